@@ -1,5 +1,7 @@
 package jerm
 
+import "time"
+
 type CloudStorage interface {
 	Delete(string) error
 	Upload(string) error
@@ -21,3 +23,95 @@ type CloudPlatform interface {
 	Logs()
 	Invoke(string) error
 }
+
+// CloudSecrets is implemented by cloud platforms that store
+// secrets of the project stage
+type CloudSecrets interface {
+	SetSecret(key, value string) error
+	GetSecret(key string) (string, error)
+	ListSecrets() ([]string, error)
+}
+
+// CloudCanary is implemented by cloud platforms that shift part of the
+// traffic to a new deployment before promoting it
+type CloudCanary interface {
+	SetCanary(weight int, window time.Duration) error
+}
+
+// FunctionVersion is a published version of a deployment
+type FunctionVersion struct {
+	Version     string
+	Created     time.Time
+	CodeHash    string
+	Size        int64
+	Description string
+	// Current is set on the version serving the stage
+	Current bool
+}
+
+// CloudVersions is implemented by cloud platforms that keep the
+// published versions of a deployment
+type CloudVersions interface {
+	Versions() ([]FunctionVersion, error)
+	RollbackTo(version string) error
+}
+
+// CloudPruner is implemented by cloud platforms that delete
+// old deployments beyond the retention of the project
+type CloudPruner interface {
+	Prune(dryRun bool) ([]string, error)
+}
+
+// UndeployOptions are the resources kept by undeploy
+type UndeployOptions struct {
+	KeepBucket bool
+	KeepRole   bool
+}
+
+// CloudTeardown is implemented by cloud platforms that list the
+// resources of a deployment before deleting them
+type CloudTeardown interface {
+	UndeployPlan(opts UndeployOptions) ([]string, error)
+	Teardown(opts UndeployOptions) error
+}
+
+// Deployment is a deployed project stage found in the cloud account
+type Deployment struct {
+	Project      string
+	Stage        string
+	URL          string
+	Runtime      string
+	LastDeployed time.Time
+	// Orphaned lists the resources left behind by a deleted function
+	Orphaned []string
+}
+
+// CloudInventory is implemented by cloud platforms that list the
+// deployments of the cloud account
+type CloudInventory interface {
+	List() ([]Deployment, error)
+}
+
+// CloudDomains is implemented by cloud platforms that serve
+// deployments at custom domains
+type CloudDomains interface {
+	Certify() error
+}
+
+// ApiKey is a key required by the requests to a deployment
+type ApiKey struct {
+	Id   string
+	Name string
+	// Value is only set on a created key
+	Value   string
+	Enabled bool
+	Created time.Time
+}
+
+// CloudApiKeys is implemented by cloud platforms that manage
+// the API keys of a deployment
+type CloudApiKeys interface {
+	CreateApiKey(name string) (ApiKey, error)
+	ListApiKeys() ([]ApiKey, error)
+	RevokeApiKey(key string) error
+}
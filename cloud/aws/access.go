@@ -2,8 +2,12 @@ package aws
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
@@ -13,9 +17,12 @@ import (
 	"github.com/spatocode/jerm/internal/log"
 )
 
+const secretsPolicySid = "JermSecrets"
+
 type IAM struct {
 	client     *iam.Client
 	config     *config.Config
+	region     string
 	roleName   string
 	policyName string
 }
@@ -25,6 +32,7 @@ func NewIAM(cfg *config.Config, awsConfig aws.Config) *IAM {
 	return &IAM{
 		config:     cfg,
 		client:     iam.NewFromConfig(awsConfig),
+		region:     awsConfig.Region,
 		roleName:   fmt.Sprintf("%s-JermLambdaServiceExecutionRole", cfg.GetFunctionName()),
 		policyName: "jerm-permissions",
 	}
@@ -46,10 +54,20 @@ func (i *IAM) checkPermissions() error {
 }
 
 // ensureIAMRolePolicy ensures the required policy is available
-// and creates/updates one if unavailable.
+// and creates/updates one if unavailable or outdated.
 func (i *IAM) ensureIAMRolePolicy() error {
+	secrets, err := i.secretResources()
+	if err != nil {
+		return err
+	}
+
+	document, err := policyDocument(secrets)
+	if err != nil {
+		return err
+	}
+
 	log.Debug("fetching IAM role policy...")
-	_, err := i.client.GetRolePolicy(context.TODO(), &iam.GetRolePolicyInput{
+	resp, err := i.client.GetRolePolicy(context.TODO(), &iam.GetRolePolicyInput{
 		RoleName:   &i.roleName,
 		PolicyName: &i.policyName,
 	})
@@ -57,19 +75,109 @@ func (i *IAM) ensureIAMRolePolicy() error {
 		var nseErr *iamTypes.NoSuchEntityException
 		if errors.As(err, &nseErr) {
 			log.Debug("IAM role policy not found. creating new IAM role policy...")
-			_, perr := i.client.PutRolePolicy(context.TODO(), &iam.PutRolePolicyInput{
-				RoleName:       &i.roleName,
-				PolicyName:     &i.policyName,
-				PolicyDocument: aws.String(awsAttachPolicy),
-			})
-			if perr != nil {
-				return perr
-			}
-			return nil
+			return i.putIAMRolePolicy(document)
 		}
 		return err
 	}
-	return nil
+
+	if equalPolicyDocuments(aws.ToString(resp.PolicyDocument), document) {
+		return nil
+	}
+
+	log.Debug("IAM role policy changed. updating IAM role policy...")
+	return i.putIAMRolePolicy(document)
+}
+
+// putIAMRolePolicy creates or replaces the IAM role policy
+func (i *IAM) putIAMRolePolicy(document string) error {
+	_, err := i.client.PutRolePolicy(context.TODO(), &iam.PutRolePolicyInput{
+		RoleName:       &i.roleName,
+		PolicyName:     &i.policyName,
+		PolicyDocument: aws.String(document),
+	})
+	return err
+}
+
+// secretResources returns the ARNs of the secrets the function reads at runtime
+func (i *IAM) secretResources() ([]string, error) {
+	if !i.config.ResolvesSecretsAtRuntime() {
+		return nil, nil
+	}
+
+	references, err := i.config.GetSecretReferences()
+	if err != nil {
+		return nil, err
+	}
+
+	// role ARNs are in the form arn:aws:iam::<account>:role/<name>
+	parts := strings.Split(i.config.Platform.Role, ":")
+	if len(parts) < 6 {
+		return nil, fmt.Errorf("invalid IAM role %s", i.config.Platform.Role)
+	}
+	partition, account := parts[1], parts[4]
+
+	var resources []string
+	for _, reference := range references {
+		var resource string
+		switch reference.Store {
+		case config.SSMSecret:
+			name := strings.TrimPrefix(reference.Name, "/")
+			resource = fmt.Sprintf("arn:%s:ssm:%s:%s:parameter/%s", partition, i.region, account, name)
+		case config.SecretsManager:
+			// secret ARNs end with a random suffix of 6 characters
+			resource = fmt.Sprintf("arn:%s:secretsmanager:%s:%s:secret:%s-??????", partition, i.region, account, reference.Name)
+		}
+		resources = append(resources, resource)
+	}
+	return resources, nil
+}
+
+// policyDocument returns the IAM role policy document granting access to secrets
+func policyDocument(secrets []string) (string, error) {
+	if len(secrets) == 0 {
+		return awsAttachPolicy, nil
+	}
+
+	var document map[string]interface{}
+	err := json.Unmarshal([]byte(awsAttachPolicy), &document)
+	if err != nil {
+		return "", err
+	}
+
+	statements, _ := document["Statement"].([]interface{})
+	document["Statement"] = append(statements, map[string]interface{}{
+		"Sid":    secretsPolicySid,
+		"Effect": "Allow",
+		"Action": []string{
+			"ssm:GetParameter",
+			"ssm:GetParameters",
+			"secretsmanager:GetSecretValue",
+		},
+		"Resource": secrets,
+	})
+
+	b, err := json.Marshal(document)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// equalPolicyDocuments reports whether the current policy document grants the
+// same as the wanted one. Policy documents returned by IAM are URL-encoded.
+func equalPolicyDocuments(current, wanted string) bool {
+	if decoded, err := url.QueryUnescape(current); err == nil {
+		current = decoded
+	}
+
+	var currentPolicy, wantedPolicy interface{}
+	if err := json.Unmarshal([]byte(current), &currentPolicy); err != nil {
+		return false
+	}
+	if err := json.Unmarshal([]byte(wanted), &wantedPolicy); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(currentPolicy, wantedPolicy)
 }
 
 // getIAMRole gets AWS IAM role
@@ -91,15 +199,26 @@ func (i *IAM) getIAMRole() (*iamTypes.Role, error) {
 		return nil, err
 	}
 
+	if resp.Role != nil {
+		err = i.tagIAMRole(resp.Role.Tags)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return resp.Role, nil
 }
 
 // createIAMRole creates AWS IAM role
 func (i *IAM) createIAMRole() (*iam.CreateRoleOutput, error) {
+	tags, err := i.roleTags()
+	if err != nil {
+		return nil, err
+	}
 	resp, err := i.client.CreateRole(context.TODO(), &iam.CreateRoleInput{
 		AssumeRolePolicyDocument: aws.String(awsAssumePolicy),
 		Path:                     aws.String("/"),
 		RoleName:                 &i.roleName,
+		Tags:                     tags,
 	})
 	if err != nil {
 		return nil, err
@@ -107,3 +226,65 @@ func (i *IAM) createIAMRole() (*iam.CreateRoleOutput, error) {
 
 	return resp, nil
 }
+
+// deleteIAMRole deletes the jerm policy of the role and the role
+func (i *IAM) deleteIAMRole() error {
+	log.Debug("deleting IAM role policy...")
+	_, err := i.client.DeleteRolePolicy(context.TODO(), &iam.DeleteRolePolicyInput{
+		RoleName:   &i.roleName,
+		PolicyName: &i.policyName,
+	})
+	var nseErr *iamTypes.NoSuchEntityException
+	if err != nil && !errors.As(err, &nseErr) {
+		return err
+	}
+
+	log.Debug("deleting IAM role...")
+	_, err = i.client.DeleteRole(context.TODO(), &iam.DeleteRoleInput{
+		RoleName: &i.roleName,
+	})
+	if err != nil && !errors.As(err, &nseErr) {
+		return err
+	}
+	return nil
+}
+
+func (i *IAM) roleTags() ([]iamTypes.Tag, error) {
+	tags, err := resourceTags(i.config)
+	if err != nil {
+		return nil, err
+	}
+	var roleTags []iamTypes.Tag
+	for _, key := range sortedTagKeys(tags) {
+		roleTags = append(roleTags, iamTypes.Tag{Key: aws.String(key), Value: aws.String(tags[key])})
+	}
+	return roleTags, nil
+}
+
+// tagIAMRole tags the role with the tags of the project unless it already has them
+func (i *IAM) tagIAMRole(current []iamTypes.Tag) error {
+	tags, err := i.roleTags()
+	if err != nil {
+		return err
+	}
+	existing := map[string]string{}
+	for _, tag := range current {
+		existing[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	tagged := true
+	for _, tag := range tags {
+		if value, ok := existing[aws.ToString(tag.Key)]; !ok || value != aws.ToString(tag.Value) {
+			tagged = false
+		}
+	}
+	if tagged {
+		return nil
+	}
+
+	log.Debug("tagging IAM role...")
+	_, err = i.client.TagRole(context.TODO(), &iam.TagRoleInput{
+		RoleName: &i.roleName,
+		Tags:     tags,
+	})
+	return err
+}
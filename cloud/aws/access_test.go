@@ -3,6 +3,7 @@ package aws
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -198,7 +199,7 @@ func TestEnsureIAMRolePolicy(t *testing.T) {
 							"GetRolePolicyMock",
 							func(ctx context.Context, fi middleware.FinalizeInput, fh middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
 								return middleware.FinalizeOutput{
-									Result: &iam.GetRolePolicyOutput{},
+									Result: &iam.GetRolePolicyOutput{PolicyDocument: aws.String(url.QueryEscape(awsAttachPolicy))},
 								}, middleware.Metadata{}, nil
 							},
 						),
@@ -235,3 +236,63 @@ func TestEnsureIAMRolePolicy(t *testing.T) {
 		})
 	}
 }
+
+func TestEnsureIAMRolePolicyUpdatesOutdatedPolicy(t *testing.T) {
+	assert := assert.New(t)
+	withAPIOptionsFunc, recorder := mockApi(func(operation string, input interface{}) (interface{}, error) {
+		switch operation {
+		case "GetRolePolicy":
+			return &iam.GetRolePolicyOutput{PolicyDocument: aws.String(url.QueryEscape(`{"Version":"2012-10-17","Statement":[]}`))}, nil
+		case "PutRolePolicy":
+			return &iam.PutRolePolicyOutput{}, nil
+		default:
+			return nil, errUnexpectedOperation
+		}
+	})
+	l := helperLambda(t, withAPIOptionsFunc)
+
+	err := l.access.ensureIAMRolePolicy()
+	assert.Nil(err)
+	assert.Equal([]string{"GetRolePolicy", "PutRolePolicy"}, recorder.operations)
+	assert.Equal(awsAttachPolicy, *recorder.inputs[1].(*iam.PutRolePolicyInput).PolicyDocument)
+}
+
+func TestIAMSecretResources(t *testing.T) {
+	assert := assert.New(t)
+	cfg := &config.Config{
+		Environment: map[string]string{
+			"DB_PASSWORD": "ssm:/myapp/db_password",
+			"DB":          "secretsmanager:prod/db",
+		},
+	}
+	cfg.Platform.Role = "arn:aws:iam::123456789012:role/test"
+	i := NewIAM(cfg, aws.Config{Region: "us-west-1"})
+
+	resources, err := i.secretResources()
+	assert.Nil(err)
+	assert.Nil(resources)
+
+	cfg.SecretsMode = config.SecretsRuntime
+	resources, err = i.secretResources()
+	assert.Nil(err)
+	assert.Equal([]string{
+		"arn:aws:secretsmanager:us-west-1:123456789012:secret:prod/db-??????",
+		"arn:aws:ssm:us-west-1:123456789012:parameter/myapp/db_password",
+	}, resources)
+}
+
+func TestIAMPolicyDocument(t *testing.T) {
+	assert := assert.New(t)
+
+	document, err := policyDocument(nil)
+	assert.Nil(err)
+	assert.Equal(awsAttachPolicy, document)
+
+	secrets := []string{"arn:aws:ssm:us-west-1:123456789012:parameter/myapp/db_password"}
+	withSecrets, err := policyDocument(secrets)
+	assert.Nil(err)
+	assert.Contains(withSecrets, secrets[0])
+	assert.True(equalPolicyDocuments(url.QueryEscape(withSecrets), withSecrets))
+	assert.False(equalPolicyDocuments(url.QueryEscape(document), withSecrets))
+	assert.False(equalPolicyDocuments("", document))
+}
@@ -0,0 +1,318 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+
+	"github.com/spatocode/jerm"
+	"github.com/spatocode/jerm/internal/log"
+	"github.com/spatocode/jerm/internal/utils"
+)
+
+const (
+	// DefaultCanaryWindow is how long a canary version is watched before it is promoted
+	DefaultCanaryWindow = 10 * time.Minute
+
+	// DefaultCanaryInterval is how often the errors of a canary version are checked
+	DefaultCanaryInterval = time.Minute
+
+	// lambdaTimeLayout is the layout of the timestamps of Lambda resources
+	lambdaTimeLayout = "2006-01-02T15:04:05.000-0700"
+)
+
+// SetCanary shifts weight percent of the traffic of the next deployment to the
+// new version for the window before promoting it. A weight of 0 shifts all the
+// traffic at once.
+func (l *Lambda) SetCanary(weight int, window time.Duration) error {
+	if weight < 0 || weight >= 100 {
+		msg := fmt.Sprintf("invalid canary weight %d%%. Canary weight must be between 0%% and 100%%", weight)
+		return errors.New(msg)
+	}
+	if window <= 0 {
+		window = DefaultCanaryWindow
+	}
+	l.canaryWeight = weight
+	l.canaryWindow = window
+	return nil
+}
+
+// release publishes a version of the function, wires the stage alias to the
+// event sources and API of the project, routes the traffic to the version and
+// prunes old deployments
+func (l *Lambda) release() error {
+	version, err := l.publishVersion()
+	if err != nil {
+		return err
+	}
+
+	aliasArn, err := l.ensureAlias(version)
+	if err != nil {
+		return err
+	}
+
+	err = l.tagFunction(aliasArn)
+	if err != nil {
+		return err
+	}
+
+	err = l.scheduleEvents(&aliasArn)
+	if err != nil {
+		return err
+	}
+
+	err = l.eventSources.sync(aliasArn)
+	if err != nil {
+		return err
+	}
+
+	err = l.triggers.sync(aliasArn)
+	if err != nil {
+		return err
+	}
+
+	err = l.serveHttp(aliasArn)
+	if err != nil {
+		return err
+	}
+
+	err = l.serveWebSocket(aliasArn)
+	if err != nil {
+		return err
+	}
+
+	err = l.routeTraffic(version)
+	if err != nil {
+		return err
+	}
+
+	// a failed prune leaves old deployments behind but doesn't fail the release
+	pruned, err := l.Prune(false)
+	if err != nil {
+		log.PrintWarn(err)
+	}
+	for _, resource := range pruned {
+		log.Debug(fmt.Sprintf("pruned %s", resource))
+	}
+	return nil
+}
+
+// publishVersion publishes the code and configuration of the function as a new version
+func (l *Lambda) publishVersion() (string, error) {
+	log.Debug("publishing lambda version...")
+	resp, err := l.client.PublishVersion(context.TODO(), &lambda.PublishVersionInput{
+		FunctionName: aws.String(l.config.GetFunctionName()),
+		Description:  aws.String(l.versionDescription()),
+	})
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(resp.Version), nil
+}
+
+// versionDescription records the jerm version and the git commit of the project in a version
+func (l *Lambda) versionDescription() string {
+	description := fmt.Sprintf("jerm %s", jerm.Version)
+	commit, err := utils.Command().RunCommand("git", "-C", l.config.Dir, "rev-parse", "--short", "HEAD")
+	if err != nil || strings.TrimSpace(commit) == "" {
+		return description
+	}
+	description = fmt.Sprintf("%s git %s", description, strings.TrimSpace(commit))
+	status, err := utils.Command().RunCommand("git", "-C", l.config.Dir, "status", "--porcelain")
+	if err == nil && strings.TrimSpace(status) != "" {
+		description += "-dirty"
+	}
+	return description
+}
+
+// ensureAlias creates the stage alias at version if it doesn't exist and returns its ARN
+func (l *Lambda) ensureAlias(version string) (string, error) {
+	alias, err := l.getAlias()
+	if err == nil {
+		return aws.ToString(alias.AliasArn), nil
+	}
+	var rnfErr *lambdaTypes.ResourceNotFoundException
+	if !errors.As(err, &rnfErr) {
+		return "", err
+	}
+
+	log.Debug(fmt.Sprintf("creating lambda alias %s...", l.config.Stage))
+	resp, err := l.client.CreateAlias(context.TODO(), &lambda.CreateAliasInput{
+		FunctionName:    aws.String(l.config.GetFunctionName()),
+		Name:            aws.String(l.config.Stage),
+		FunctionVersion: aws.String(version),
+	})
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(resp.AliasArn), nil
+}
+
+func (l *Lambda) getAlias() (*lambda.GetAliasOutput, error) {
+	log.Debug(fmt.Sprintf("getting lambda alias %s...", l.config.Stage))
+	return l.client.GetAlias(context.TODO(), &lambda.GetAliasInput{
+		FunctionName: aws.String(l.config.GetFunctionName()),
+		Name:         aws.String(l.config.Stage),
+	})
+}
+
+// updateAlias points the stage alias at version. weights route part of
+// the traffic to other versions; nil routes all the traffic to version.
+func (l *Lambda) updateAlias(version string, weights map[string]float64) error {
+	log.Debug(fmt.Sprintf("pointing lambda alias %s to version %s...", l.config.Stage, version))
+	if weights == nil {
+		weights = map[string]float64{}
+	}
+	_, err := l.client.UpdateAlias(context.TODO(), &lambda.UpdateAliasInput{
+		FunctionName:    aws.String(l.config.GetFunctionName()),
+		Name:            aws.String(l.config.Stage),
+		FunctionVersion: aws.String(version),
+		RoutingConfig:   &lambdaTypes.AliasRoutingConfiguration{AdditionalVersionWeights: weights},
+	})
+	return err
+}
+
+// routeTraffic moves the traffic of the stage alias to version. With a canary,
+// part of the traffic is shifted to version and the alias is rolled back if
+// the version reports errors within the canary window.
+func (l *Lambda) routeTraffic(version string) error {
+	alias, err := l.getAlias()
+	if err != nil {
+		return err
+	}
+	current := aws.ToString(alias.FunctionVersion)
+	if current == version {
+		return nil
+	}
+	if l.canaryWeight == 0 {
+		return l.updateAlias(version, nil)
+	}
+
+	log.PrintfInfo("Shifting %d%% of traffic to version %s for %s...\n", l.canaryWeight, version, l.canaryWindow)
+	err = l.updateAlias(current, map[string]float64{version: float64(l.canaryWeight) / 100})
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	for time.Since(start) < l.canaryWindow {
+		time.Sleep(l.canaryInterval)
+		errorCount, err := l.metrics.functionErrors(version, start)
+		if err != nil {
+			return errors.Join(err, l.updateAlias(current, nil))
+		}
+		if errorCount > 0 {
+			err = l.updateAlias(current, nil)
+			if err != nil {
+				return err
+			}
+			msg := fmt.Sprintf("version %s reported %v errors during the canary. Rolled back to version %s", version, errorCount, current)
+			return errors.New(msg)
+		}
+	}
+
+	log.PrintfInfo("Promoting version %s...\n", version)
+	return l.updateAlias(version, nil)
+}
+
+// previousVersions lists the published versions older than version, newest first
+func (l *Lambda) previousVersions(version string) ([]string, error) {
+	current, err := strconv.Atoi(version)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := l.listLambdaVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	var revisions []int
+	for _, v := range versions {
+		revision, err := strconv.Atoi(aws.ToString(v.Version))
+		if err != nil || revision >= current {
+			// skips $LATEST and versions newer than the alias
+			continue
+		}
+		revisions = append(revisions, revision)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(revisions)))
+
+	var previous []string
+	for _, revision := range revisions {
+		previous = append(previous, strconv.Itoa(revision))
+	}
+	return previous, nil
+}
+
+// Versions lists the published versions of the function, newest first
+func (l *Lambda) Versions() ([]jerm.FunctionVersion, error) {
+	versions, err := l.listLambdaVersions()
+	if err != nil {
+		var rnfErr *lambdaTypes.ResourceNotFoundException
+		if errors.As(err, &rnfErr) {
+			msg := "can't find a deployed project. Run 'jerm deploy' to deploy instead"
+			return nil, errors.New(msg)
+		}
+		return nil, err
+	}
+
+	current := ""
+	alias, err := l.getAlias()
+	if err == nil {
+		current = aws.ToString(alias.FunctionVersion)
+	} else {
+		var rnfErr *lambdaTypes.ResourceNotFoundException
+		if !errors.As(err, &rnfErr) {
+			return nil, err
+		}
+	}
+
+	var published []jerm.FunctionVersion
+	revisions := map[string]int{}
+	for _, v := range versions {
+		version := aws.ToString(v.Version)
+		revision, err := strconv.Atoi(version)
+		if err != nil {
+			continue
+		}
+		revisions[version] = revision
+		created, _ := time.Parse(lambdaTimeLayout, aws.ToString(v.LastModified))
+		published = append(published, jerm.FunctionVersion{
+			Version:     version,
+			Created:     created.UTC(),
+			CodeHash:    aws.ToString(v.CodeSha256),
+			Size:        v.CodeSize,
+			Description: aws.ToString(v.Description),
+			Current:     version == current,
+		})
+	}
+	sort.Slice(published, func(i, j int) bool {
+		return revisions[published[i].Version] > revisions[published[j].Version]
+	})
+	return published, nil
+}
+
+// RollbackTo points the stage alias at a published version. The version
+// runs with the code and configuration it was published with.
+func (l *Lambda) RollbackTo(version string) error {
+	versions, err := l.Versions()
+	if err != nil {
+		return err
+	}
+	for _, v := range versions {
+		if v.Version == version {
+			return l.updateAlias(version, nil)
+		}
+	}
+	msg := fmt.Sprintf("can't find version %s of project %s", version, l.config.GetFunctionName())
+	return errors.New(msg)
+}
@@ -0,0 +1,234 @@
+package aws
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/spatocode/jerm"
+	"github.com/stretchr/testify/assert"
+)
+
+var testFunctionVersions = &lambda.ListVersionsByFunctionOutput{Versions: []lambdaTypes.FunctionConfiguration{
+	{Version: aws.String("$LATEST")},
+	{Version: aws.String("1")},
+	{Version: aws.String("2")},
+	{Version: aws.String("3"), CodeSha256: aws.String("abc="), CodeSize: 2048, LastModified: aws.String("2023-07-01T12:30:00.000+0000"), Description: aws.String("jerm 0.1.3 git 1a2b3c4")},
+	{Version: aws.String("4")},
+}}
+
+func TestLambdaSetCanary(t *testing.T) {
+	assert := assert.New(t)
+	l := &Lambda{}
+	assert.Nil(l.SetCanary(10, 0))
+	assert.Equal(10, l.canaryWeight)
+	assert.Equal(DefaultCanaryWindow, l.canaryWindow)
+	assert.Nil(l.SetCanary(25, time.Minute))
+	assert.Equal(time.Minute, l.canaryWindow)
+	assert.EqualError(l.SetCanary(100, 0), "invalid canary weight 100%. Canary weight must be between 0% and 100%")
+	assert.Error(l.SetCanary(-1, 0))
+}
+
+func TestLambdaRouteTraffic(t *testing.T) {
+	assert := assert.New(t)
+
+	type update struct {
+		version string
+		weights map[string]float64
+	}
+	cases := []struct {
+		name         string
+		canaryWeight int
+		errorCount   float64
+		version      string
+		want         error
+		wantErr      bool
+		updates      []update
+	}{
+		{
+			name:    "alias already points at version",
+			version: "1",
+		},
+		{
+			name:    "all traffic to version",
+			version: "2",
+			updates: []update{{"2", nil}},
+		},
+		{
+			name:         "canary promotes version",
+			canaryWeight: 10,
+			version:      "2",
+			updates:      []update{{"1", map[string]float64{"2": 0.1}}, {"2", nil}},
+		},
+		{
+			name:         "canary rolls back version",
+			canaryWeight: 10,
+			errorCount:   3,
+			version:      "2",
+			want:         fmt.Errorf("version 2 reported 3 errors during the canary. Rolled back to version 1"),
+			wantErr:      true,
+			updates:      []update{{"1", map[string]float64{"2": 0.1}}, {"1", nil}},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			withAPIOptionsFunc, recorder := mockApi(func(operation string, input interface{}) (interface{}, error) {
+				switch operation {
+				case "GetAlias":
+					return &lambda.GetAliasOutput{AliasArn: aws.String(testAliasArn), FunctionVersion: aws.String("1")}, nil
+				case "UpdateAlias":
+					return &lambda.UpdateAliasOutput{}, nil
+				case "GetMetricStatistics":
+					output := &cloudwatch.GetMetricStatisticsOutput{}
+					if tt.errorCount > 0 {
+						output.Datapoints = []cwTypes.Datapoint{{Sum: aws.Float64(tt.errorCount)}}
+					}
+					return output, nil
+				}
+				return nil, errUnexpectedOperation
+			})
+			l := helperLambda(t, withAPIOptionsFunc)
+			l.canaryWeight = tt.canaryWeight
+
+			err := l.routeTraffic(tt.version)
+			if tt.wantErr {
+				assert.EqualError(err, tt.want.Error())
+			} else {
+				assert.Nil(err)
+			}
+
+			var updates []update
+			for _, input := range recorder.inputs {
+				if u, ok := input.(*lambda.UpdateAliasInput); ok {
+					assert.Equal("dev", *u.Name)
+					var weights map[string]float64
+					if len(u.RoutingConfig.AdditionalVersionWeights) > 0 {
+						weights = u.RoutingConfig.AdditionalVersionWeights
+					}
+					updates = append(updates, update{*u.FunctionVersion, weights})
+				}
+			}
+			assert.Equal(tt.updates, updates)
+		})
+	}
+}
+
+func TestLambdaRollback(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		name    string
+		steps   int
+		version string
+		want    error
+		wantErr bool
+	}{
+		{name: "rollback one step", steps: 1, version: "2"},
+		{name: "rollback two steps", steps: 2, version: "1"},
+		{name: "rollback past the first version", steps: 3, want: fmt.Errorf("invalid revision for rollback. Aborting"), wantErr: true},
+		{name: "rollback zero steps", steps: 0, want: fmt.Errorf("invalid revision for rollback. Aborting"), wantErr: true},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			withAPIOptionsFunc, recorder := mockApi(func(operation string, input interface{}) (interface{}, error) {
+				switch operation {
+				case "GetAlias":
+					return &lambda.GetAliasOutput{AliasArn: aws.String(testAliasArn), FunctionVersion: aws.String("3")}, nil
+				case "ListVersionsByFunction":
+					return testFunctionVersions, nil
+				case "UpdateAlias":
+					return &lambda.UpdateAliasOutput{}, nil
+				}
+				return nil, errUnexpectedOperation
+			})
+			l := helperLambda(t, withAPIOptionsFunc)
+
+			err := l.Rollback(tt.steps)
+			if tt.wantErr {
+				assert.EqualError(err, tt.want.Error())
+				assert.NotContains(recorder.operations, "UpdateAlias")
+				return
+			}
+			assert.Nil(err)
+			update := recorder.inputs[len(recorder.inputs)-1].(*lambda.UpdateAliasInput)
+			assert.Equal(tt.version, *update.FunctionVersion)
+		})
+	}
+}
+
+func TestLambdaRollbackTo(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		name    string
+		version string
+		want    error
+		wantErr bool
+	}{
+		{name: "rollback to version", version: "1"},
+		{name: "rollback to unknown version", version: "9", want: fmt.Errorf("can't find version 9 of project test-dev"), wantErr: true},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			withAPIOptionsFunc, recorder := mockApi(func(operation string, input interface{}) (interface{}, error) {
+				switch operation {
+				case "GetAlias":
+					return &lambda.GetAliasOutput{AliasArn: aws.String(testAliasArn), FunctionVersion: aws.String("3")}, nil
+				case "ListVersionsByFunction":
+					return testFunctionVersions, nil
+				case "UpdateAlias":
+					return &lambda.UpdateAliasOutput{}, nil
+				}
+				return nil, errUnexpectedOperation
+			})
+			l := helperLambda(t, withAPIOptionsFunc)
+
+			err := l.RollbackTo(tt.version)
+			if tt.wantErr {
+				assert.EqualError(err, tt.want.Error())
+				assert.NotContains(recorder.operations, "UpdateAlias")
+				return
+			}
+			assert.Nil(err)
+			update := recorder.inputs[len(recorder.inputs)-1].(*lambda.UpdateAliasInput)
+			assert.Equal(tt.version, *update.FunctionVersion)
+		})
+	}
+}
+
+func TestLambdaVersions(t *testing.T) {
+	assert := assert.New(t)
+	withAPIOptionsFunc, _ := mockApi(func(operation string, input interface{}) (interface{}, error) {
+		switch operation {
+		case "GetAlias":
+			return &lambda.GetAliasOutput{AliasArn: aws.String(testAliasArn), FunctionVersion: aws.String("3")}, nil
+		case "ListVersionsByFunction":
+			return testFunctionVersions, nil
+		}
+		return nil, errUnexpectedOperation
+	})
+	l := helperLambda(t, withAPIOptionsFunc)
+
+	versions, err := l.Versions()
+	assert.Nil(err)
+	assert.Len(versions, 4)
+	assert.Equal("4", versions[0].Version)
+	assert.False(versions[0].Current)
+	assert.Equal(jerm.FunctionVersion{
+		Version:     "3",
+		Created:     time.Date(2023, 7, 1, 12, 30, 0, 0, time.UTC),
+		CodeHash:    "abc=",
+		Size:        2048,
+		Description: "jerm 0.1.3 git 1a2b3c4",
+		Current:     true,
+	}, versions[1])
+	assert.Equal("1", versions[3].Version)
+}
@@ -4,7 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -46,25 +51,151 @@ func (a *ApiGateway) WithMonitor(monitor jerm.CloudMonitor) {
 	a.monitor = monitor
 }
 
-func (a *ApiGateway) setup(functionArn *string) error {
+// apiRoute serves methods of a path of the REST API with a function
+type apiRoute struct {
+	path        string
+	methods     []string
+	functionArn string
+}
+
+// apiResource is a resource of the REST API with the functions serving its methods
+type apiResource struct {
+	path     string
+	methods  map[string]string
+	children map[string]*apiResource
+}
+
+// resourceTree returns the resources of the REST API serving the routes. Every
+// resource falls back to the function for the methods its routes leave out, and
+// has a greedy child for the paths beneath it unless it has a path variable.
+func resourceTree(functionArn string, routes []apiRoute) *apiResource {
+	root := &apiResource{path: "/", methods: map[string]string{}, children: map[string]*apiResource{}}
+	for _, route := range routes {
+		resource := root
+		for _, segment := range config.RouteSegments(route.path) {
+			child, ok := resource.children[segment]
+			if !ok {
+				child = &apiResource{
+					path:     strings.TrimSuffix(resource.path, "/") + "/" + segment,
+					methods:  map[string]string{},
+					children: map[string]*apiResource{},
+				}
+				resource.children[segment] = child
+			}
+			resource = child
+		}
+		for _, method := range route.methods {
+			resource.methods[method] = route.functionArn
+		}
+	}
+
+	var fallback func(resource *apiResource)
+	fallback = func(resource *apiResource) {
+		if _, ok := resource.methods["ANY"]; !ok {
+			resource.methods["ANY"] = functionArn
+		}
+		if strings.HasSuffix(resource.path, "+}") {
+			return
+		}
+		variable := false
+		for segment, child := range resource.children {
+			variable = variable || strings.HasPrefix(segment, "{")
+			fallback(child)
+		}
+		if !variable {
+			resource.children["{proxy+}"] = &apiResource{
+				path:     strings.TrimSuffix(resource.path, "/") + "/{proxy+}",
+				methods:  map[string]string{"ANY": functionArn},
+				children: map[string]*apiResource{},
+			}
+		}
+	}
+	fallback(root)
+	return root
+}
+
+// resourceKey returns the suffix of the logical IDs of the template resources of a path.
+// The root and its greedy path keep the IDs of the stacks deployed before routes.
+func resourceKey(path string) string {
+	switch path {
+	case "/":
+		return "0"
+	case "/{proxy+}":
+		return "1"
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(path))
+	return fmt.Sprintf("%08x", hash.Sum32())
+}
+
+// resourceLogicalId returns the logical ID of the template resource of a path
+func resourceLogicalId(path string) string {
+	if path == "/{proxy+}" {
+		return "ResourceAnyPathSlashed"
+	}
+	return fmt.Sprintf("Resource%s", resourceKey(path))
+}
+
+// createResources adds the resources beneath a resource of the tree with their methods to the template
+func (a *ApiGateway) createResources(resource *apiResource, resourceId string, auth config.Auth, cors *config.Cors) {
+	key := resourceKey(resource.path)
+	var methods []string
+	for method := range resource.methods {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	for _, method := range methods {
+		a.createMethod(resource.methods[method], resourceId, key, method, auth)
+	}
+	// the preflight requests of a resource are answered by its OPTIONS route if it has one
+	if _, ok := resource.methods["OPTIONS"]; !ok {
+		a.createCorsMethod(resourceId, key, cors)
+	}
+
+	for _, child := range resource.children {
+		logicalId := resourceLogicalId(child.path)
+		a.cfTemplate.Resources[logicalId] = &cfApigateway.Resource{
+			RestApiId: cf.Ref("Api"),
+			ParentId:  resourceId,
+			PathPart:  path.Base(child.path),
+		}
+		a.createResources(child, cf.Ref(logicalId), auth, cors)
+	}
+}
+
+func (a *ApiGateway) setup(functionArn *string, routes []apiRoute) error {
+	auth, err := a.config.GetAuth()
+	if err != nil {
+		return err
+	}
+	settings, err := a.config.GetApiGateway()
+	if err != nil {
+		return err
+	}
+
 	template := cf.NewTemplate()
 	template.Description = "Auto generated by Jerm"
 	restApi := &cfApigateway.RestApi{
-		Name:        aws.String(a.config.GetFunctionName()),
-		Description: aws.String("Automatically created by Jerm"),
+		Name:                   aws.String(a.config.GetFunctionName()),
+		Description:            aws.String("Automatically created by Jerm"),
+		BinaryMediaTypes:       settings.BinaryMediaTypes,
+		MinimumCompressionSize: settings.MinimumCompressionSize,
 	}
 	template.Resources["Api"] = restApi
 
-	rootId := cf.GetAtt("Api", "RootResourceId")
 	a.cfTemplate = template
-	a.createMethods(functionArn, rootId, 0)
-
-	resource := &cfApigateway.Resource{}
-	resource.RestApiId = cf.Ref("Api")
-	resource.ParentId = rootId
-	resource.PathPart = "{proxy+}"
-	a.cfTemplate.Resources["ResourceAnyPathSlashed"] = resource
-	a.createMethods(functionArn, cf.Ref("ResourceAnyPathSlashed"), 1)
+	a.createAuthorizer(functionArn, auth)
+	a.createUsagePlan(auth)
+	tree := resourceTree(*functionArn, routes)
+	a.createResources(tree, cf.GetAtt("Api", "RootResourceId"), auth, settings.Cors)
+
+	// the stage is removed from the usage plan before the stack deletes the plan
+	if auth.ApiKeys == nil {
+		err = a.detachUsagePlan()
+		if err != nil {
+			return err
+		}
+	}
 
 	a.createCFStack()
 
@@ -78,6 +209,13 @@ func (a *ApiGateway) setup(functionArn *string) error {
 		return err
 	}
 
+	if auth.ApiKeys != nil {
+		err = a.attachUsagePlan(apiId)
+		if err != nil {
+			return err
+		}
+	}
+
 	fmt.Printf("%s %s", log.Magenta("url:"), log.Green(apiUrl))
 
 	return nil
@@ -85,57 +223,102 @@ func (a *ApiGateway) setup(functionArn *string) error {
 
 // deployAPIGateway deploys an AWS API gateway
 func (a *ApiGateway) deploy(apiId *string) (string, error) {
+	settings, err := a.config.GetApiGateway()
+	if err != nil {
+		return "", err
+	}
+
 	log.Debug("deploying API Gateway...")
-	_, err := a.client.CreateDeployment(context.TODO(), &apigateway.CreateDeploymentInput{
-		StageName:        aws.String(a.config.Stage),
-		RestApiId:        apiId,
-		Description:      aws.String("Automatically created by Jerm"),
-		CacheClusterSize: agTypes.CacheClusterSizeSize0Point5Gb,
+	_, err = a.client.CreateDeployment(context.TODO(), &apigateway.CreateDeploymentInput{
+		StageName:   aws.String(a.config.Stage),
+		RestApiId:   apiId,
+		Description: aws.String("Automatically created by Jerm"),
 	})
 	if err != nil {
 		msg := fmt.Sprintf("[Deployment Error] %s", err.Error())
 		return "", errors.New(msg)
 	}
 
-	_, err = a.client.UpdateStage(context.TODO(), &apigateway.UpdateStageInput{
+	stage, err := a.client.GetStage(context.TODO(), &apigateway.GetStageInput{
 		RestApiId: apiId,
 		StageName: aws.String(a.config.Stage),
-		PatchOperations: []agTypes.PatchOperation{
-			{
-				Op:    agTypes.OpReplace,
-				Path:  aws.String("/*/*/logging/loglevel"),
-				Value: aws.String("OFF"),
-			},
-			{
-				Op:    agTypes.OpReplace,
-				Path:  aws.String("/*/*/logging/dataTrace"),
-				Value: aws.String("false"),
-			},
-			{
-				Op:    agTypes.OpReplace,
-				Path:  aws.String("/*/*/metrics/enabled"),
-				Value: aws.String("false"),
-			},
-			{
-				Op:    agTypes.OpReplace,
-				Path:  aws.String("/*/*/caching/ttlInSeconds"),
-				Value: aws.String("300"),
-			},
-			{
-				Op:    agTypes.OpReplace,
-				Path:  aws.String("/*/*/caching/dataEncrypted"),
-				Value: aws.String("false"),
-			},
-		},
 	})
 	if err != nil {
-		msg := fmt.Sprintf("[Stage Update Error] %s", err)
-		return "", errors.New(msg)
+		return "", err
+	}
+
+	operations := stagePatchOperations(stage, settings)
+	if len(operations) > 0 {
+		log.Debug("updating API Gateway stage...")
+		_, err = a.client.UpdateStage(context.TODO(), &apigateway.UpdateStageInput{
+			RestApiId:       apiId,
+			StageName:       aws.String(a.config.Stage),
+			PatchOperations: operations,
+		})
+		if err != nil {
+			msg := fmt.Sprintf("[Stage Update Error] %s", err)
+			return "", errors.New(msg)
+		}
 	}
 
 	return fmt.Sprintf("https://%s.execute-api.%s.amazonaws.com/%s\n", *apiId, a.awsConfig.Region, a.config.Stage), nil
 }
 
+// stagePatchOperations returns the operations that apply the settings to a stage.
+// Settings the stage already has are left out.
+func stagePatchOperations(stage *apigateway.GetStageOutput, settings config.ApiGateway) []agTypes.PatchOperation {
+	var operations []agTypes.PatchOperation
+	replace := func(path, current, value string) {
+		if current != value {
+			operations = append(operations, agTypes.PatchOperation{
+				Op:    agTypes.OpReplace,
+				Path:  aws.String(path),
+				Value: aws.String(value),
+			})
+		}
+	}
+
+	replace("/cacheClusterEnabled", strconv.FormatBool(stage.CacheClusterEnabled), strconv.FormatBool(settings.CacheCluster))
+	if settings.CacheCluster {
+		replace("/cacheClusterSize", string(stage.CacheClusterSize), settings.CacheClusterSize)
+	}
+
+	// the settings of every method of a stage without method settings are the defaults
+	method, ok := stage.MethodSettings["*/*"]
+	if !ok {
+		method = agTypes.MethodSetting{
+			LoggingLevel:      aws.String(config.LogLevelOff),
+			CacheTtlInSeconds: config.DefaultCacheTtl,
+		}
+	}
+	replace("/*/*/caching/enabled", strconv.FormatBool(method.CachingEnabled), strconv.FormatBool(settings.CacheCluster))
+	replace("/*/*/caching/ttlInSeconds", strconv.Itoa(int(method.CacheTtlInSeconds)), strconv.Itoa(*settings.CacheTtl))
+	replace("/*/*/logging/loglevel", aws.ToString(method.LoggingLevel), settings.LogLevel)
+	replace("/*/*/logging/dataTrace", strconv.FormatBool(method.DataTraceEnabled), strconv.FormatBool(settings.DataTrace))
+	replace("/*/*/metrics/enabled", strconv.FormatBool(method.MetricsEnabled), strconv.FormatBool(settings.DetailedMetrics))
+	if settings.ThrottlingBurstLimit > 0 {
+		replace("/*/*/throttling/burstLimit", strconv.Itoa(int(method.ThrottlingBurstLimit)), strconv.Itoa(settings.ThrottlingBurstLimit))
+	}
+	if settings.ThrottlingRateLimit > 0 {
+		replace("/*/*/throttling/rateLimit", strconv.FormatFloat(method.ThrottlingRateLimit, 'f', -1, 64), strconv.FormatFloat(settings.ThrottlingRateLimit, 'f', -1, 64))
+	}
+
+	current := stage.AccessLogSettings
+	if current == nil {
+		current = &agTypes.AccessLogSettings{}
+	}
+	if settings.AccessLog != nil {
+		replace("/accessLogSettings/destinationArn", aws.ToString(current.DestinationArn), settings.AccessLog.Destination)
+		replace("/accessLogSettings/format", aws.ToString(current.Format), settings.AccessLog.Format)
+	} else if aws.ToString(current.DestinationArn) != "" {
+		operations = append(operations, agTypes.PatchOperation{
+			Op:   agTypes.OpRemove,
+			Path: aws.String("/accessLogSettings"),
+		})
+	}
+	return operations
+}
+
 func (a *ApiGateway) getRestApis() ([]*string, error) {
 	var apiIds []*string
 	resp, err := a.client.GetRestApis(context.TODO(), &apigateway.GetRestApisInput{
@@ -193,17 +376,26 @@ func (a *ApiGateway) createCFStack() error {
 		url = fmt.Sprintf("https://s3-us-gov-west-1.amazonaws.com/%s/%s", a.config.Bucket, template)
 	}
 
+	// stack tags propagate to the resources of the stack
+	resourceTags, err := resourceTags(a.config)
+	if err != nil {
+		return err
+	}
+	tags := []cfTypes.Tag{
+		{
+			Key:   aws.String("JermProject"),
+			Value: aws.String(a.config.GetFunctionName()),
+		},
+	}
+	for _, key := range sortedTagKeys(resourceTags) {
+		tags = append(tags, cfTypes.Tag{Key: aws.String(key), Value: aws.String(resourceTags[key])})
+	}
+
 	_, err = a.cfClient.DescribeStacks(context.TODO(), &cloudformation.DescribeStacksInput{
 		StackName: aws.String(a.config.GetFunctionName()),
 	})
 	if err != nil {
 		log.Debug("creating cloud formation stack...")
-		tags := []cfTypes.Tag{
-			{
-				Key:   aws.String("JermProject"),
-				Value: aws.String(a.config.GetFunctionName()),
-			},
-		}
 		_, err := a.cfClient.CreateStack(context.TODO(), &cloudformation.CreateStackInput{
 			StackName:    aws.String(a.config.GetFunctionName()),
 			TemplateURL:  aws.String(url),
@@ -217,6 +409,7 @@ func (a *ApiGateway) createCFStack() error {
 		a.cfClient.UpdateStack(context.TODO(), &cloudformation.UpdateStackInput{
 			StackName:    aws.String(a.config.GetFunctionName()),
 			TemplateURL:  aws.String(url),
+			Tags:         tags,
 			Capabilities: make([]cfTypes.Capability, 0),
 		})
 	}
@@ -282,21 +475,41 @@ func (a *ApiGateway) deleteStack() error {
 	return nil
 }
 
-func (a *ApiGateway) createMethods(functionArn *string, resourceId string, depth int) {
+// invocationUri returns the URI API Gateway invokes a function with
+func (a *ApiGateway) invocationUri(functionArn string) string {
+	return lambdaInvocationUri(a.awsConfig.Region, functionArn)
+}
+
+// lambdaInvocationUri returns the URI API Gateway invokes a function with in the region
+func lambdaInvocationUri(region, functionArn string) string {
 	pre := "aws-us-gov"
-	if a.awsConfig.Region != "us-gov-west-1" {
+	if region != "us-gov-west-1" {
 		pre = "aws"
 	}
-	integrationUri := fmt.Sprintf("arn:%s:apigateway:%s:lambda:path/2015-03-31/functions/%s/invocations", pre, a.awsConfig.Region, *functionArn)
-	methodName := "ANY"
+	return fmt.Sprintf("arn:%s:apigateway:%s:lambda:path/2015-03-31/functions/%s/invocations", pre, region, functionArn)
+}
+
+// createMethod adds a method of a resource integrated with a function to the template
+func (a *ApiGateway) createMethod(functionArn, resourceId, key, methodName string, auth config.Auth) {
+	integrationUri := a.invocationUri(functionArn)
 	method := &cfApigateway.Method{}
 
 	method.RestApiId = cf.Ref("Api")
 	method.ResourceId = resourceId
 	method.HttpMethod = methodName
 	method.AuthorizationType = aws.String("NONE")
-	method.ApiKeyRequired = aws.Bool(false)
-	a.cfTemplate.Resources[fmt.Sprintf("%s%v", methodName, depth)] = method
+	switch auth.Type {
+	case config.AuthIam:
+		method.AuthorizationType = aws.String("AWS_IAM")
+	case config.AuthCognito:
+		method.AuthorizationType = aws.String("COGNITO_USER_POOLS")
+		method.AuthorizerId = aws.String(cf.Ref("Authorizer"))
+	case config.AuthLambda:
+		method.AuthorizationType = aws.String("CUSTOM")
+		method.AuthorizerId = aws.String(cf.Ref("Authorizer"))
+	}
+	method.ApiKeyRequired = aws.Bool(auth.ApiKeys != nil)
+	a.cfTemplate.Resources[fmt.Sprintf("%s%s", methodName, key)] = method
 
 	method.Integration = &cfApigateway.Method_Integration{
 		CacheNamespace:        aws.String("none"),
@@ -308,6 +521,124 @@ func (a *ApiGateway) createMethods(functionArn *string, resourceId string, depth
 	}
 }
 
+// createCorsMethod adds an OPTIONS method answering the CORS preflight requests
+// of the resource without invoking the function or authorizing the requests.
+// With several origins the origin of the request is allowed when it's one of them.
+func (a *ApiGateway) createCorsMethod(resourceId, key string, cors *config.Cors) {
+	if cors == nil {
+		return
+	}
+	headers := map[string]string{
+		"Access-Control-Allow-Origin":  cors.Origins[0],
+		"Access-Control-Allow-Headers": strings.Join(cors.Headers, ","),
+		"Access-Control-Allow-Methods": strings.Join(cors.Methods, ","),
+	}
+	if cors.Credentials {
+		headers["Access-Control-Allow-Credentials"] = "true"
+	}
+	responseParameters := map[string]string{}
+	methodParameters := map[string]bool{}
+	for name, value := range headers {
+		parameter := fmt.Sprintf("method.response.header.%s", name)
+		responseParameters[parameter] = fmt.Sprintf("'%s'", value)
+		methodParameters[parameter] = false
+	}
+
+	responseTemplate := ""
+	if len(cors.Origins) > 1 {
+		var conditions []string
+		for _, origin := range cors.Origins {
+			conditions = append(conditions, fmt.Sprintf(`$origin == "%s"`, origin))
+		}
+		responseTemplate = fmt.Sprintf(`#set($origin = $input.params("Origin"))
+#if($origin == "")#set($origin = $input.params("origin"))#end
+#if(%s)#set($context.responseOverride.header.Access-Control-Allow-Origin = $origin)#end`, strings.Join(conditions, " || "))
+	}
+
+	method := &cfApigateway.Method{
+		RestApiId:         cf.Ref("Api"),
+		ResourceId:        resourceId,
+		HttpMethod:        "OPTIONS",
+		AuthorizationType: aws.String("NONE"),
+		ApiKeyRequired:    aws.Bool(false),
+		MethodResponses: []cfApigateway.Method_MethodResponse{
+			{
+				StatusCode:         "200",
+				ResponseParameters: methodParameters,
+			},
+		},
+		Integration: &cfApigateway.Method_Integration{
+			Type:                aws.String("MOCK"),
+			PassthroughBehavior: aws.String("WHEN_NO_MATCH"),
+			RequestTemplates:    map[string]string{"application/json": `{"statusCode": 200}`},
+			IntegrationResponses: []cfApigateway.Method_IntegrationResponse{
+				{
+					StatusCode:         "200",
+					ResponseParameters: responseParameters,
+					ResponseTemplates:  map[string]string{"application/json": responseTemplate},
+				},
+			},
+		},
+	}
+	a.cfTemplate.Resources[fmt.Sprintf("OPTIONS%s", key)] = method
+}
+
+// createAuthorizer adds the Cognito or Lambda authorizer of the methods to the template.
+// A Lambda authorizer with a handler invokes the function with its handler.
+func (a *ApiGateway) createAuthorizer(functionArn *string, auth config.Auth) {
+	authorizer := &cfApigateway.Authorizer{
+		Name:      fmt.Sprintf("%s-authorizer", a.config.GetFunctionName()),
+		RestApiId: cf.Ref("Api"),
+	}
+	switch auth.Type {
+	case config.AuthCognito:
+		authorizer.Type = "COGNITO_USER_POOLS"
+		authorizer.ProviderARNs = auth.Cognito.UserPools
+		authorizer.IdentitySource = aws.String(auth.Cognito.IdentitySource)
+	case config.AuthLambda:
+		function := auth.Lambda.Function
+		if function == "" {
+			function = *functionArn
+		}
+		authorizer.Type = strings.ToUpper(auth.Lambda.Type)
+		authorizer.AuthorizerUri = aws.String(a.invocationUri(function))
+		authorizer.AuthorizerCredentials = aws.String(a.config.Platform.Role)
+		authorizer.AuthorizerResultTtlInSeconds = auth.Lambda.ResultTtl
+		authorizer.IdentitySource = aws.String(auth.Lambda.IdentitySource)
+	default:
+		return
+	}
+	a.cfTemplate.Resources["Authorizer"] = authorizer
+}
+
+// createUsagePlan adds the usage plan of the API keys to the template.
+// The stage is added to the plan after it's deployed.
+func (a *ApiGateway) createUsagePlan(auth config.Auth) {
+	if auth.ApiKeys == nil {
+		return
+	}
+	plan := &cfApigateway.UsagePlan{
+		UsagePlanName: aws.String(a.config.GetFunctionName()),
+		Description:   aws.String("Automatically created by Jerm"),
+	}
+	if auth.ApiKeys.Quota != nil {
+		plan.Quota = &cfApigateway.UsagePlan_QuotaSettings{
+			Limit:  aws.Int(auth.ApiKeys.Quota.Limit),
+			Period: aws.String(auth.ApiKeys.Quota.Period),
+		}
+	}
+	if auth.ApiKeys.BurstLimit > 0 || auth.ApiKeys.RateLimit > 0 {
+		plan.Throttle = &cfApigateway.UsagePlan_ThrottleSettings{}
+		if auth.ApiKeys.BurstLimit > 0 {
+			plan.Throttle.BurstLimit = aws.Int(auth.ApiKeys.BurstLimit)
+		}
+		if auth.ApiKeys.RateLimit > 0 {
+			plan.Throttle.RateLimit = aws.Float64(auth.ApiKeys.RateLimit)
+		}
+	}
+	a.cfTemplate.Resources["UsagePlan"] = plan
+}
+
 // deleteLogs deletes API gateway logs
 func (a *ApiGateway) deleteLogs() error {
 	log.Debug("deleting API Gateway logs...")
@@ -3,12 +3,16 @@ package aws
 import (
 	"context"
 	"fmt"
+	"sort"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsConfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/apigateway"
+	agTypes "github.com/aws/aws-sdk-go-v2/service/apigateway/types"
 	"github.com/aws/smithy-go/middleware"
+	cf "github.com/awslabs/goformation/v7/cloudformation"
+	cfApigateway "github.com/awslabs/goformation/v7/cloudformation/apigateway"
 	"github.com/spatocode/jerm/config"
 	"github.com/stretchr/testify/assert"
 )
@@ -103,3 +107,242 @@ func TestApiGatewayGetRestApis(t *testing.T) {
 		})
 	}
 }
+
+func TestStagePatchOperations(t *testing.T) {
+	assert := assert.New(t)
+	cfg := &config.Config{}
+	settings, err := cfg.GetApiGateway()
+	assert.Nil(err)
+
+	// a new stage already has the default settings
+	stage := &apigateway.GetStageOutput{}
+	assert.Empty(stagePatchOperations(stage, settings))
+
+	ttl := 60
+	cfg.ApiGateway = &config.ApiGateway{
+		CacheCluster:         true,
+		CacheClusterSize:     "1.6",
+		CacheTtl:             &ttl,
+		LogLevel:             "error",
+		DetailedMetrics:      true,
+		ThrottlingBurstLimit: 100,
+		ThrottlingRateLimit:  50.5,
+		AccessLog:            &config.AccessLog{Destination: "arn:aws:logs:us-west-1:123456789012:log-group:access"},
+	}
+	settings, err = cfg.GetApiGateway()
+	assert.Nil(err)
+	operations := stagePatchOperations(stage, settings)
+	var patches []string
+	for _, operation := range operations {
+		patches = append(patches, fmt.Sprintf("%s %s %s", operation.Op, *operation.Path, aws.ToString(operation.Value)))
+	}
+	assert.Equal([]string{
+		"replace /cacheClusterEnabled true",
+		"replace /cacheClusterSize 1.6",
+		"replace /*/*/caching/enabled true",
+		"replace /*/*/caching/ttlInSeconds 60",
+		"replace /*/*/logging/loglevel ERROR",
+		"replace /*/*/metrics/enabled true",
+		"replace /*/*/throttling/burstLimit 100",
+		"replace /*/*/throttling/rateLimit 50.5",
+		"replace /accessLogSettings/destinationArn arn:aws:logs:us-west-1:123456789012:log-group:access",
+		"replace /accessLogSettings/format " + config.DefaultAccessLogFormat,
+	}, patches)
+
+	stage = &apigateway.GetStageOutput{
+		CacheClusterEnabled: true,
+		CacheClusterSize:    agTypes.CacheClusterSizeSize1Point6Gb,
+		MethodSettings: map[string]agTypes.MethodSetting{"*/*": {
+			CachingEnabled:       true,
+			CacheTtlInSeconds:    60,
+			LoggingLevel:         aws.String("ERROR"),
+			MetricsEnabled:       true,
+			ThrottlingBurstLimit: 100,
+			ThrottlingRateLimit:  50.5,
+		}},
+		AccessLogSettings: &agTypes.AccessLogSettings{
+			DestinationArn: aws.String("arn:aws:logs:us-west-1:123456789012:log-group:access"),
+			Format:         aws.String(config.DefaultAccessLogFormat),
+		},
+	}
+	assert.Empty(stagePatchOperations(stage, settings))
+
+	cfg.ApiGateway = nil
+	settings, err = cfg.GetApiGateway()
+	assert.Nil(err)
+	operations = stagePatchOperations(stage, settings)
+	assert.Len(operations, 6)
+	assert.Equal("/cacheClusterEnabled", *operations[0].Path)
+	assert.Equal(agTypes.OpRemove, operations[5].Op)
+	assert.Equal("/accessLogSettings", *operations[5].Path)
+}
+
+func TestApiGatewayAuthTemplate(t *testing.T) {
+	assert := assert.New(t)
+	cfg := &config.Config{Name: "test", Stage: "dev", Platform: config.Platform{Role: "arn:aws:iam::123456789012:role/test"}}
+	a := NewApiGateway(cfg, aws.Config{Region: "us-west-1"})
+	functionArn := aws.String(testAliasArn)
+
+	a.cfTemplate = cf.NewTemplate()
+	a.createAuthorizer(functionArn, config.Auth{})
+	a.createUsagePlan(config.Auth{})
+	a.createMethod(*functionArn, "root", "0", "ANY", config.Auth{})
+	assert.Len(a.cfTemplate.Resources, 1)
+	method := a.cfTemplate.Resources["ANY0"].(*cfApigateway.Method)
+	assert.Equal("NONE", *method.AuthorizationType)
+	assert.Nil(method.AuthorizerId)
+	assert.False(*method.ApiKeyRequired)
+
+	cfg.Auth = &config.Auth{
+		Type:    config.AuthLambda,
+		ApiKeys: &config.ApiKeys{Quota: &config.Quota{Limit: 1000, Period: "month"}, BurstLimit: 5},
+		Lambda:  &config.LambdaAuthorizer{Handler: "auth.handler", Type: "request"},
+	}
+	auth, err := cfg.GetAuth()
+	assert.Nil(err)
+	a.cfTemplate = cf.NewTemplate()
+	a.createAuthorizer(functionArn, auth)
+	a.createUsagePlan(auth)
+	a.createMethod(*functionArn, "root", "0", "ANY", auth)
+	method = a.cfTemplate.Resources["ANY0"].(*cfApigateway.Method)
+	assert.Equal("CUSTOM", *method.AuthorizationType)
+	assert.Equal(cf.Ref("Authorizer"), *method.AuthorizerId)
+	assert.True(*method.ApiKeyRequired)
+	authorizer := a.cfTemplate.Resources["Authorizer"].(*cfApigateway.Authorizer)
+	assert.Equal("REQUEST", authorizer.Type)
+	assert.Equal(fmt.Sprintf("arn:aws:apigateway:us-west-1:lambda:path/2015-03-31/functions/%s/invocations", testAliasArn), *authorizer.AuthorizerUri)
+	assert.Equal(cfg.Platform.Role, *authorizer.AuthorizerCredentials)
+	assert.Equal(config.DefaultAuthorizerResultTtl, *authorizer.AuthorizerResultTtlInSeconds)
+	plan := a.cfTemplate.Resources["UsagePlan"].(*cfApigateway.UsagePlan)
+	assert.Equal("test-dev", *plan.UsagePlanName)
+	assert.Equal(1000, *plan.Quota.Limit)
+	assert.Equal("MONTH", *plan.Quota.Period)
+	assert.Equal(5, *plan.Throttle.BurstLimit)
+	assert.Nil(plan.Throttle.RateLimit)
+
+	auth = config.Auth{Type: config.AuthCognito, Cognito: &config.CognitoAuthorizer{
+		UserPools:      []string{"arn:aws:cognito-idp:us-west-1:123456789012:userpool/pool"},
+		IdentitySource: config.DefaultIdentitySource,
+	}}
+	a.cfTemplate = cf.NewTemplate()
+	a.createAuthorizer(functionArn, auth)
+	a.createMethod(*functionArn, "root", "0", "ANY", auth)
+	method = a.cfTemplate.Resources["ANY0"].(*cfApigateway.Method)
+	assert.Equal("COGNITO_USER_POOLS", *method.AuthorizationType)
+	authorizer = a.cfTemplate.Resources["Authorizer"].(*cfApigateway.Authorizer)
+	assert.Equal(auth.Cognito.UserPools, authorizer.ProviderARNs)
+	assert.Nil(authorizer.AuthorizerUri)
+
+	a.cfTemplate = cf.NewTemplate()
+	a.createAuthorizer(functionArn, config.Auth{Type: config.AuthIam})
+	a.createMethod(*functionArn, "root", "0", "ANY", config.Auth{Type: config.AuthIam})
+	method = a.cfTemplate.Resources["ANY0"].(*cfApigateway.Method)
+	assert.Equal("AWS_IAM", *method.AuthorizationType)
+	assert.Nil(method.AuthorizerId)
+	assert.NotContains(a.cfTemplate.Resources, "Authorizer")
+}
+
+func TestApiGatewayCorsMethod(t *testing.T) {
+	assert := assert.New(t)
+	a := NewApiGateway(&config.Config{Name: "test", Stage: "dev"}, aws.Config{Region: "us-west-1"})
+
+	a.cfTemplate = cf.NewTemplate()
+	a.createCorsMethod("root", "0", nil)
+	assert.Empty(a.cfTemplate.Resources)
+
+	cfg := &config.Config{ApiGateway: &config.ApiGateway{Cors: &config.Cors{Origins: []string{"https://example.com"}, Credentials: true}}}
+	settings, err := cfg.GetApiGateway()
+	assert.Nil(err)
+	a.createCorsMethod("root", "0", settings.Cors)
+	method := a.cfTemplate.Resources["OPTIONS0"].(*cfApigateway.Method)
+	assert.Equal("OPTIONS", method.HttpMethod)
+	assert.Equal("NONE", *method.AuthorizationType)
+	assert.False(*method.ApiKeyRequired)
+	assert.Equal("MOCK", *method.Integration.Type)
+	response := method.Integration.IntegrationResponses[0]
+	assert.Equal(map[string]string{
+		"method.response.header.Access-Control-Allow-Origin":      "'https://example.com'",
+		"method.response.header.Access-Control-Allow-Headers":     "'Content-Type,X-Amz-Date,Authorization,X-Api-Key,X-Amz-Security-Token'",
+		"method.response.header.Access-Control-Allow-Methods":     "'DELETE,GET,HEAD,OPTIONS,PATCH,POST,PUT'",
+		"method.response.header.Access-Control-Allow-Credentials": "'true'",
+	}, response.ResponseParameters)
+	assert.Equal("", response.ResponseTemplates["application/json"])
+	assert.Len(method.MethodResponses[0].ResponseParameters, 4)
+
+	cors := &config.Cors{Origins: []string{"https://a.com", "https://b.com"}, Headers: []string{"X-Token"}, Methods: []string{"GET"}}
+	a.createCorsMethod("proxy", "1", cors)
+	response = a.cfTemplate.Resources["OPTIONS1"].(*cfApigateway.Method).Integration.IntegrationResponses[0]
+	assert.Equal("'https://a.com'", response.ResponseParameters["method.response.header.Access-Control-Allow-Origin"])
+	assert.NotContains(response.ResponseParameters, "method.response.header.Access-Control-Allow-Credentials")
+	assert.Contains(response.ResponseTemplates["application/json"], `#if($origin == "https://a.com" || $origin == "https://b.com")`)
+}
+
+func TestResourceTree(t *testing.T) {
+	assert := assert.New(t)
+
+	tree := resourceTree("main", nil)
+	assert.Equal(map[string]string{"ANY": "main"}, tree.methods)
+	assert.Len(tree.children, 1)
+	assert.Equal("/{proxy+}", tree.children["{proxy+}"].path)
+	assert.Equal(map[string]string{"ANY": "main"}, tree.children["{proxy+}"].methods)
+	assert.Empty(tree.children["{proxy+}"].children)
+
+	tree = resourceTree("main", []apiRoute{
+		{path: "/reports/{proxy+}", methods: []string{"ANY"}, functionArn: "reports"},
+		{path: "/users/{id}", methods: []string{"GET", "PUT"}, functionArn: "users"},
+	})
+	assert.Equal([]string{"reports", "users", "{proxy+}"}, sortedKeys(tree.children))
+	reports := tree.children["reports"]
+	assert.Equal(map[string]string{"ANY": "main"}, reports.methods)
+	assert.Equal([]string{"{proxy+}"}, sortedKeys(reports.children))
+	assert.Equal(map[string]string{"ANY": "reports"}, reports.children["{proxy+}"].methods)
+
+	users := tree.children["users"]
+	assert.Equal([]string{"{id}"}, sortedKeys(users.children))
+	user := users.children["{id}"]
+	assert.Equal("/users/{id}", user.path)
+	assert.Equal(map[string]string{"ANY": "main", "GET": "users", "PUT": "users"}, user.methods)
+	assert.Equal([]string{"{proxy+}"}, sortedKeys(user.children))
+
+	// a path variable under the root replaces its greedy path
+	tree = resourceTree("main", []apiRoute{{path: "/{tenant}", methods: []string{"ANY"}, functionArn: "tenants"}})
+	assert.Equal([]string{"{tenant}"}, sortedKeys(tree.children))
+}
+
+func TestApiGatewayCreateResources(t *testing.T) {
+	assert := assert.New(t)
+	a := NewApiGateway(&config.Config{Name: "test", Stage: "dev"}, aws.Config{Region: "us-west-1"})
+	a.cfTemplate = cf.NewTemplate()
+	tree := resourceTree("main", []apiRoute{{path: "/reports", methods: []string{"GET", "OPTIONS"}, functionArn: "reports"}})
+	a.createResources(tree, "root", config.Auth{}, &config.Cors{Origins: []string{"*"}})
+
+	// the resources deployed before routes keep their logical IDs
+	assert.Contains(a.cfTemplate.Resources, "ANY0")
+	assert.Contains(a.cfTemplate.Resources, "OPTIONS0")
+	assert.Equal("{proxy+}", a.cfTemplate.Resources["ResourceAnyPathSlashed"].(*cfApigateway.Resource).PathPart)
+	assert.Contains(a.cfTemplate.Resources, "ANY1")
+
+	key := resourceKey("/reports")
+	resource := a.cfTemplate.Resources["Resource"+key].(*cfApigateway.Resource)
+	assert.Equal("reports", resource.PathPart)
+	assert.Equal("root", resource.ParentId)
+	get := a.cfTemplate.Resources["GET"+key].(*cfApigateway.Method)
+	assert.Equal(cf.Ref("Resource"+key), get.ResourceId)
+	assert.Equal(a.invocationUri("reports"), *get.Integration.Uri)
+	assert.Equal(a.invocationUri("main"), *a.cfTemplate.Resources["ANY"+key].(*cfApigateway.Method).Integration.Uri)
+	// the OPTIONS route answers the preflight requests of its resource
+	assert.Equal("AWS_PROXY", *a.cfTemplate.Resources["OPTIONS"+key].(*cfApigateway.Method).Integration.Type)
+
+	proxy := a.cfTemplate.Resources[resourceLogicalId("/reports/{proxy+}")].(*cfApigateway.Resource)
+	assert.Equal(cf.Ref("Resource"+key), proxy.ParentId)
+	assert.Len(a.cfTemplate.Resources, 12)
+}
+
+func sortedKeys(resources map[string]*apiResource) []string {
+	var keys []string
+	for key := range resources {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
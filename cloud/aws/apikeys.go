@@ -0,0 +1,275 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/apigateway"
+	agTypes "github.com/aws/aws-sdk-go-v2/service/apigateway/types"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+
+	"github.com/spatocode/jerm"
+	"github.com/spatocode/jerm/internal/log"
+)
+
+// getUsagePlanId returns the ID of the usage plan of the API keys of the stage.
+// It's empty when the stack has no usage plan.
+func (a *ApiGateway) getUsagePlanId() (string, error) {
+	resp, err := a.cfClient.DescribeStackResource(context.TODO(), &cloudformation.DescribeStackResourceInput{
+		StackName:         aws.String(a.config.GetFunctionName()),
+		LogicalResourceId: aws.String("UsagePlan"),
+	})
+	if err != nil {
+		// the stack or its usage plan doesn't exist
+		log.Debug(fmt.Sprintf("unable to find usage plan of %s: %s", a.config.GetFunctionName(), err))
+		return "", nil
+	}
+	return aws.ToString(resp.StackResourceDetail.PhysicalResourceId), nil
+}
+
+// usagePlanStage returns the ID of the usage plan with the stage of the API
+// in the format the usage plan identifies its stages
+func (a *ApiGateway) usagePlanStage(apiId string) string {
+	return fmt.Sprintf("%s:%s", apiId, a.config.Stage)
+}
+
+// attachUsagePlan adds the stage to the usage plan of the API keys
+func (a *ApiGateway) attachUsagePlan(apiId *string) error {
+	planId, err := a.getUsagePlanId()
+	if err != nil || planId == "" {
+		return err
+	}
+
+	plan, err := a.client.GetUsagePlan(context.TODO(), &apigateway.GetUsagePlanInput{
+		UsagePlanId: aws.String(planId),
+	})
+	if err != nil {
+		return err
+	}
+	for _, stage := range plan.ApiStages {
+		if aws.ToString(stage.ApiId) == aws.ToString(apiId) && aws.ToString(stage.Stage) == a.config.Stage {
+			return nil
+		}
+	}
+
+	log.Debug("adding stage to usage plan...")
+	_, err = a.client.UpdateUsagePlan(context.TODO(), &apigateway.UpdateUsagePlanInput{
+		UsagePlanId: aws.String(planId),
+		PatchOperations: []agTypes.PatchOperation{
+			{
+				Op:    agTypes.OpAdd,
+				Path:  aws.String("/apiStages"),
+				Value: aws.String(a.usagePlanStage(aws.ToString(apiId))),
+			},
+		},
+	})
+	return err
+}
+
+// detachUsagePlan removes the stages of the usage plan of the API keys.
+// A usage plan with stages can't be deleted.
+func (a *ApiGateway) detachUsagePlan() error {
+	planId, err := a.getUsagePlanId()
+	if err != nil || planId == "" {
+		return err
+	}
+
+	plan, err := a.client.GetUsagePlan(context.TODO(), &apigateway.GetUsagePlanInput{
+		UsagePlanId: aws.String(planId),
+	})
+	if err != nil {
+		return err
+	}
+	var operations []agTypes.PatchOperation
+	for _, stage := range plan.ApiStages {
+		operations = append(operations, agTypes.PatchOperation{
+			Op:    agTypes.OpRemove,
+			Path:  aws.String("/apiStages"),
+			Value: aws.String(fmt.Sprintf("%s:%s", aws.ToString(stage.ApiId), aws.ToString(stage.Stage))),
+		})
+	}
+	if len(operations) == 0 {
+		return nil
+	}
+
+	log.Debug("removing stages from usage plan...")
+	_, err = a.client.UpdateUsagePlan(context.TODO(), &apigateway.UpdateUsagePlanInput{
+		UsagePlanId:     aws.String(planId),
+		PatchOperations: operations,
+	})
+	return err
+}
+
+// requireUsagePlan returns the ID of the usage plan of the API keys or
+// an error when the deployed stage doesn't use API keys
+func (a *ApiGateway) requireUsagePlan() (string, error) {
+	if a.config.Auth == nil || a.config.Auth.ApiKeys == nil {
+		msg := "api keys aren't enabled. Set auth.api_keys in your jerm.json file"
+		return "", errors.New(msg)
+	}
+	planId, err := a.getUsagePlanId()
+	if err != nil {
+		return "", err
+	}
+	if planId == "" {
+		msg := "can't find the usage plan of the API keys. Run 'jerm deploy' to deploy instead"
+		return "", errors.New(msg)
+	}
+	return planId, nil
+}
+
+// createApiKey creates an API key and adds it to the usage plan of the stage
+func (a *ApiGateway) createApiKey(name string) (jerm.ApiKey, error) {
+	planId, err := a.requireUsagePlan()
+	if err != nil {
+		return jerm.ApiKey{}, err
+	}
+
+	tags, err := resourceTags(a.config)
+	if err != nil {
+		return jerm.ApiKey{}, err
+	}
+
+	log.Debug(fmt.Sprintf("creating api key %s...", name))
+	key, err := a.client.CreateApiKey(context.TODO(), &apigateway.CreateApiKeyInput{
+		Name:        aws.String(name),
+		Description: aws.String(fmt.Sprintf("API key of %s. Automatically created by Jerm", a.config.GetFunctionName())),
+		Enabled:     true,
+		Tags:        tags,
+	})
+	if err != nil {
+		return jerm.ApiKey{}, err
+	}
+
+	_, err = a.client.CreateUsagePlanKey(context.TODO(), &apigateway.CreateUsagePlanKeyInput{
+		UsagePlanId: aws.String(planId),
+		KeyId:       key.Id,
+		KeyType:     aws.String("API_KEY"),
+	})
+	if err != nil {
+		return jerm.ApiKey{}, err
+	}
+	return jerm.ApiKey{
+		Id:      aws.ToString(key.Id),
+		Name:    aws.ToString(key.Name),
+		Value:   aws.ToString(key.Value),
+		Enabled: key.Enabled,
+		Created: aws.ToTime(key.CreatedDate),
+	}, nil
+}
+
+// getUsagePlanKeys returns the API keys of the usage plan
+func (a *ApiGateway) getUsagePlanKeys(planId string) ([]agTypes.UsagePlanKey, error) {
+	var keys []agTypes.UsagePlanKey
+	paginator := apigateway.NewGetUsagePlanKeysPaginator(a.client, &apigateway.GetUsagePlanKeysInput{
+		UsagePlanId: aws.String(planId),
+	})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, resp.Items...)
+	}
+	return keys, nil
+}
+
+// listApiKeys returns the API keys of the stage without their values
+func (a *ApiGateway) listApiKeys() ([]jerm.ApiKey, error) {
+	planId, err := a.requireUsagePlan()
+	if err != nil {
+		return nil, err
+	}
+
+	planKeys, err := a.getUsagePlanKeys(planId)
+	if err != nil {
+		return nil, err
+	}
+	var keys []jerm.ApiKey
+	for _, planKey := range planKeys {
+		key, err := a.client.GetApiKey(context.TODO(), &apigateway.GetApiKeyInput{
+			ApiKey: planKey.Id,
+		})
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, jerm.ApiKey{
+			Id:      aws.ToString(key.Id),
+			Name:    aws.ToString(key.Name),
+			Enabled: key.Enabled,
+			Created: aws.ToTime(key.CreatedDate),
+		})
+	}
+	return keys, nil
+}
+
+// revokeApiKey deletes the API key of the stage with the ID or name
+func (a *ApiGateway) revokeApiKey(key string) error {
+	planId, err := a.requireUsagePlan()
+	if err != nil {
+		return err
+	}
+
+	planKeys, err := a.getUsagePlanKeys(planId)
+	if err != nil {
+		return err
+	}
+	var ids []*string
+	for _, planKey := range planKeys {
+		if aws.ToString(planKey.Id) == key || aws.ToString(planKey.Name) == key {
+			ids = append(ids, planKey.Id)
+		}
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("can't find api key %s", key)
+	}
+	if len(ids) > 1 {
+		return fmt.Errorf("more than one api key is named %s. Revoke it with its ID instead", key)
+	}
+
+	log.Debug(fmt.Sprintf("deleting api key %s...", key))
+	_, err = a.client.DeleteApiKey(context.TODO(), &apigateway.DeleteApiKeyInput{
+		ApiKey: ids[0],
+	})
+	return err
+}
+
+// deleteApiKeys deletes the API keys of the usage plan and removes its stages
+// so the stack can delete the plan
+func (a *ApiGateway) deleteApiKeys() error {
+	planId, err := a.getUsagePlanId()
+	if err != nil || planId == "" {
+		return err
+	}
+
+	planKeys, err := a.getUsagePlanKeys(planId)
+	if err != nil {
+		return err
+	}
+	for _, planKey := range planKeys {
+		_, err = a.client.DeleteApiKey(context.TODO(), &apigateway.DeleteApiKeyInput{
+			ApiKey: planKey.Id,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return a.detachUsagePlan()
+}
+
+// CreateApiKey creates an API key for the stage and returns it with its value
+func (l *Lambda) CreateApiKey(name string) (jerm.ApiKey, error) {
+	return l.apigateway.createApiKey(name)
+}
+
+// ListApiKeys lists the API keys of the stage
+func (l *Lambda) ListApiKeys() ([]jerm.ApiKey, error) {
+	return l.apigateway.listApiKeys()
+}
+
+// RevokeApiKey deletes an API key of the stage by its ID or name
+func (l *Lambda) RevokeApiKey(key string) error {
+	return l.apigateway.revokeApiKey(key)
+}
@@ -0,0 +1,192 @@
+package aws
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/apigateway"
+	agTypes "github.com/aws/aws-sdk-go-v2/service/apigateway/types"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/spatocode/jerm"
+	"github.com/spatocode/jerm/config"
+	"github.com/stretchr/testify/assert"
+)
+
+var testKeyCreated = time.Date(2023, 8, 1, 12, 0, 0, 0, time.UTC)
+
+func TestApiGatewayApiKeys(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		name       string
+		auth       *config.Auth
+		call       func(a *ApiGateway) (interface{}, error)
+		want       interface{}
+		wantErr    error
+		operations []string
+	}{
+		{
+			name:       "create api key",
+			auth:       &config.Auth{ApiKeys: &config.ApiKeys{}},
+			call:       func(a *ApiGateway) (interface{}, error) { return a.createApiKey("partner") },
+			want:       jerm.ApiKey{Id: "key3", Name: "partner", Value: "secret", Enabled: true, Created: testKeyCreated},
+			operations: []string{"DescribeStackResource", "CreateApiKey", "CreateUsagePlanKey"},
+		},
+		{
+			name:    "create api key without api keys enabled",
+			call:    func(a *ApiGateway) (interface{}, error) { return a.createApiKey("partner") },
+			wantErr: fmt.Errorf("api keys aren't enabled. Set auth.api_keys in your jerm.json file"),
+		},
+		{
+			name: "list api keys",
+			auth: &config.Auth{ApiKeys: &config.ApiKeys{}},
+			call: func(a *ApiGateway) (interface{}, error) { return a.listApiKeys() },
+			want: []jerm.ApiKey{
+				{Id: "key1", Name: "name-key1", Enabled: true, Created: testKeyCreated},
+				{Id: "key2", Name: "name-key2", Enabled: true, Created: testKeyCreated},
+			},
+			operations: []string{"DescribeStackResource", "GetUsagePlanKeys", "GetApiKey", "GetApiKey"},
+		},
+		{
+			name:       "revoke api key",
+			auth:       &config.Auth{ApiKeys: &config.ApiKeys{}},
+			call:       func(a *ApiGateway) (interface{}, error) { return nil, a.revokeApiKey("mobile") },
+			operations: []string{"DescribeStackResource", "GetUsagePlanKeys", "DeleteApiKey"},
+		},
+		{
+			name:    "revoke unknown api key",
+			auth:    &config.Auth{ApiKeys: &config.ApiKeys{}},
+			call:    func(a *ApiGateway) (interface{}, error) { return nil, a.revokeApiKey("unknown") },
+			wantErr: fmt.Errorf("can't find api key unknown"),
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			withAPIOptionsFunc, recorder := mockApi(func(operation string, input interface{}) (interface{}, error) {
+				switch operation {
+				case "DescribeStackResource":
+					return &cloudformation.DescribeStackResourceOutput{StackResourceDetail: &cfTypes.StackResourceDetail{
+						PhysicalResourceId: aws.String("plan1"),
+					}}, nil
+				case "CreateApiKey":
+					return &apigateway.CreateApiKeyOutput{Id: aws.String("key3"), Name: aws.String("partner"), Value: aws.String("secret"), Enabled: true, CreatedDate: aws.Time(testKeyCreated)}, nil
+				case "CreateUsagePlanKey":
+					return &apigateway.CreateUsagePlanKeyOutput{}, nil
+				case "GetUsagePlanKeys":
+					return &apigateway.GetUsagePlanKeysOutput{Items: []agTypes.UsagePlanKey{
+						{Id: aws.String("key1"), Name: aws.String("web")},
+						{Id: aws.String("key2"), Name: aws.String("mobile")},
+					}}, nil
+				case "GetApiKey":
+					id := input.(*apigateway.GetApiKeyInput).ApiKey
+					return &apigateway.GetApiKeyOutput{Id: id, Name: aws.String("name-" + *id), Enabled: true, CreatedDate: aws.Time(testKeyCreated)}, nil
+				case "DeleteApiKey":
+					return &apigateway.DeleteApiKeyOutput{}, nil
+				}
+				return nil, errUnexpectedOperation
+			})
+			l := helperLambda(t, withAPIOptionsFunc)
+			l.config.Auth = tt.auth
+
+			got, err := tt.call(l.apigateway)
+			if tt.wantErr != nil {
+				assert.EqualError(err, tt.wantErr.Error())
+				return
+			}
+			assert.Nil(err)
+			if tt.want != nil {
+				assert.Equal(tt.want, got)
+			}
+			assert.Equal(tt.operations, recorder.operations)
+
+			for _, input := range recorder.inputs {
+				switch input := input.(type) {
+				case *apigateway.CreateApiKeyInput:
+					assert.Equal("test", input.Tags[config.TagProject])
+					assert.Equal("dev", input.Tags[config.TagStage])
+					assert.NotContains(input.Tags, "JermProject")
+				case *apigateway.CreateUsagePlanKeyInput:
+					assert.Equal("plan1", *input.UsagePlanId)
+					assert.Equal("key3", *input.KeyId)
+					assert.Equal("API_KEY", *input.KeyType)
+				case *apigateway.DeleteApiKeyInput:
+					assert.Equal("key2", *input.ApiKey)
+				}
+			}
+		})
+	}
+}
+
+func TestApiGatewayUsagePlanStages(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		name       string
+		call       func(a *ApiGateway) error
+		operations []string
+		patch      *agTypes.PatchOperation
+	}{
+		{
+			name:       "attach usage plan to attached api",
+			call:       func(a *ApiGateway) error { return a.attachUsagePlan(aws.String("api1")) },
+			operations: []string{"DescribeStackResource", "GetUsagePlan"},
+		},
+		{
+			name:       "attach usage plan to api",
+			call:       func(a *ApiGateway) error { return a.attachUsagePlan(aws.String("api2")) },
+			operations: []string{"DescribeStackResource", "GetUsagePlan", "UpdateUsagePlan"},
+			patch:      &agTypes.PatchOperation{Op: agTypes.OpAdd, Path: aws.String("/apiStages"), Value: aws.String("api2:dev")},
+		},
+		{
+			name: "delete api keys",
+			call: func(a *ApiGateway) error { return a.deleteApiKeys() },
+			operations: []string{
+				"DescribeStackResource", "GetUsagePlanKeys", "DeleteApiKey", "DeleteApiKey",
+				"DescribeStackResource", "GetUsagePlan", "UpdateUsagePlan",
+			},
+			patch: &agTypes.PatchOperation{Op: agTypes.OpRemove, Path: aws.String("/apiStages"), Value: aws.String("api1:dev")},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			withAPIOptionsFunc, recorder := mockApi(func(operation string, input interface{}) (interface{}, error) {
+				switch operation {
+				case "DescribeStackResource":
+					return &cloudformation.DescribeStackResourceOutput{StackResourceDetail: &cfTypes.StackResourceDetail{
+						PhysicalResourceId: aws.String("plan1"),
+					}}, nil
+				case "GetUsagePlan":
+					return &apigateway.GetUsagePlanOutput{Id: aws.String("plan1"), ApiStages: []agTypes.ApiStage{
+						{ApiId: aws.String("api1"), Stage: aws.String("dev")},
+					}}, nil
+				case "UpdateUsagePlan":
+					return &apigateway.UpdateUsagePlanOutput{}, nil
+				case "GetUsagePlanKeys":
+					return &apigateway.GetUsagePlanKeysOutput{Items: []agTypes.UsagePlanKey{
+						{Id: aws.String("key1"), Name: aws.String("web")},
+						{Id: aws.String("key2"), Name: aws.String("mobile")},
+					}}, nil
+				case "DeleteApiKey":
+					return &apigateway.DeleteApiKeyOutput{}, nil
+				}
+				return nil, errUnexpectedOperation
+			})
+			l := helperLambda(t, withAPIOptionsFunc)
+			l.config.Auth = &config.Auth{ApiKeys: &config.ApiKeys{}}
+
+			assert.Nil(tt.call(l.apigateway))
+			assert.Equal(tt.operations, recorder.operations)
+			if tt.patch != nil {
+				update := recorder.inputs[len(recorder.inputs)-1].(*apigateway.UpdateUsagePlanInput)
+				assert.Equal(tt.patch.Op, update.PatchOperations[0].Op)
+				assert.Equal(*tt.patch.Path, *update.PatchOperations[0].Path)
+				assert.Equal(*tt.patch.Value, *update.PatchOperations[0].Value)
+			}
+		})
+	}
+}
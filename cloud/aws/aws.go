@@ -104,6 +104,13 @@ const (
 					"route53:*"
 				],
 				"Resource": "*"
+			},
+			{
+				"Effect": "Allow",
+				"Action": [
+					"execute-api:ManageConnections"
+				],
+				"Resource": "arn:aws:execute-api:*:*:*"
 			}
 		]
 	}`
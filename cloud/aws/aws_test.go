@@ -0,0 +1,114 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsMiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	awsConfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/spatocode/jerm/config"
+)
+
+// errUnexpectedOperation is returned by mocks for the operations they don't expect
+var errUnexpectedOperation = fmt.Errorf("unexpected operation")
+
+// mockInputKey is the stack value of the input of the mocked operation
+type mockInputKey struct{}
+
+// apiRecorder records the operations of a mocked AWS API with their inputs
+type apiRecorder struct {
+	operations []string
+	inputs     []interface{}
+}
+
+// mockApi returns the API options of an AWS API answering every operation with
+// mock, which gets the operation and its input, and the recorder of the operations
+func mockApi(mock func(operation string, input interface{}) (interface{}, error)) (func(*middleware.Stack) error, *apiRecorder) {
+	recorder := &apiRecorder{}
+	return func(s *middleware.Stack) error {
+		err := s.Initialize.Add(
+			middleware.InitializeMiddlewareFunc(
+				"RecordInput",
+				func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+					recorder.inputs = append(recorder.inputs, in.Parameters)
+					ctx = middleware.WithStackValue(ctx, mockInputKey{}, in.Parameters)
+					return next.HandleInitialize(ctx, in)
+				},
+			),
+			middleware.Before,
+		)
+		if err != nil {
+			return err
+		}
+		return s.Finalize.Add(
+			middleware.FinalizeMiddlewareFunc(
+				"ApiMock",
+				func(ctx context.Context, fi middleware.FinalizeInput, fh middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+					operation := awsMiddleware.GetOperationName(ctx)
+					recorder.operations = append(recorder.operations, operation)
+					result, err := mock(operation, middleware.GetStackValue(ctx, mockInputKey{}))
+					return middleware.FinalizeOutput{Result: result}, middleware.Metadata{}, err
+				},
+			),
+			middleware.Before,
+		)
+	}, recorder
+}
+
+// mockAwsConfig returns the AWS config of a mocked AWS API in us-west-1
+func mockAwsConfig(t *testing.T, withAPIOptionsFunc func(*middleware.Stack) error) aws.Config {
+	awsCfg, err := awsConfig.LoadDefaultConfig(
+		context.TODO(),
+		awsConfig.WithRegion("us-west-1"),
+		awsConfig.WithAPIOptions([]func(*middleware.Stack) error{withAPIOptionsFunc}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return awsCfg
+}
+
+// helperLambda returns a Lambda of the test-dev project whose services all use
+// the mocked AWS API of withAPIOptionsFunc
+func helperLambda(t *testing.T, withAPIOptionsFunc func(*middleware.Stack) error) *Lambda {
+	awsCfg := mockAwsConfig(t, withAPIOptionsFunc)
+	cfg := &config.Config{Name: "test", Stage: "dev", Bucket: "jerm-bucket"}
+	cfg.Platform = config.Platform{
+		Name:    config.Lambda,
+		Runtime: "python3.11",
+		Memory:  1024,
+		Timeout: 60,
+		Role:    "arn:aws:iam::123456789012:role/test",
+	}
+	l := &Lambda{
+		config:            cfg,
+		description:       "Jerm Deployment",
+		functionHandler:   "handler.handler",
+		maxWaiterDuration: DefaultWaitDuration,
+		canaryWindow:      10 * time.Millisecond,
+		canaryInterval:    time.Millisecond,
+		client:            lambda.NewFromConfig(awsCfg),
+		monitor:           NewCloudWatch(cfg, awsCfg),
+		storage:           NewS3(cfg, awsCfg),
+		access:            NewIAM(cfg, awsCfg),
+		apigateway:        NewApiGateway(cfg, awsCfg),
+		httpApi:           NewHttpApi(cfg, awsCfg),
+		webSocket:         NewWebSocketApi(cfg, awsCfg),
+		domains:           NewDomains(cfg, awsCfg),
+		registry:          NewECR(cfg, awsCfg),
+		secrets:           NewSecrets(cfg, awsCfg),
+		vpc:               NewVPC(cfg, awsCfg),
+		events:            NewEventBridge(cfg, awsCfg),
+		eventSources:      NewEventSources(cfg, awsCfg),
+		triggers:          NewTriggers(cfg, awsCfg),
+		metrics:           NewMetrics(cfg, awsCfg),
+		inventory:         NewInventory(cfg, awsCfg),
+	}
+	l.domains.pollInterval = 0
+	return l
+}
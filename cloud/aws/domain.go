@@ -0,0 +1,448 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/acm"
+	acmTypes "github.com/aws/aws-sdk-go-v2/service/acm/types"
+	"github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
+	agv2Types "github.com/aws/aws-sdk-go-v2/service/apigatewayv2/types"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	r53Types "github.com/aws/aws-sdk-go-v2/service/route53/types"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/spatocode/jerm/internal/log"
+)
+
+const (
+	// DefaultCertificateWait is how long a requested certificate is waited for to be validated
+	DefaultCertificateWait = 30 * time.Minute
+
+	// validationRecordAttempts is how many times a requested certificate is checked for its validation record
+	validationRecordAttempts = 10
+)
+
+// Domains is the AWS custom domain operations of ACM, API Gateway and Route 53
+type Domains struct {
+	config          *config.Config
+	region          string
+	certificateWait time.Duration
+	pollInterval    time.Duration
+	acm             *acm.Client
+	route53         *route53.Client
+	client          *apigatewayv2.Client
+}
+
+// NewDomains creates a new AWS Domains object
+func NewDomains(cfg *config.Config, awsConfig aws.Config) *Domains {
+	return &Domains{
+		config:          cfg,
+		region:          awsConfig.Region,
+		certificateWait: DefaultCertificateWait,
+		pollInterval:    5 * time.Second,
+		acm:             acm.NewFromConfig(awsConfig),
+		route53:         route53.NewFromConfig(awsConfig),
+		client:          apigatewayv2.NewFromConfig(awsConfig),
+	}
+}
+
+// certify serves a stage of an API at the custom domain of the project and returns
+// its URL. The certificate, the API Gateway domain name, the API mapping and the
+// Route 53 alias record are created when they don't exist.
+func (d *Domains) certify(apiId, stage string) (string, error) {
+	domain, err := d.config.GetDomain()
+	if err != nil {
+		return "", err
+	}
+
+	zoneId, err := d.hostedZone(domain)
+	if err != nil {
+		return "", err
+	}
+	if zoneId == "" {
+		msg := fmt.Sprintf("can't find a hosted zone of domain %s. Set hosted_zone of the domain in your jerm.json file", domain.Name)
+		return "", errors.New(msg)
+	}
+
+	certificateArn, err := d.ensureCertificate(domain, zoneId)
+	if err != nil {
+		return "", err
+	}
+
+	target, err := d.ensureDomainName(domain, certificateArn)
+	if err != nil {
+		return "", err
+	}
+
+	err = d.ensureApiMapping(domain, apiId, stage)
+	if err != nil {
+		return "", err
+	}
+
+	err = d.changeRecord(zoneId, r53Types.ChangeActionUpsert, &r53Types.ResourceRecordSet{
+		Name: aws.String(domain.Name),
+		Type: r53Types.RRTypeA,
+		AliasTarget: &r53Types.AliasTarget{
+			DNSName:      target.ApiGatewayDomainName,
+			HostedZoneId: target.HostedZoneId,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("https://%s/%s", domain.Name, domain.BasePath), nil
+}
+
+// hostedZone returns the ID of the public hosted zone of the domain. It's
+// the zone of the longest parent domain and is empty when none is found.
+func (d *Domains) hostedZone(domain config.Domain) (string, error) {
+	if domain.HostedZone != "" {
+		return domain.HostedZone, nil
+	}
+
+	labels := strings.Split(domain.Name, ".")
+	for i := 0; i < len(labels)-1; i++ {
+		name := strings.Join(labels[i:], ".") + "."
+		log.Debug(fmt.Sprintf("looking up hosted zone %s...", name))
+		resp, err := d.route53.ListHostedZonesByName(context.TODO(), &route53.ListHostedZonesByNameInput{
+			DNSName:  aws.String(name),
+			MaxItems: aws.Int32(1),
+		})
+		if err != nil {
+			return "", err
+		}
+		for _, zone := range resp.HostedZones {
+			if aws.ToString(zone.Name) == name && (zone.Config == nil || !zone.Config.PrivateZone) {
+				return strings.TrimPrefix(aws.ToString(zone.Id), "/hostedzone/"), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// ensureCertificate returns the certificate of the domain. Without a configured
+// certificate, the certificate jerm requested for the domain is used or a new one
+// is requested and validated through a DNS record in the hosted zone.
+func (d *Domains) ensureCertificate(domain config.Domain, zoneId string) (string, error) {
+	if domain.Certificate != "" {
+		return domain.Certificate, nil
+	}
+
+	certificateArn, err := d.findCertificate(domain.Name)
+	if err != nil {
+		return "", err
+	}
+
+	if certificateArn == "" {
+		tags, err := resourceTags(d.config)
+		if err != nil {
+			return "", err
+		}
+		var certificateTags []acmTypes.Tag
+		for _, key := range sortedTagKeys(tags) {
+			certificateTags = append(certificateTags, acmTypes.Tag{Key: aws.String(key), Value: aws.String(tags[key])})
+		}
+
+		log.PrintfInfo("Requesting certificate for %s...\n", domain.Name)
+		resp, err := d.acm.RequestCertificate(context.TODO(), &acm.RequestCertificateInput{
+			DomainName:       aws.String(domain.Name),
+			ValidationMethod: acmTypes.ValidationMethodDns,
+			Tags:             certificateTags,
+		})
+		if err != nil {
+			return "", err
+		}
+		certificateArn = aws.ToString(resp.CertificateArn)
+	}
+
+	err = d.validateCertificate(certificateArn, zoneId)
+	if err != nil {
+		return "", err
+	}
+	return certificateArn, nil
+}
+
+// findCertificate returns the ARN of an issued or pending certificate of the domain
+func (d *Domains) findCertificate(name string) (string, error) {
+	log.Debug(fmt.Sprintf("finding certificate of %s...", name))
+	paginator := acm.NewListCertificatesPaginator(d.acm, &acm.ListCertificatesInput{
+		CertificateStatuses: []acmTypes.CertificateStatus{
+			acmTypes.CertificateStatusIssued,
+			acmTypes.CertificateStatusPendingValidation,
+		},
+	})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return "", err
+		}
+		for _, certificate := range resp.CertificateSummaryList {
+			if aws.ToString(certificate.DomainName) == name {
+				return aws.ToString(certificate.CertificateArn), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// validateCertificate creates the DNS validation record of a pending certificate
+// and waits for the certificate to be issued
+func (d *Domains) validateCertificate(certificateArn, zoneId string) error {
+	// ACM adds the validation record to a certificate shortly after it's requested
+	for attempt := 1; ; attempt++ {
+		resp, err := d.acm.DescribeCertificate(context.TODO(), &acm.DescribeCertificateInput{
+			CertificateArn: aws.String(certificateArn),
+		})
+		if err != nil {
+			return err
+		}
+		if resp.Certificate.Status == acmTypes.CertificateStatusIssued {
+			return nil
+		}
+
+		var record *acmTypes.ResourceRecord
+		for _, validation := range resp.Certificate.DomainValidationOptions {
+			if validation.ResourceRecord != nil {
+				record = validation.ResourceRecord
+			}
+		}
+		if record != nil {
+			err = d.changeRecord(zoneId, r53Types.ChangeActionUpsert, &r53Types.ResourceRecordSet{
+				Name:            record.Name,
+				Type:            r53Types.RRType(record.Type),
+				TTL:             aws.Int64(300),
+				ResourceRecords: []r53Types.ResourceRecord{{Value: record.Value}},
+			})
+			if err != nil {
+				return err
+			}
+			break
+		}
+
+		if attempt == validationRecordAttempts {
+			msg := fmt.Sprintf("can't find the DNS validation record of certificate %s", certificateArn)
+			return errors.New(msg)
+		}
+		time.Sleep(d.pollInterval)
+	}
+
+	log.PrintInfo("Waiting for certificate validation...")
+	waiter := acm.NewCertificateValidatedWaiter(d.acm)
+	return waiter.Wait(context.TODO(), &acm.DescribeCertificateInput{
+		CertificateArn: aws.String(certificateArn),
+	}, d.certificateWait)
+}
+
+// ensureDomainName creates the regional API Gateway domain name if it doesn't
+// exist and returns its configuration
+func (d *Domains) ensureDomainName(domain config.Domain, certificateArn string) (agv2Types.DomainNameConfiguration, error) {
+	resp, err := d.client.GetDomainName(context.TODO(), &apigatewayv2.GetDomainNameInput{
+		DomainName: aws.String(domain.Name),
+	})
+	if err == nil && len(resp.DomainNameConfigurations) > 0 {
+		return resp.DomainNameConfigurations[0], nil
+	}
+	var nfErr *agv2Types.NotFoundException
+	if err != nil && !errors.As(err, &nfErr) {
+		return agv2Types.DomainNameConfiguration{}, err
+	}
+
+	tags, err := resourceTags(d.config)
+	if err != nil {
+		return agv2Types.DomainNameConfiguration{}, err
+	}
+
+	log.Debug(fmt.Sprintf("creating API Gateway domain name %s...", domain.Name))
+	created, err := d.client.CreateDomainName(context.TODO(), &apigatewayv2.CreateDomainNameInput{
+		DomainName: aws.String(domain.Name),
+		DomainNameConfigurations: []agv2Types.DomainNameConfiguration{
+			{
+				CertificateArn: aws.String(certificateArn),
+				EndpointType:   agv2Types.EndpointTypeRegional,
+				SecurityPolicy: agv2Types.SecurityPolicyTls12,
+			},
+		},
+		Tags: tags,
+	})
+	if err != nil {
+		return agv2Types.DomainNameConfiguration{}, err
+	}
+	if len(created.DomainNameConfigurations) == 0 {
+		msg := fmt.Sprintf("can't find the endpoint of domain name %s", domain.Name)
+		return agv2Types.DomainNameConfiguration{}, errors.New(msg)
+	}
+	return created.DomainNameConfigurations[0], nil
+}
+
+// ensureApiMapping maps the base path of the domain to a stage of an API
+func (d *Domains) ensureApiMapping(domain config.Domain, apiId, stage string) error {
+	mapping, err := d.getApiMapping(domain)
+	if err != nil {
+		return err
+	}
+
+	if mapping == nil {
+		log.Debug(fmt.Sprintf("mapping %s/%s to API %s...", domain.Name, domain.BasePath, apiId))
+		input := &apigatewayv2.CreateApiMappingInput{
+			ApiId:      aws.String(apiId),
+			DomainName: aws.String(domain.Name),
+			Stage:      aws.String(stage),
+		}
+		if domain.BasePath != "" {
+			input.ApiMappingKey = aws.String(domain.BasePath)
+		}
+		_, err = d.client.CreateApiMapping(context.TODO(), input)
+		return err
+	}
+
+	if aws.ToString(mapping.ApiId) == apiId && aws.ToString(mapping.Stage) == stage {
+		return nil
+	}
+
+	log.Debug(fmt.Sprintf("remapping %s/%s to API %s...", domain.Name, domain.BasePath, apiId))
+	_, err = d.client.UpdateApiMapping(context.TODO(), &apigatewayv2.UpdateApiMappingInput{
+		ApiMappingId: mapping.ApiMappingId,
+		DomainName:   aws.String(domain.Name),
+		ApiId:        aws.String(apiId),
+		Stage:        aws.String(stage),
+	})
+	return err
+}
+
+// getApiMapping returns the API mapping of the base path of the domain. It's
+// nil when the domain name or the mapping doesn't exist.
+func (d *Domains) getApiMapping(domain config.Domain) (*agv2Types.ApiMapping, error) {
+	mappings, err := d.getApiMappings(domain.Name)
+	if err != nil {
+		return nil, err
+	}
+	for _, mapping := range mappings {
+		if aws.ToString(mapping.ApiMappingKey) == domain.BasePath {
+			return &mapping, nil
+		}
+	}
+	return nil, nil
+}
+
+// getApiMappings lists the API mappings of a domain name. It's empty when the domain name doesn't exist.
+func (d *Domains) getApiMappings(name string) ([]agv2Types.ApiMapping, error) {
+	var mappings []agv2Types.ApiMapping
+	input := &apigatewayv2.GetApiMappingsInput{DomainName: aws.String(name)}
+	for {
+		resp, err := d.client.GetApiMappings(context.TODO(), input)
+		if err != nil {
+			var nfErr *agv2Types.NotFoundException
+			if errors.As(err, &nfErr) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		mappings = append(mappings, resp.Items...)
+		if resp.NextToken == nil {
+			return mappings, nil
+		}
+		input.NextToken = resp.NextToken
+	}
+}
+
+// deleteApiMapping deletes the API mapping of the base path of the domain. The
+// domain name is deleted with its last mapping; its certificate is kept.
+func (d *Domains) deleteApiMapping(domain config.Domain, mapping agv2Types.ApiMapping) error {
+	_, err := d.client.DeleteApiMapping(context.TODO(), &apigatewayv2.DeleteApiMappingInput{
+		ApiMappingId: mapping.ApiMappingId,
+		DomainName:   aws.String(domain.Name),
+	})
+	if err != nil {
+		return err
+	}
+
+	mappings, err := d.getApiMappings(domain.Name)
+	if err != nil || len(mappings) > 0 {
+		return err
+	}
+
+	log.Debug(fmt.Sprintf("deleting API Gateway domain name %s...", domain.Name))
+	_, err = d.client.DeleteDomainName(context.TODO(), &apigatewayv2.DeleteDomainNameInput{
+		DomainName: aws.String(domain.Name),
+	})
+	return err
+}
+
+// getRecord returns the Route 53 alias record of the domain pointing to an API
+// Gateway domain name. It's nil when the record doesn't exist.
+func (d *Domains) getRecord(zoneId, name string) (*r53Types.ResourceRecordSet, error) {
+	resp, err := d.route53.ListResourceRecordSets(context.TODO(), &route53.ListResourceRecordSetsInput{
+		HostedZoneId:    aws.String(zoneId),
+		StartRecordName: aws.String(name),
+		StartRecordType: r53Types.RRTypeA,
+		MaxItems:        aws.Int32(1),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range resp.ResourceRecordSets {
+		if strings.TrimSuffix(aws.ToString(record.Name), ".") != name || record.Type != r53Types.RRTypeA || record.AliasTarget == nil {
+			continue
+		}
+		target := strings.TrimSuffix(aws.ToString(record.AliasTarget.DNSName), ".")
+		if strings.HasSuffix(target, fmt.Sprintf(".execute-api.%s.amazonaws.com", d.region)) {
+			return &record, nil
+		}
+	}
+	return nil, nil
+}
+
+// changeRecord changes a record of a hosted zone
+func (d *Domains) changeRecord(zoneId string, action r53Types.ChangeAction, record *r53Types.ResourceRecordSet) error {
+	log.Debug(fmt.Sprintf("changing route53 record %s (%s)...", aws.ToString(record.Name), action))
+	_, err := d.route53.ChangeResourceRecordSets(context.TODO(), &route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: aws.String(zoneId),
+		ChangeBatch: &r53Types.ChangeBatch{
+			Changes: []r53Types.Change{{Action: action, ResourceRecordSet: record}},
+		},
+	})
+	return err
+}
+
+// teardownSteps lists the Route 53 record and the API mapping of the domain.
+// Certificates may be shared with other domains, so they're kept.
+func (d *Domains) teardownSteps() ([]teardownStep, error) {
+	domain, err := d.config.GetDomain()
+	if err != nil {
+		return nil, err
+	}
+
+	var steps []teardownStep
+	zoneId, err := d.hostedZone(domain)
+	if err != nil {
+		return nil, err
+	}
+	if zoneId != "" {
+		record, err := d.getRecord(zoneId, domain.Name)
+		if err != nil {
+			return nil, err
+		}
+		if record != nil {
+			steps = append(steps, teardownStep{fmt.Sprintf("route53 record %s", domain.Name), func() error {
+				return d.changeRecord(zoneId, r53Types.ChangeActionDelete, record)
+			}})
+		}
+	}
+
+	mapping, err := d.getApiMapping(domain)
+	if err != nil {
+		return nil, err
+	}
+	if mapping != nil {
+		steps = append(steps, teardownStep{fmt.Sprintf("api mapping %s/%s", domain.Name, domain.BasePath), func() error {
+			return d.deleteApiMapping(domain, *mapping)
+		}})
+	}
+	return steps, nil
+}
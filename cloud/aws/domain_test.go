@@ -0,0 +1,202 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/acm"
+	acmTypes "github.com/aws/aws-sdk-go-v2/service/acm/types"
+	"github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
+	agv2Types "github.com/aws/aws-sdk-go-v2/service/apigatewayv2/types"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	r53Types "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/spatocode/jerm/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDomainsCertify(t *testing.T) {
+	assert := assert.New(t)
+	describes := 0
+
+	cases := []struct {
+		name       string
+		domain     *config.Domain
+		mock       func(operation string, input interface{}) (interface{}, error)
+		url        string
+		operations []string
+		check      func(inputs []interface{})
+	}{
+		{
+			name:   "certify new domain",
+			domain: &config.Domain{Name: "api.example.com"},
+			mock: func(operation string, input interface{}) (interface{}, error) {
+				switch operation {
+				case "ListHostedZonesByName":
+					if *input.(*route53.ListHostedZonesByNameInput).DNSName == "example.com." {
+						return &route53.ListHostedZonesByNameOutput{HostedZones: []r53Types.HostedZone{
+							{Id: aws.String("/hostedzone/Z123"), Name: aws.String("example.com.")},
+						}}, nil
+					}
+					return &route53.ListHostedZonesByNameOutput{HostedZones: []r53Types.HostedZone{
+						{Id: aws.String("/hostedzone/Z999"), Name: aws.String("example.org.")},
+					}}, nil
+				case "ListCertificates":
+					return &acm.ListCertificatesOutput{}, nil
+				case "RequestCertificate":
+					return &acm.RequestCertificateOutput{CertificateArn: aws.String("arn:aws:acm:us-west-1:123456789012:certificate/1")}, nil
+				case "DescribeCertificate":
+					describes++
+					if describes > 1 {
+						return &acm.DescribeCertificateOutput{Certificate: &acmTypes.CertificateDetail{
+							Status:                  acmTypes.CertificateStatusIssued,
+							DomainValidationOptions: []acmTypes.DomainValidation{{ValidationStatus: acmTypes.DomainStatusSuccess}},
+						}}, nil
+					}
+					return &acm.DescribeCertificateOutput{Certificate: &acmTypes.CertificateDetail{
+						Status: acmTypes.CertificateStatusPendingValidation,
+						DomainValidationOptions: []acmTypes.DomainValidation{{
+							DomainName:     aws.String("api.example.com"),
+							ResourceRecord: &acmTypes.ResourceRecord{Name: aws.String("_x.api.example.com."), Type: acmTypes.RecordTypeCname, Value: aws.String("_y.acm-validations.aws.")},
+						}},
+					}}, nil
+				case "ChangeResourceRecordSets":
+					return &route53.ChangeResourceRecordSetsOutput{}, nil
+				case "GetDomainName":
+					return nil, &agv2Types.NotFoundException{}
+				case "CreateDomainName":
+					return &apigatewayv2.CreateDomainNameOutput{DomainNameConfigurations: []agv2Types.DomainNameConfiguration{{
+						ApiGatewayDomainName: aws.String("d-abc.execute-api.us-west-1.amazonaws.com"),
+						HostedZoneId:         aws.String("Z2OJLYMUO9EFXC"),
+					}}}, nil
+				case "GetApiMappings":
+					return &apigatewayv2.GetApiMappingsOutput{}, nil
+				case "CreateApiMapping":
+					return &apigatewayv2.CreateApiMappingOutput{}, nil
+				}
+				return nil, errUnexpectedOperation
+			},
+			url: "https://api.example.com/",
+			operations: []string{
+				"ListHostedZonesByName", "ListHostedZonesByName",
+				"ListCertificates", "RequestCertificate",
+				"DescribeCertificate", "ChangeResourceRecordSets", "DescribeCertificate",
+				"GetDomainName", "CreateDomainName",
+				"GetApiMappings", "CreateApiMapping",
+				"ChangeResourceRecordSets",
+			},
+			check: func(inputs []interface{}) {
+				requested := inputs[3].(*acm.RequestCertificateInput)
+				assert.Equal(acmTypes.ValidationMethodDns, requested.ValidationMethod)
+				validation := inputs[5].(*route53.ChangeResourceRecordSetsInput)
+				assert.Equal("Z123", *validation.HostedZoneId)
+				assert.Equal(r53Types.RRTypeCname, validation.ChangeBatch.Changes[0].ResourceRecordSet.Type)
+				created := inputs[8].(*apigatewayv2.CreateDomainNameInput)
+				assert.Equal("arn:aws:acm:us-west-1:123456789012:certificate/1", *created.DomainNameConfigurations[0].CertificateArn)
+				assert.Equal(agv2Types.EndpointTypeRegional, created.DomainNameConfigurations[0].EndpointType)
+				mapping := inputs[10].(*apigatewayv2.CreateApiMappingInput)
+				assert.Equal("api1", *mapping.ApiId)
+				assert.Equal("dev", *mapping.Stage)
+				assert.Nil(mapping.ApiMappingKey)
+				record := inputs[11].(*route53.ChangeResourceRecordSetsInput).ChangeBatch.Changes[0]
+				assert.Equal(r53Types.ChangeActionUpsert, record.Action)
+				assert.Equal(r53Types.RRTypeA, record.ResourceRecordSet.Type)
+				assert.Equal("d-abc.execute-api.us-west-1.amazonaws.com", *record.ResourceRecordSet.AliasTarget.DNSName)
+			},
+		},
+		{
+			name: "certify existing domain",
+			domain: &config.Domain{
+				Name:        "api.example.com",
+				Certificate: "arn:aws:acm:us-west-1:123456789012:certificate/shared",
+				HostedZone:  "Z123",
+				BasePath:    "v1",
+			},
+			mock: func(operation string, input interface{}) (interface{}, error) {
+				switch operation {
+				case "GetDomainName":
+					return &apigatewayv2.GetDomainNameOutput{DomainNameConfigurations: []agv2Types.DomainNameConfiguration{{
+						ApiGatewayDomainName: aws.String("d-abc.execute-api.us-west-1.amazonaws.com"),
+						HostedZoneId:         aws.String("Z2OJLYMUO9EFXC"),
+					}}}, nil
+				case "GetApiMappings":
+					return &apigatewayv2.GetApiMappingsOutput{Items: []agv2Types.ApiMapping{
+						{ApiMappingId: aws.String("m1"), ApiMappingKey: aws.String("v1"), ApiId: aws.String("old"), Stage: aws.String("dev")},
+						{ApiMappingId: aws.String("m2"), ApiMappingKey: aws.String("v2"), ApiId: aws.String("other"), Stage: aws.String("dev")},
+					}}, nil
+				case "UpdateApiMapping":
+					return &apigatewayv2.UpdateApiMappingOutput{}, nil
+				case "ChangeResourceRecordSets":
+					return &route53.ChangeResourceRecordSetsOutput{}, nil
+				}
+				return nil, errUnexpectedOperation
+			},
+			url:        "https://api.example.com/v1",
+			operations: []string{"GetDomainName", "GetApiMappings", "UpdateApiMapping", "ChangeResourceRecordSets"},
+			check: func(inputs []interface{}) {
+				updated := inputs[2].(*apigatewayv2.UpdateApiMappingInput)
+				assert.Equal("m1", *updated.ApiMappingId)
+				assert.Equal("api1", *updated.ApiId)
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			withAPIOptionsFunc, recorder := mockApi(tt.mock)
+			l := helperLambda(t, withAPIOptionsFunc)
+			l.config.Domain = tt.domain
+
+			url, err := l.domains.certify("api1", "dev")
+			assert.Nil(err)
+			assert.Equal(tt.url, url)
+			assert.Equal(tt.operations, recorder.operations)
+			tt.check(recorder.inputs)
+		})
+	}
+}
+
+func TestDomainsTeardownSteps(t *testing.T) {
+	assert := assert.New(t)
+	deleted := false
+	withAPIOptionsFunc, recorder := mockApi(func(operation string, input interface{}) (interface{}, error) {
+		switch operation {
+		case "ListResourceRecordSets":
+			return &route53.ListResourceRecordSetsOutput{ResourceRecordSets: []r53Types.ResourceRecordSet{{
+				Name:        aws.String("api.example.com."),
+				Type:        r53Types.RRTypeA,
+				AliasTarget: &r53Types.AliasTarget{DNSName: aws.String("d-abc.execute-api.us-west-1.amazonaws.com."), HostedZoneId: aws.String("Z2OJLYMUO9EFXC")},
+			}}}, nil
+		case "GetApiMappings":
+			if deleted {
+				return &apigatewayv2.GetApiMappingsOutput{}, nil
+			}
+			return &apigatewayv2.GetApiMappingsOutput{Items: []agv2Types.ApiMapping{
+				{ApiMappingId: aws.String("m1"), ApiId: aws.String("api1"), Stage: aws.String("dev")},
+			}}, nil
+		case "ChangeResourceRecordSets":
+			return &route53.ChangeResourceRecordSetsOutput{}, nil
+		case "DeleteApiMapping":
+			deleted = true
+			return &apigatewayv2.DeleteApiMappingOutput{}, nil
+		case "DeleteDomainName":
+			return &apigatewayv2.DeleteDomainNameOutput{}, nil
+		}
+		return nil, errUnexpectedOperation
+	})
+	l := helperLambda(t, withAPIOptionsFunc)
+	l.config.Domain = &config.Domain{Name: "api.example.com", HostedZone: "Z123"}
+
+	steps, err := l.domains.teardownSteps()
+	assert.Nil(err)
+	assert.Len(steps, 2)
+	assert.Equal("route53 record api.example.com", steps[0].resource)
+	assert.Equal("api mapping api.example.com/", steps[1].resource)
+
+	recorder.operations = nil
+	for _, step := range steps {
+		assert.Nil(step.delete())
+	}
+	assert.Equal([]string{"ChangeResourceRecordSets", "DeleteApiMapping", "GetApiMappings", "DeleteDomainName"}, recorder.operations)
+	record := recorder.inputs[2].(*route53.ChangeResourceRecordSetsInput).ChangeBatch.Changes[0]
+	assert.Equal(r53Types.ChangeActionDelete, record.Action)
+}
@@ -0,0 +1,181 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	ecrTypes "github.com/aws/aws-sdk-go-v2/service/ecr/types"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/spatocode/jerm/internal/log"
+	"github.com/spatocode/jerm/internal/utils"
+)
+
+// ECR is the AWS Elastic Container Registry operations
+type ECR struct {
+	config    *config.Config
+	awsConfig aws.Config
+	command   utils.ShellCommand
+	client    *ecr.Client
+}
+
+// NewECR creates a new AWS ECR object
+func NewECR(config *config.Config, awsConfig aws.Config) *ECR {
+	return &ECR{
+		config:    config,
+		awsConfig: awsConfig,
+		command:   utils.Command(),
+		client:    ecr.NewFromConfig(awsConfig),
+	}
+}
+
+// ensureRepository creates the function image repository if it doesn't exist
+// and returns the repository URI
+func (e *ECR) ensureRepository() (string, error) {
+	name := e.config.GetFunctionName()
+	log.Debug(fmt.Sprintf("describing ecr repository %s...", name))
+	out, err := e.client.DescribeRepositories(context.TODO(), &ecr.DescribeRepositoriesInput{
+		RepositoryNames: []string{name},
+	})
+	if err == nil && len(out.Repositories) > 0 {
+		return *out.Repositories[0].RepositoryUri, nil
+	}
+
+	var rnfErr *ecrTypes.RepositoryNotFoundException
+	if err != nil && !errors.As(err, &rnfErr) {
+		return "", err
+	}
+
+	tags, err := resourceTags(e.config)
+	if err != nil {
+		return "", err
+	}
+	var repositoryTags []ecrTypes.Tag
+	for _, key := range sortedTagKeys(tags) {
+		repositoryTags = append(repositoryTags, ecrTypes.Tag{Key: aws.String(key), Value: aws.String(tags[key])})
+	}
+
+	log.Debug(fmt.Sprintf("creating ecr repository %s...", name))
+	resp, err := e.client.CreateRepository(context.TODO(), &ecr.CreateRepositoryInput{
+		RepositoryName: aws.String(name),
+		ImageScanningConfiguration: &ecrTypes.ImageScanningConfiguration{
+			ScanOnPush: true,
+		},
+		Tags: repositoryTags,
+	})
+	if err != nil {
+		return "", err
+	}
+	return *resp.Repository.RepositoryUri, nil
+}
+
+// repositoryExists checks whether the function image repository exists
+func (e *ECR) repositoryExists() (bool, error) {
+	name := e.config.GetFunctionName()
+	log.Debug(fmt.Sprintf("describing ecr repository %s...", name))
+	_, err := e.client.DescribeRepositories(context.TODO(), &ecr.DescribeRepositoriesInput{
+		RepositoryNames: []string{name},
+	})
+	if err != nil {
+		var rnfErr *ecrTypes.RepositoryNotFoundException
+		if errors.As(err, &rnfErr) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// deleteRepository deletes the function image repository with its images
+func (e *ECR) deleteRepository() error {
+	name := e.config.GetFunctionName()
+	log.Debug(fmt.Sprintf("deleting ecr repository %s...", name))
+	_, err := e.client.DeleteRepository(context.TODO(), &ecr.DeleteRepositoryInput{
+		RepositoryName: aws.String(name),
+		Force:          true,
+	})
+	return err
+}
+
+// dockerConfig writes a docker config authorized to push to the registry
+// into a temporary directory and returns the directory
+func (e *ECR) dockerConfig() (string, error) {
+	log.Debug("getting ecr authorization token...")
+	out, err := e.client.GetAuthorizationToken(context.TODO(), &ecr.GetAuthorizationTokenInput{})
+	if err != nil {
+		return "", err
+	}
+	if len(out.AuthorizationData) == 0 {
+		return "", errors.New("cannot get an ecr authorization token")
+	}
+
+	auth := out.AuthorizationData[0]
+	registry := strings.TrimPrefix(*auth.ProxyEndpoint, "https://")
+	content, err := json.Marshal(map[string]interface{}{
+		"auths": map[string]interface{}{
+			registry: map[string]string{"auth": *auth.AuthorizationToken},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := os.MkdirTemp(os.TempDir(), "jerm-docker")
+	if err != nil {
+		return "", err
+	}
+	err = os.WriteFile(filepath.Join(dir, "config.json"), content, 0600)
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return dir, nil
+}
+
+// pushImage builds an image from the package archive and pushes it to the
+// function repository. It returns the pushed image URI.
+func (e *ECR) pushImage(zipPath string) (string, error) {
+	repository, err := e.ensureRepository()
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := os.MkdirTemp(os.TempDir(), "jerm-image")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+
+	err = utils.Unzip(zipPath, dir)
+	if err != nil {
+		return "", err
+	}
+
+	image := fmt.Sprintf("%s:%d", repository, time.Now().UnixNano())
+	log.Debug(fmt.Sprintf("building image %s...", image))
+	_, err = e.command.RunCommand("docker", "build", "--platform", "linux/amd64", "--provenance=false", "-t", image, dir)
+	if err != nil {
+		return "", err
+	}
+
+	dockerConfig, err := e.dockerConfig()
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dockerConfig)
+
+	log.Debug(fmt.Sprintf("pushing image %s...", image))
+	_, err = e.command.RunCommand("docker", "--config", dockerConfig, "push", image)
+	if err != nil {
+		return "", err
+	}
+	return image, nil
+}
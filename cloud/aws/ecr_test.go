@@ -0,0 +1,133 @@
+package aws
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	ecrTypes "github.com/aws/aws-sdk-go-v2/service/ecr/types"
+	"github.com/spatocode/jerm/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeCommandExecutor records the commands and the docker config used to push
+type fakeCommandExecutor struct {
+	commands     []string
+	dockerConfig string
+}
+
+func (c *fakeCommandExecutor) RunCommand(command string, args ...string) (string, error) {
+	c.commands = append(c.commands, fmt.Sprintf("%s %s", command, strings.Join(args, " ")))
+	if len(args) > 1 && args[0] == "--config" {
+		b, err := os.ReadFile(filepath.Join(args[1], "config.json"))
+		if err != nil {
+			return "", err
+		}
+		c.dockerConfig = string(b)
+	}
+	return "", nil
+}
+
+func (c *fakeCommandExecutor) RunCommandWithEnv(env []string, command string, args ...string) (string, error) {
+	return c.RunCommand(command, args...)
+}
+
+func TestNewECR(t *testing.T) {
+	assert := assert.New(t)
+	cfg := &config.Config{}
+	awsC := aws.Config{}
+	e := NewECR(cfg, awsC)
+	assert.Equal(cfg, e.config)
+	assert.Equal(awsC, e.awsConfig)
+	assert.NotNil(e.client)
+	assert.NotNil(e.command)
+}
+
+func TestECREnsureRepository(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		name             string
+		repositoryExists bool
+		createErr        error
+		want             error
+		wantErr          bool
+	}{
+		{name: "existing repository", repositoryExists: true},
+		{name: "create repository"},
+		{
+			name:      "create repository error",
+			createErr: fmt.Errorf("CreateRepositoryError"),
+			want:      fmt.Errorf("operation error ECR: CreateRepository, CreateRepositoryError"),
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			withAPIOptionsFunc, _ := mockApi(func(operation string, input interface{}) (interface{}, error) {
+				repository := &ecrTypes.Repository{RepositoryUri: aws.String("123456789012.dkr.ecr.us-west-1.amazonaws.com/test-dev")}
+				switch operation {
+				case "DescribeRepositories":
+					if !tt.repositoryExists {
+						return nil, &ecrTypes.RepositoryNotFoundException{}
+					}
+					return &ecr.DescribeRepositoriesOutput{Repositories: []ecrTypes.Repository{*repository}}, nil
+				case "CreateRepository":
+					if tt.createErr != nil {
+						return nil, tt.createErr
+					}
+					return &ecr.CreateRepositoryOutput{Repository: repository}, nil
+				}
+				return nil, errUnexpectedOperation
+			})
+			l := helperLambda(t, withAPIOptionsFunc)
+
+			uri, err := l.registry.ensureRepository()
+			if tt.wantErr {
+				assert.EqualError(err, tt.want.Error())
+				return
+			}
+			assert.Nil(err)
+			assert.Equal("123456789012.dkr.ecr.us-west-1.amazonaws.com/test-dev", uri)
+		})
+	}
+}
+
+func TestECRPushImage(t *testing.T) {
+	assert := assert.New(t)
+	archive := filepath.Join(t.TempDir(), "jerm.zip")
+	err := os.WriteFile(archive, []byte("PK\x05\x06"+strings.Repeat("\x00", 18)), 0644)
+	assert.Nil(err)
+
+	withAPIOptionsFunc, _ := mockApi(func(operation string, input interface{}) (interface{}, error) {
+		switch operation {
+		case "DescribeRepositories":
+			return &ecr.DescribeRepositoriesOutput{Repositories: []ecrTypes.Repository{
+				{RepositoryUri: aws.String("123456789012.dkr.ecr.us-west-1.amazonaws.com/test-dev")},
+			}}, nil
+		case "GetAuthorizationToken":
+			return &ecr.GetAuthorizationTokenOutput{AuthorizationData: []ecrTypes.AuthorizationData{{
+				AuthorizationToken: aws.String(base64.StdEncoding.EncodeToString([]byte("AWS:password"))),
+				ProxyEndpoint:      aws.String("https://123456789012.dkr.ecr.us-west-1.amazonaws.com"),
+			}}}, nil
+		}
+		return nil, errUnexpectedOperation
+	})
+	l := helperLambda(t, withAPIOptionsFunc)
+	executor := &fakeCommandExecutor{}
+	l.registry.command = executor
+	image, err := l.registry.pushImage(archive)
+	assert.Nil(err)
+	assert.True(strings.HasPrefix(image, "123456789012.dkr.ecr.us-west-1.amazonaws.com/test-dev:"))
+	assert.Len(executor.commands, 2)
+	assert.True(strings.HasPrefix(executor.commands[0], "docker build --platform linux/amd64 --provenance=false -t "+image))
+	assert.True(strings.HasPrefix(executor.commands[1], "docker --config "))
+	assert.True(strings.HasSuffix(executor.commands[1], "push "+image))
+	assert.Contains(executor.dockerConfig, `"123456789012.dkr.ecr.us-west-1.amazonaws.com":{"auth":"QVdTOnBhc3N3b3Jk"}`)
+}
@@ -0,0 +1,186 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	eventbridgeTypes "github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/spatocode/jerm/internal/log"
+)
+
+const eventTargetId = "jerm"
+
+// EventBridge is the AWS EventBridge operations
+type EventBridge struct {
+	config *config.Config
+	client *eventbridge.Client
+	lambda *lambda.Client
+}
+
+// NewEventBridge creates a new AWS EventBridge object
+func NewEventBridge(cfg *config.Config, awsConfig aws.Config) *EventBridge {
+	return &EventBridge{
+		config: cfg,
+		client: eventbridge.NewFromConfig(awsConfig),
+		lambda: lambda.NewFromConfig(awsConfig),
+	}
+}
+
+// schedule reconciles the scheduled event rules of the function with the config.
+// Rules of events removed from the config are deleted.
+func (e *EventBridge) schedule(functionArn string) error {
+	events, err := e.config.GetEvents()
+	if err != nil {
+		return err
+	}
+
+	rules := map[string]bool{}
+	for _, event := range events {
+		name := e.ruleName(event.Name)
+		rules[name] = true
+		err = e.putRule(name, event, functionArn)
+		if err != nil {
+			return err
+		}
+	}
+
+	existing, err := e.listRules(functionArn)
+	if err != nil {
+		return err
+	}
+	for _, name := range existing {
+		if rules[name] {
+			continue
+		}
+		err = e.deleteRule(name, functionArn)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ruleName returns the name of the rule of an event
+func (e *EventBridge) ruleName(event string) string {
+	return fmt.Sprintf("%s-%s", e.config.GetFunctionName(), event)
+}
+
+// putRule creates or updates the rule of an event and points it to the function
+func (e *EventBridge) putRule(name string, event config.Event, functionArn string) error {
+	tags, err := resourceTags(e.config)
+	if err != nil {
+		return err
+	}
+	var ruleTags []eventbridgeTypes.Tag
+	for _, key := range sortedTagKeys(tags) {
+		ruleTags = append(ruleTags, eventbridgeTypes.Tag{Key: aws.String(key), Value: aws.String(tags[key])})
+	}
+
+	log.Debug(fmt.Sprintf("scheduling event %s...", event.Name))
+	rule, err := e.client.PutRule(context.TODO(), &eventbridge.PutRuleInput{
+		Name:               aws.String(name),
+		ScheduleExpression: aws.String(event.Schedule),
+		State:              eventbridgeTypes.RuleStateEnabled,
+		Tags:               ruleTags,
+	})
+	if err != nil {
+		return err
+	}
+
+	target := eventbridgeTypes.Target{
+		Id:  aws.String(eventTargetId),
+		Arn: aws.String(functionArn),
+	}
+	if len(event.Payload) > 0 {
+		target.Input = aws.String(string(event.Payload))
+	}
+	_, err = e.client.PutTargets(context.TODO(), &eventbridge.PutTargetsInput{
+		Rule:    aws.String(name),
+		Targets: []eventbridgeTypes.Target{target},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = e.lambda.AddPermission(context.TODO(), &lambda.AddPermissionInput{
+		FunctionName: aws.String(functionArn),
+		StatementId:  aws.String(name),
+		Action:       aws.String("lambda:InvokeFunction"),
+		Principal:    aws.String("events.amazonaws.com"),
+		SourceArn:    rule.RuleArn,
+	})
+	if err != nil {
+		var rcErr *lambdaTypes.ResourceConflictException
+		if errors.As(err, &rcErr) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// deleteRule deletes the rule of an event and its invoke permission
+func (e *EventBridge) deleteRule(name, functionArn string) error {
+	log.Debug(fmt.Sprintf("deleting event rule %s...", name))
+	_, err := e.client.RemoveTargets(context.TODO(), &eventbridge.RemoveTargetsInput{
+		Rule: aws.String(name),
+		Ids:  []string{eventTargetId},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = e.client.DeleteRule(context.TODO(), &eventbridge.DeleteRuleInput{
+		Name: aws.String(name),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = e.lambda.RemovePermission(context.TODO(), &lambda.RemovePermissionInput{
+		FunctionName: aws.String(functionArn),
+		StatementId:  aws.String(name),
+	})
+	if err != nil {
+		var rnfErr *lambdaTypes.ResourceNotFoundException
+		if errors.As(err, &rnfErr) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// listRules lists the names of the jerm rules targeting the function
+func (e *EventBridge) listRules(functionArn string) ([]string, error) {
+	log.Debug("listing event rules...")
+	prefix := e.config.GetFunctionName() + "-"
+	var rules []string
+	var nextToken *string
+	for {
+		resp, err := e.client.ListRuleNamesByTarget(context.TODO(), &eventbridge.ListRuleNamesByTargetInput{
+			TargetArn: aws.String(functionArn),
+			NextToken: nextToken,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range resp.RuleNames {
+			if strings.HasPrefix(name, prefix) {
+				rules = append(rules, name)
+			}
+		}
+		if resp.NextToken == nil {
+			return rules, nil
+		}
+		nextToken = resp.NextToken
+	}
+}
@@ -0,0 +1,112 @@
+package aws
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testFunctionArn = "arn:aws:lambda:us-west-1:123456789012:function:test-dev"
+	testAliasArn    = testFunctionArn + ":dev"
+)
+
+func TestNewEventBridge(t *testing.T) {
+	assert := assert.New(t)
+	cfg := &config.Config{}
+	e := NewEventBridge(cfg, aws.Config{})
+	assert.Equal(cfg, e.config)
+	assert.NotNil(e.client)
+	assert.NotNil(e.lambda)
+}
+
+func TestEventBridgeSchedule(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		name       string
+		events     []config.Event
+		keepWarm   bool
+		operations []string
+		check      func(inputs []interface{})
+	}{
+		{
+			name: "schedule events",
+			events: []config.Event{
+				{Name: "report", Schedule: "cron(0 8 * * ? *)", Payload: json.RawMessage(`{"task":"report"}`)},
+			},
+			keepWarm: true,
+			operations: []string{
+				"PutRule", "PutTargets", "AddPermission",
+				"PutRule", "PutTargets", "AddPermission",
+				"ListRuleNamesByTarget",
+				"RemoveTargets", "DeleteRule", "RemovePermission",
+			},
+			check: func(inputs []interface{}) {
+				rule := inputs[0].(*eventbridge.PutRuleInput)
+				assert.Equal("test-dev-report", *rule.Name)
+				assert.Equal("cron(0 8 * * ? *)", *rule.ScheduleExpression)
+				targets := inputs[1].(*eventbridge.PutTargetsInput)
+				assert.Equal(testAliasArn, *targets.Targets[0].Arn)
+				assert.Equal(`{"task":"report"}`, *targets.Targets[0].Input)
+
+				rule = inputs[3].(*eventbridge.PutRuleInput)
+				assert.Equal("test-dev-keep-warm", *rule.Name)
+				assert.Equal(config.KeepWarmSchedule, *rule.ScheduleExpression)
+
+				deleted := inputs[8].(*eventbridge.DeleteRuleInput)
+				assert.Equal("test-dev-cleanup", *deleted.Name)
+			},
+		},
+		{
+			name: "unschedule events",
+			operations: []string{
+				"ListRuleNamesByTarget",
+				"RemoveTargets", "DeleteRule", "RemovePermission",
+				"RemoveTargets", "DeleteRule", "RemovePermission",
+			},
+			check: func(inputs []interface{}) {
+				assert.Equal("test-dev-report", *inputs[2].(*eventbridge.DeleteRuleInput).Name)
+				assert.Equal("test-dev-cleanup", *inputs[5].(*eventbridge.DeleteRuleInput).Name)
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			withAPIOptionsFunc, recorder := mockApi(func(operation string, input interface{}) (interface{}, error) {
+				switch operation {
+				case "PutRule":
+					return &eventbridge.PutRuleOutput{RuleArn: aws.String("arn:aws:events:us-west-1:123456789012:rule/test")}, nil
+				case "PutTargets":
+					return &eventbridge.PutTargetsOutput{}, nil
+				case "ListRuleNamesByTarget":
+					return &eventbridge.ListRuleNamesByTargetOutput{RuleNames: []string{"test-dev-report", "test-dev-cleanup", "other-rule"}}, nil
+				case "RemoveTargets":
+					return &eventbridge.RemoveTargetsOutput{}, nil
+				case "DeleteRule":
+					return &eventbridge.DeleteRuleOutput{}, nil
+				case "AddPermission":
+					return &lambda.AddPermissionOutput{}, nil
+				case "RemovePermission":
+					return &lambda.RemovePermissionOutput{}, nil
+				}
+				return nil, errUnexpectedOperation
+			})
+			l := helperLambda(t, withAPIOptionsFunc)
+			l.config.Events = tt.events
+			l.config.Platform.KeepWarm = tt.keepWarm
+
+			err := l.events.schedule(testAliasArn)
+			assert.Nil(err)
+			assert.Equal(tt.operations, recorder.operations)
+			tt.check(recorder.inputs)
+		})
+	}
+}
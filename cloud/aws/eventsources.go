@@ -0,0 +1,202 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/spatocode/jerm/internal/log"
+)
+
+// EventSources is the AWS Lambda event source mapping operations
+type EventSources struct {
+	config *config.Config
+	client *lambda.Client
+}
+
+// NewEventSources creates a new AWS EventSources object
+func NewEventSources(cfg *config.Config, awsConfig aws.Config) *EventSources {
+	return &EventSources{
+		config: cfg,
+		client: lambda.NewFromConfig(awsConfig),
+	}
+}
+
+// sync reconciles the event source mappings of the function with the config.
+// Mappings of event sources removed from the config are deleted.
+func (e *EventSources) sync(functionArn string) error {
+	sources, err := e.config.GetEventSources()
+	if err != nil {
+		return err
+	}
+
+	mappings, err := e.listMappings(functionArn)
+	if err != nil {
+		return err
+	}
+	existing := map[string]lambdaTypes.EventSourceMappingConfiguration{}
+	for _, mapping := range mappings {
+		existing[aws.ToString(mapping.EventSourceArn)] = mapping
+	}
+
+	for _, source := range sources {
+		mapping, ok := existing[source.Arn]
+		delete(existing, source.Arn)
+		if !ok {
+			err = e.createMapping(source, functionArn)
+		} else {
+			err = e.updateMapping(mapping, source)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, mapping := range existing {
+		err = e.deleteMapping(mapping)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// delete deletes the event source mappings of the function
+func (e *EventSources) delete(functionArn string) error {
+	mappings, err := e.listMappings(functionArn)
+	if err != nil {
+		return err
+	}
+	for _, mapping := range mappings {
+		err = e.deleteMapping(mapping)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// routes returns the event source ARNs mapped to the user functions handling them
+func (e *EventSources) routes() map[string]string {
+	routes := map[string]string{}
+	for _, source := range e.config.EventSources {
+		if source.Function != "" {
+			routes[source.Arn] = source.Function
+		}
+	}
+	return routes
+}
+
+func (e *EventSources) createMapping(source config.EventSource, functionArn string) error {
+	log.Debug(fmt.Sprintf("creating event source mapping for %s...", source.Arn))
+	input := &lambda.CreateEventSourceMappingInput{
+		FunctionName:   aws.String(functionArn),
+		EventSourceArn: aws.String(source.Arn),
+		Enabled:        aws.Bool(source.IsEnabled()),
+	}
+	if len(source.Filters) > 0 {
+		input.FilterCriteria = filterCriteria(source.Filters)
+	}
+	if source.BatchSize != 0 {
+		input.BatchSize = aws.Int32(int32(source.BatchSize))
+	}
+	if source.IsStream() {
+		input.StartingPosition = lambdaTypes.EventSourcePosition(config.StartingPositionLatest)
+		if source.StartingPosition != "" {
+			input.StartingPosition = lambdaTypes.EventSourcePosition(source.StartingPosition)
+		}
+	}
+
+	_, err := e.client.CreateEventSourceMapping(context.TODO(), input)
+	return err
+}
+
+// updateMapping applies the changes of an event source to its mapping.
+// The starting position of a mapping can't be changed.
+func (e *EventSources) updateMapping(mapping lambdaTypes.EventSourceMappingConfiguration, source config.EventSource) error {
+	input := &lambda.UpdateEventSourceMappingInput{
+		UUID: mapping.UUID,
+	}
+	changed := false
+
+	if batchSize := int32(source.BatchSize); batchSize != 0 && aws.ToInt32(mapping.BatchSize) != batchSize {
+		input.BatchSize = aws.Int32(batchSize)
+		changed = true
+	}
+
+	enabled := aws.ToString(mapping.State) == "Enabled" || aws.ToString(mapping.State) == "Enabling"
+	if enabled != source.IsEnabled() {
+		input.Enabled = aws.Bool(source.IsEnabled())
+		changed = true
+	}
+
+	var livePatterns []string
+	if mapping.FilterCriteria != nil {
+		for _, filter := range mapping.FilterCriteria.Filters {
+			livePatterns = append(livePatterns, aws.ToString(filter.Pattern))
+		}
+	}
+	criteria := filterCriteria(source.Filters)
+	var patterns []string
+	for _, filter := range criteria.Filters {
+		patterns = append(patterns, aws.ToString(filter.Pattern))
+	}
+	if !equalIds(livePatterns, patterns) {
+		input.FilterCriteria = criteria
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+
+	log.Debug(fmt.Sprintf("updating event source mapping for %s...", source.Arn))
+	_, err := e.client.UpdateEventSourceMapping(context.TODO(), input)
+	return err
+}
+
+func (e *EventSources) deleteMapping(mapping lambdaTypes.EventSourceMappingConfiguration) error {
+	log.Debug(fmt.Sprintf("deleting event source mapping for %s...", aws.ToString(mapping.EventSourceArn)))
+	_, err := e.client.DeleteEventSourceMapping(context.TODO(), &lambda.DeleteEventSourceMappingInput{
+		UUID: mapping.UUID,
+	})
+	return err
+}
+
+// listMappings lists the event source mappings of the function
+func (e *EventSources) listMappings(functionArn string) ([]lambdaTypes.EventSourceMappingConfiguration, error) {
+	log.Debug("listing event source mappings...")
+	var mappings []lambdaTypes.EventSourceMappingConfiguration
+	paginator := lambda.NewListEventSourceMappingsPaginator(e.client, &lambda.ListEventSourceMappingsInput{
+		FunctionName: aws.String(functionArn),
+	})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, resp.EventSourceMappings...)
+	}
+	return mappings, nil
+}
+
+// filterCriteria returns the filter criteria of the filter patterns.
+// An empty filter criteria removes the filters of a mapping.
+func filterCriteria(filters []json.RawMessage) *lambdaTypes.FilterCriteria {
+	criteria := &lambdaTypes.FilterCriteria{Filters: []lambdaTypes.Filter{}}
+	for _, filter := range filters {
+		pattern := string(filter)
+		var b bytes.Buffer
+		if err := json.Compact(&b, filter); err == nil {
+			pattern = b.String()
+		}
+		criteria.Filters = append(criteria.Filters, lambdaTypes.Filter{Pattern: aws.String(pattern)})
+	}
+	return criteria
+}
@@ -0,0 +1,67 @@
+package aws
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEventSources(t *testing.T) {
+	assert := assert.New(t)
+	cfg := &config.Config{}
+	e := NewEventSources(cfg, aws.Config{})
+	assert.Equal(cfg, e.config)
+	assert.NotNil(e.client)
+}
+
+func TestEventSourcesSync(t *testing.T) {
+	assert := assert.New(t)
+	inputs := map[string]interface{}{}
+	withAPIOptionsFunc, _ := mockApi(func(operation string, input interface{}) (interface{}, error) {
+		inputs[operation] = input
+		switch operation {
+		case "ListEventSourceMappings":
+			return &lambda.ListEventSourceMappingsOutput{EventSourceMappings: []lambdaTypes.EventSourceMappingConfiguration{
+				{UUID: aws.String("1"), EventSourceArn: aws.String("arn:aws:sqs:us-west-1:123456789012:orders"), BatchSize: aws.Int32(10), State: aws.String("Enabled")},
+				{UUID: aws.String("2"), EventSourceArn: aws.String("arn:aws:sqs:us-west-1:123456789012:legacy"), State: aws.String("Enabled")},
+			}}, nil
+		case "CreateEventSourceMapping":
+			return &lambda.CreateEventSourceMappingOutput{}, nil
+		case "UpdateEventSourceMapping":
+			return &lambda.UpdateEventSourceMappingOutput{}, nil
+		case "DeleteEventSourceMapping":
+			return &lambda.DeleteEventSourceMappingOutput{}, nil
+		default:
+			return nil, errUnexpectedOperation
+		}
+	})
+	l := helperLambda(t, withAPIOptionsFunc)
+	l.config.EventSources = []config.EventSource{
+		{Arn: "arn:aws:sqs:us-west-1:123456789012:orders", BatchSize: 5, Function: "app.tasks.process_order"},
+		{Arn: "arn:aws:kinesis:us-west-1:123456789012:stream/clicks", Filters: []json.RawMessage{json.RawMessage(`{"data": {"type": ["click"]}}`)}},
+	}
+
+	err := l.eventSources.sync(testAliasArn)
+	assert.Nil(err)
+
+	updated := inputs["UpdateEventSourceMapping"].(*lambda.UpdateEventSourceMappingInput)
+	assert.Equal("1", *updated.UUID)
+	assert.Equal(int32(5), *updated.BatchSize)
+	assert.Nil(updated.Enabled)
+
+	created := inputs["CreateEventSourceMapping"].(*lambda.CreateEventSourceMappingInput)
+	assert.Equal(testAliasArn, *created.FunctionName)
+	assert.Equal(lambdaTypes.EventSourcePositionLatest, created.StartingPosition)
+	assert.Equal(`{"data":{"type":["click"]}}`, *created.FilterCriteria.Filters[0].Pattern)
+
+	deleted := inputs["DeleteEventSourceMapping"].(*lambda.DeleteEventSourceMappingInput)
+	assert.Equal("2", *deleted.UUID)
+
+	assert.Equal(map[string]string{"arn:aws:sqs:us-west-1:123456789012:orders": "app.tasks.process_order"}, l.eventSources.routes())
+}
@@ -0,0 +1,207 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/spatocode/jerm/internal/log"
+)
+
+const functionUrlPermission = "jerm-function-url"
+
+// serveHttp exposes the stage alias through the HTTP endpoint of the http type
+// of the project. The endpoints of the other types are deleted, so switching
+// types doesn't leave the previous endpoint serving an old deployment.
+func (l *Lambda) serveHttp(aliasArn string) error {
+	httpType := l.config.Platform.GetHttpType()
+
+	if httpType != config.HttpRest {
+		apiIds, err := l.apigateway.getRestApis()
+		if err != nil {
+			return err
+		}
+		if len(apiIds) > 0 {
+			log.Debug("deleting REST API of previous http type...")
+			err = l.apigateway.deleteLogs()
+			if err != nil {
+				return err
+			}
+			err = l.apigateway.delete()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if httpType != config.HttpApi {
+		err := l.httpApi.delete()
+		if err != nil {
+			return err
+		}
+	}
+
+	if httpType != config.HttpUrl {
+		err := l.deleteFunctionUrl()
+		if err != nil {
+			return err
+		}
+	}
+
+	var url string
+	var err error
+	switch httpType {
+	case config.HttpRest:
+		var routes []apiRoute
+		routes, err = l.deployRoutes()
+		if err != nil {
+			return err
+		}
+		err = l.apigateway.setup(&aliasArn, routes)
+	case config.HttpApi:
+		url, err = l.httpApi.setup(aliasArn)
+	case config.HttpUrl:
+		url, err = l.createFunctionUrl()
+	}
+	if err != nil {
+		return err
+	}
+	err = l.removeStaleRoutes()
+	if err != nil {
+		return err
+	}
+	if url != "" {
+		fmt.Printf("%s %s\n", log.Magenta("url:"), log.Green(url))
+	}
+
+	if l.config.Domain != nil {
+		return l.Certify()
+	}
+	return nil
+}
+
+// Certify serves the API of the project at its custom domain
+func (l *Lambda) Certify() error {
+	var apiId, stage string
+	switch l.config.Platform.GetHttpType() {
+	case config.HttpRest:
+		id, err := l.apigateway.getApiId()
+		if err != nil {
+			return err
+		}
+		apiId, stage = aws.ToString(id), l.config.Stage
+	case config.HttpApi:
+		apis, err := l.httpApi.getApis()
+		if err != nil {
+			return err
+		}
+		if len(apis) > 0 {
+			// HTTP APIs are served by their auto deployed default stage
+			apiId, stage = aws.ToString(apis[0].ApiId), "$default"
+		}
+	default:
+		msg := fmt.Sprintf("custom domains require http type %s or %s", config.HttpRest, config.HttpApi)
+		return errors.New(msg)
+	}
+	if apiId == "" {
+		msg := "can't find a deployed API. Run 'jerm deploy' to deploy instead"
+		return errors.New(msg)
+	}
+
+	url, err := l.domains.certify(apiId, stage)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s %s\n", log.Magenta("domain:"), log.Green(url))
+	return nil
+}
+
+// createFunctionUrl creates a public Function URL for the stage alias if it
+// doesn't exist and returns it. Requests are sent with the 2.0 payload format.
+func (l *Lambda) createFunctionUrl() (string, error) {
+	url, err := l.getFunctionUrl()
+	if err != nil {
+		return "", err
+	}
+	if url != "" {
+		return url, nil
+	}
+
+	log.Debug("creating lambda function url...")
+	resp, err := l.client.CreateFunctionUrlConfig(context.TODO(), &lambda.CreateFunctionUrlConfigInput{
+		FunctionName: aws.String(l.config.GetFunctionName()),
+		Qualifier:    aws.String(l.config.Stage),
+		AuthType:     lambdaTypes.FunctionUrlAuthTypeNone,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	_, err = l.client.AddPermission(context.TODO(), &lambda.AddPermissionInput{
+		FunctionName:        aws.String(l.config.GetFunctionName()),
+		Qualifier:           aws.String(l.config.Stage),
+		StatementId:         aws.String(functionUrlPermission),
+		Action:              aws.String("lambda:InvokeFunctionUrl"),
+		Principal:           aws.String("*"),
+		FunctionUrlAuthType: lambdaTypes.FunctionUrlAuthTypeNone,
+	})
+	if err != nil {
+		var rcErr *lambdaTypes.ResourceConflictException
+		if !errors.As(err, &rcErr) {
+			return "", err
+		}
+	}
+	return aws.ToString(resp.FunctionUrl), nil
+}
+
+// getFunctionUrl returns the Function URL of the stage alias. It's empty
+// when the alias has no Function URL.
+func (l *Lambda) getFunctionUrl() (string, error) {
+	resp, err := l.client.GetFunctionUrlConfig(context.TODO(), &lambda.GetFunctionUrlConfigInput{
+		FunctionName: aws.String(l.config.GetFunctionName()),
+		Qualifier:    aws.String(l.config.Stage),
+	})
+	if err != nil {
+		var rnfErr *lambdaTypes.ResourceNotFoundException
+		if errors.As(err, &rnfErr) {
+			return "", nil
+		}
+		return "", err
+	}
+	return aws.ToString(resp.FunctionUrl), nil
+}
+
+// deleteFunctionUrl deletes the Function URL of the stage alias and its public permission
+func (l *Lambda) deleteFunctionUrl() error {
+	url, err := l.getFunctionUrl()
+	if err != nil || url == "" {
+		return err
+	}
+
+	log.Debug("deleting lambda function url...")
+	_, err = l.client.DeleteFunctionUrlConfig(context.TODO(), &lambda.DeleteFunctionUrlConfigInput{
+		FunctionName: aws.String(l.config.GetFunctionName()),
+		Qualifier:    aws.String(l.config.Stage),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = l.client.RemovePermission(context.TODO(), &lambda.RemovePermissionInput{
+		FunctionName: aws.String(l.config.GetFunctionName()),
+		Qualifier:    aws.String(l.config.Stage),
+		StatementId:  aws.String(functionUrlPermission),
+	})
+	if err != nil {
+		var rnfErr *lambdaTypes.ResourceNotFoundException
+		if !errors.As(err, &rnfErr) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/apigateway"
+	"github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	"github.com/spatocode/jerm/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLambdaServeHttp(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		name        string
+		httpType    string
+		functionUrl string
+		operations  []string
+	}{
+		{
+			name:     "create function url",
+			httpType: config.HttpUrl,
+			operations: []string{
+				"GetRestApis", "GetApis",
+				"GetFunctionUrlConfig", "CreateFunctionUrlConfig", "AddPermission",
+				"GetResources",
+			},
+		},
+		{
+			name:        "keep function url",
+			httpType:    config.HttpUrl,
+			functionUrl: "https://abc.lambda-url.us-west-1.on.aws/",
+			operations:  []string{"GetRestApis", "GetApis", "GetFunctionUrlConfig", "GetResources"},
+		},
+		{
+			name:        "delete function url",
+			httpType:    config.HttpNone,
+			functionUrl: "https://abc.lambda-url.us-west-1.on.aws/",
+			operations: []string{
+				"GetRestApis", "GetApis",
+				"GetFunctionUrlConfig", "DeleteFunctionUrlConfig", "RemovePermission",
+				"GetResources",
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			withAPIOptionsFunc, recorder := mockApi(func(operation string, input interface{}) (interface{}, error) {
+				switch operation {
+				case "GetRestApis":
+					return &apigateway.GetRestApisOutput{}, nil
+				case "GetApis":
+					return &apigatewayv2.GetApisOutput{}, nil
+				case "GetFunctionUrlConfig":
+					if tt.functionUrl == "" {
+						return nil, &lambdaTypes.ResourceNotFoundException{}
+					}
+					return &lambda.GetFunctionUrlConfigOutput{FunctionUrl: aws.String(tt.functionUrl)}, nil
+				case "CreateFunctionUrlConfig":
+					return &lambda.CreateFunctionUrlConfigOutput{FunctionUrl: aws.String("https://abc.lambda-url.us-west-1.on.aws/")}, nil
+				case "AddPermission":
+					return &lambda.AddPermissionOutput{}, nil
+				case "DeleteFunctionUrlConfig":
+					return &lambda.DeleteFunctionUrlConfigOutput{}, nil
+				case "RemovePermission":
+					return &lambda.RemovePermissionOutput{}, nil
+				case "GetResources":
+					return &resourcegroupstaggingapi.GetResourcesOutput{}, nil
+				}
+				return nil, errUnexpectedOperation
+			})
+			l := helperLambda(t, withAPIOptionsFunc)
+			l.config.Platform.Http = &config.Http{Type: tt.httpType}
+
+			err := l.serveHttp(testAliasArn)
+			assert.Nil(err)
+			assert.Equal(tt.operations, recorder.operations)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
+	agv2Types "github.com/aws/aws-sdk-go-v2/service/apigatewayv2/types"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/spatocode/jerm/internal/log"
+)
+
+// HttpApi is the AWS API Gateway v2 HTTP API operations
+type HttpApi struct {
+	config *config.Config
+	client *apigatewayv2.Client
+}
+
+// NewHttpApi creates a new AWS HttpApi object
+func NewHttpApi(cfg *config.Config, awsConfig aws.Config) *HttpApi {
+	return &HttpApi{
+		config: cfg,
+		client: apigatewayv2.NewFromConfig(awsConfig),
+	}
+}
+
+// setup creates the HTTP API of the function, or points the existing one at the
+// function, and returns its URL. The API routes every request to the function
+// with the 2.0 payload format on an auto deployed default stage.
+func (h *HttpApi) setup(functionArn string) (string, error) {
+	apis, err := h.getApis()
+	if err != nil {
+		return "", err
+	}
+
+	if len(apis) > 0 {
+		log.Debug("updating HTTP API...")
+		resp, err := h.client.UpdateApi(context.TODO(), &apigatewayv2.UpdateApiInput{
+			ApiId:          apis[0].ApiId,
+			Target:         aws.String(functionArn),
+			CredentialsArn: aws.String(h.config.Platform.Role),
+		})
+		if err != nil {
+			return "", err
+		}
+		return aws.ToString(resp.ApiEndpoint), nil
+	}
+
+	tags, err := resourceTags(h.config)
+	if err != nil {
+		return "", err
+	}
+
+	log.Debug("creating HTTP API...")
+	resp, err := h.client.CreateApi(context.TODO(), &apigatewayv2.CreateApiInput{
+		Name:           aws.String(h.config.GetFunctionName()),
+		ProtocolType:   agv2Types.ProtocolTypeHttp,
+		Description:    aws.String("Automatically created by Jerm"),
+		Target:         aws.String(functionArn),
+		CredentialsArn: aws.String(h.config.Platform.Role),
+		Tags:           tags,
+	})
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(resp.ApiEndpoint), nil
+}
+
+// getApis lists the HTTP APIs of the function
+func (h *HttpApi) getApis() ([]agv2Types.Api, error) {
+	return findApis(h.client, h.config.GetFunctionName(), agv2Types.ProtocolTypeHttp)
+}
+
+// findApis lists the API Gateway v2 APIs with the name and protocol
+func findApis(client *apigatewayv2.Client, name string, protocol agv2Types.ProtocolType) ([]agv2Types.Api, error) {
+	var apis []agv2Types.Api
+	input := &apigatewayv2.GetApisInput{MaxResults: aws.String("500")}
+	for {
+		resp, err := client.GetApis(context.TODO(), input)
+		if err != nil {
+			return nil, err
+		}
+		for _, api := range resp.Items {
+			if aws.ToString(api.Name) == name && api.ProtocolType == protocol {
+				apis = append(apis, api)
+			}
+		}
+		if resp.NextToken == nil {
+			return apis, nil
+		}
+		input.NextToken = resp.NextToken
+	}
+}
+
+// delete deletes the HTTP APIs of the function
+func (h *HttpApi) delete() error {
+	apis, err := h.getApis()
+	if err != nil {
+		return err
+	}
+	for _, api := range apis {
+		log.Debug(fmt.Sprintf("deleting HTTP API %s...", aws.ToString(api.ApiId)))
+		_, err := h.client.DeleteApi(context.TODO(), &apigatewayv2.DeleteApiInput{
+			ApiId: api.ApiId,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
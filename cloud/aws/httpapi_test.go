@@ -0,0 +1,95 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
+	agv2Types "github.com/aws/aws-sdk-go-v2/service/apigatewayv2/types"
+	"github.com/spatocode/jerm/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHttpApiSetup(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		name     string
+		existing []agv2Types.Api
+		url      string
+		check    func(input interface{})
+	}{
+		{
+			name: "create http api",
+			existing: []agv2Types.Api{
+				{ApiId: aws.String("other"), Name: aws.String("other-dev"), ProtocolType: agv2Types.ProtocolTypeHttp},
+				{ApiId: aws.String("ws1"), Name: aws.String("test-dev"), ProtocolType: agv2Types.ProtocolTypeWebsocket},
+			},
+			url: "https://new.execute-api.us-west-1.amazonaws.com",
+			check: func(input interface{}) {
+				created := input.(*apigatewayv2.CreateApiInput)
+				assert.Equal("test-dev", *created.Name)
+				assert.Equal(agv2Types.ProtocolTypeHttp, created.ProtocolType)
+				assert.Equal(testAliasArn, *created.Target)
+				assert.Equal("arn:aws:iam::123456789012:role/test", *created.CredentialsArn)
+				assert.Equal("test", created.Tags[config.TagProject])
+			},
+		},
+		{
+			name: "update http api",
+			existing: []agv2Types.Api{
+				{ApiId: aws.String("http1"), Name: aws.String("test-dev"), ProtocolType: agv2Types.ProtocolTypeHttp},
+			},
+			url: "https://http1.execute-api.us-west-1.amazonaws.com",
+			check: func(input interface{}) {
+				updated := input.(*apigatewayv2.UpdateApiInput)
+				assert.Equal("http1", *updated.ApiId)
+				assert.Equal(testAliasArn, *updated.Target)
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			withAPIOptionsFunc, recorder := mockApi(func(operation string, input interface{}) (interface{}, error) {
+				switch operation {
+				case "GetApis":
+					return &apigatewayv2.GetApisOutput{Items: tt.existing}, nil
+				case "CreateApi":
+					return &apigatewayv2.CreateApiOutput{ApiEndpoint: aws.String("https://new.execute-api.us-west-1.amazonaws.com")}, nil
+				case "UpdateApi":
+					return &apigatewayv2.UpdateApiOutput{ApiEndpoint: aws.String("https://http1.execute-api.us-west-1.amazonaws.com")}, nil
+				}
+				return nil, errUnexpectedOperation
+			})
+			l := helperLambda(t, withAPIOptionsFunc)
+
+			url, err := l.httpApi.setup(testAliasArn)
+			assert.Nil(err)
+			assert.Equal(tt.url, url)
+			tt.check(recorder.inputs[1])
+		})
+	}
+}
+
+func TestHttpApiDelete(t *testing.T) {
+	assert := assert.New(t)
+	withAPIOptionsFunc, recorder := mockApi(func(operation string, input interface{}) (interface{}, error) {
+		switch operation {
+		case "GetApis":
+			return &apigatewayv2.GetApisOutput{Items: []agv2Types.Api{
+				{ApiId: aws.String("http1"), Name: aws.String("test-dev"), ProtocolType: agv2Types.ProtocolTypeHttp},
+				{ApiId: aws.String("other"), Name: aws.String("other-dev"), ProtocolType: agv2Types.ProtocolTypeHttp},
+			}}, nil
+		case "DeleteApi":
+			return &apigatewayv2.DeleteApiOutput{}, nil
+		}
+		return nil, errUnexpectedOperation
+	})
+	l := helperLambda(t, withAPIOptionsFunc)
+
+	err := l.httpApi.delete()
+	assert.Nil(err)
+	assert.Len(recorder.inputs, 2)
+	assert.Equal("http1", *recorder.inputs[1].(*apigatewayv2.DeleteApiInput).ApiId)
+}
@@ -3,13 +3,12 @@ package aws
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
-	"sort"
-	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -20,14 +19,30 @@ import (
 
 	"github.com/spatocode/jerm"
 	"github.com/spatocode/jerm/config"
+	"github.com/spatocode/jerm/config/handlers"
 	"github.com/spatocode/jerm/internal/log"
 	"github.com/spatocode/jerm/internal/utils"
 )
 
 const (
-	DefaultTimeout      = 30
 	DefaultWaitDuration = 20
 	DefaultMaxRetry     = 3
+
+	// EventSourcesEnv is the environment variable that maps the ARNs of event sources
+	// and triggers to the user functions the generated handlers route their records to
+	EventSourcesEnv = "JERM_EVENT_SOURCES"
+
+	// AuthorizerEnv is the environment variable of the handler the generated
+	// handlers route the events of the Lambda authorizer of the REST API to
+	AuthorizerEnv = "JERM_AUTHORIZER"
+
+	// CorsEnv is the environment variable of the CORS origins the generated
+	// handlers allow in the responses of the function
+	CorsEnv = "JERM_CORS"
+
+	// WebSocketEnv is the environment variable of the handler the generated
+	// handlers route the events of the WebSocket API to
+	WebSocketEnv = "JERM_WEBSOCKET"
 )
 
 // Lambda is the AWS Lambda operations
@@ -36,15 +51,40 @@ type Lambda struct {
 	storage           jerm.CloudStorage
 	monitor           jerm.CloudMonitor
 	apigateway        *ApiGateway
+	httpApi           *HttpApi
+	webSocket         *WebSocketApi
+	domains           *Domains
+	registry          *ECR
+	secrets           *Secrets
+	vpc               *VPC
+	events            *EventBridge
+	eventSources      *EventSources
+	triggers          *Triggers
+	metrics           *Metrics
+	inventory         *Inventory
 	functionHandler   string
+	code              *lambdaTypes.FunctionCode
+	imageDir          string
 	description       string
 	config            *config.Config
 	retry             int
 	maxWaiterDuration time.Duration
-	timeout           int32
+	canaryWeight      int
+	canaryWindow      time.Duration
+	canaryInterval    time.Duration
 	client            *lambda.Client
 }
 
+func init() {
+	jerm.RegisterPlatform(config.Lambda, func(cfg *config.Config) (jerm.CloudPlatform, error) {
+		l, err := NewLambda(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return l, nil
+	})
+}
+
 // NewLambda instantiates a new AWS Lambda service
 func NewLambda(cfg *config.Config) (*Lambda, error) {
 	l := &Lambda{
@@ -52,16 +92,85 @@ func NewLambda(cfg *config.Config) (*Lambda, error) {
 		config:            cfg,
 		retry:             DefaultMaxRetry,
 		maxWaiterDuration: DefaultWaitDuration,
-		timeout:           DefaultTimeout,
+		canaryWindow:      DefaultCanaryWindow,
+		canaryInterval:    DefaultCanaryInterval,
 	}
 
 	if l.config.Platform.Name == "" {
-		lambdaConfig := config.Platform{Name: config.Lambda}
-		err := lambdaConfig.Defaults()
+		l.config.Platform.Name = config.Lambda
+	}
+	err := l.config.Platform.Defaults()
+	if err != nil {
+		return nil, err
+	}
+
+	switch l.config.Platform.PackageType {
+	case "", config.PackageZip, config.PackageImage:
+	default:
+		msg := fmt.Sprintf("invalid package_type %s. Supported package types are %s and %s", l.config.Platform.PackageType, config.PackageZip, config.PackageImage)
+		return nil, errors.New(msg)
+	}
+
+	switch l.config.Platform.GetHttpType() {
+	case config.HttpRest, config.HttpApi, config.HttpUrl, config.HttpNone:
+	default:
+		msg := fmt.Sprintf("invalid http type %s. Supported http types are %s, %s, %s and %s", l.config.Platform.GetHttpType(), config.HttpRest, config.HttpApi, config.HttpUrl, config.HttpNone)
+		return nil, errors.New(msg)
+	}
+
+	_, err = l.config.GetTags()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = l.config.GetApiGateway()
+	if err != nil {
+		return nil, err
+	}
+
+	if l.config.Domain != nil {
+		_, err = l.config.GetDomain()
+		if err != nil {
+			return nil, err
+		}
+		switch l.config.Platform.GetHttpType() {
+		case config.HttpRest, config.HttpApi:
+		default:
+			msg := fmt.Sprintf("custom domains require http type %s or %s", config.HttpRest, config.HttpApi)
+			return nil, errors.New(msg)
+		}
+	}
+
+	_, err = l.config.GetAuth()
+	if err != nil {
+		return nil, err
+	}
+	if l.config.Auth != nil && l.config.Platform.GetHttpType() != config.HttpRest {
+		msg := fmt.Sprintf("auth requires http type %s", config.HttpRest)
+		return nil, errors.New(msg)
+	}
+
+	_, err = l.config.GetRoutes()
+	if err != nil {
+		return nil, err
+	}
+	if len(l.config.Routes) > 0 && l.config.Platform.GetHttpType() != config.HttpRest {
+		msg := fmt.Sprintf("routes require http type %s", config.HttpRest)
+		return nil, errors.New(msg)
+	}
+
+	if l.config.WebSocket != nil {
+		_, err = l.config.GetWebSocket()
 		if err != nil {
 			return nil, err
 		}
-		l.config.Platform = lambdaConfig
+	}
+
+	switch l.config.SecretsMode {
+	case "", config.SecretsDeploy, config.SecretsRuntime:
+	default:
+		msg := fmt.Sprintf("invalid secrets_mode %s. Supported secrets modes are %s and %s", l.config.SecretsMode, config.SecretsDeploy, config.SecretsRuntime)
+		return nil, errors.New(msg)
 	}
 
 	awsConfig, err := l.getAwsConfig()
@@ -74,6 +183,17 @@ func NewLambda(cfg *config.Config) (*Lambda, error) {
 	l.storage = NewS3(cfg, *awsConfig)
 	l.access = NewIAM(cfg, *awsConfig)
 	l.apigateway = NewApiGateway(cfg, *awsConfig)
+	l.httpApi = NewHttpApi(cfg, *awsConfig)
+	l.webSocket = NewWebSocketApi(cfg, *awsConfig)
+	l.domains = NewDomains(cfg, *awsConfig)
+	l.registry = NewECR(cfg, *awsConfig)
+	l.secrets = NewSecrets(cfg, *awsConfig)
+	l.vpc = NewVPC(cfg, *awsConfig)
+	l.events = NewEventBridge(cfg, *awsConfig)
+	l.eventSources = NewEventSources(cfg, *awsConfig)
+	l.triggers = NewTriggers(cfg, *awsConfig)
+	l.metrics = NewMetrics(cfg, *awsConfig)
+	l.inventory = NewInventory(cfg, *awsConfig)
 
 	go func() {
 		err := l.config.ToJson(jerm.DefaultConfigFile)
@@ -90,6 +210,26 @@ func NewLambda(cfg *config.Config) (*Lambda, error) {
 	return l, nil
 }
 
+// List lists the jerm deployments of the account in the region
+func (l *Lambda) List() ([]jerm.Deployment, error) {
+	return l.inventory.list()
+}
+
+// SetSecret stores a secret of the project stage
+func (l *Lambda) SetSecret(key, value string) error {
+	return l.secrets.set(key, value)
+}
+
+// GetSecret fetches a secret of the project stage
+func (l *Lambda) GetSecret(key string) (string, error) {
+	return l.secrets.get(key)
+}
+
+// ListSecrets lists the secret keys of the project stage
+func (l *Lambda) ListSecrets() ([]string, error) {
+	return l.secrets.list()
+}
+
 func (l *Lambda) WithMonitor(monitor jerm.CloudMonitor) {
 	l.monitor = monitor
 }
@@ -114,9 +254,77 @@ func (l *Lambda) Build() (string, error) {
 
 	l.functionHandler = function
 
+	if l.isImage() {
+		return l.writeDockerfile(r, packageDir, function)
+	}
+
+	return packageDir, nil
+}
+
+// isImage checks if the function is deployed as a container image
+func (l *Lambda) isImage() bool {
+	return l.config.Platform.PackageType == config.PackageImage
+}
+
+// writeDockerfile writes the Dockerfile of the function image into the package.
+// It returns the package directory.
+func (l *Lambda) writeDockerfile(r config.RuntimeInterface, packageDir, function string) (string, error) {
+	var dockerfile string
+	switch runtime := r.(type) {
+	case *config.Python:
+		dockerfile = fmt.Sprintf(handlers.AwsLambdaDockerfilePython, l.baseImageTag(), function)
+	case *config.Node:
+		dockerfile = fmt.Sprintf(handlers.AwsLambdaDockerfileNode, l.baseImageTag(), function)
+	case *config.Go:
+		// Go packages are standalone executables. The directory is removed once the image is pushed.
+		dir, err := os.MkdirTemp(os.TempDir(), "jerm-package")
+		if err != nil {
+			return "", err
+		}
+		content, err := os.ReadFile(packageDir)
+		if err != nil {
+			os.RemoveAll(dir)
+			return "", err
+		}
+		executable := filepath.Base(packageDir)
+		err = os.WriteFile(filepath.Join(dir, executable), content, 0755)
+		if err != nil {
+			os.RemoveAll(dir)
+			return "", err
+		}
+		l.imageDir = dir
+		packageDir = dir
+		dockerfile = fmt.Sprintf(handlers.AwsLambdaDockerfileGo, executable)
+	case *config.Runtime:
+		if runtime.Name != config.RuntimeStatic {
+			return "", errors.New("cannot detect runtime. please specify runtime in your Jerm.json file")
+		}
+		dockerfile = fmt.Sprintf(handlers.AwsLambdaDockerfileNode, l.baseImageTag(), function)
+	}
+
+	err := os.WriteFile(filepath.Join(packageDir, "Dockerfile"), []byte(dockerfile), 0644)
+	if err != nil {
+		return "", err
+	}
 	return packageDir, nil
 }
 
+// removeImageDir removes the temporary package directory of the function image
+func (l *Lambda) removeImageDir() {
+	if l.imageDir == "" {
+		return
+	}
+	os.RemoveAll(l.imageDir)
+	l.imageDir = ""
+}
+
+// baseImageTag returns the Lambda base image tag of the function runtime.
+// Runtimes such as python3.11 and nodejs18.x have tags 3.11 and 18.
+func (l *Lambda) baseImageTag() string {
+	tag := strings.TrimLeft(l.config.Platform.Runtime, "abcdefghijklmnopqrstuvwxyz")
+	return strings.TrimSuffix(tag, ".x")
+}
+
 func (l *Lambda) Invoke(command string) error {
 	payload := fmt.Sprintf(`{"manage": "%s"}`, command)
 	return l.invokeLambdaFunction([]byte(payload))
@@ -176,6 +384,10 @@ func (l *Lambda) Deploy(zipPath string) (bool, error) {
 		return true, nil
 	}
 
+	if l.isImage() {
+		return false, l.deployImage(zipPath)
+	}
+
 	if err := l.storage.Accessible(); err != nil {
 		var nfErr *s3Types.NotFound
 		if errors.As(err, &nfErr) {
@@ -192,8 +404,15 @@ func (l *Lambda) Deploy(zipPath string) (bool, error) {
 		}
 	}
 
-	l.storage.Upload(zipPath)
-	functionArn, err := l.createLambdaFunction(zipPath)
+	key, err := l.uploadArchive(zipPath)
+	if err != nil {
+		return false, err
+	}
+	l.code = &lambdaTypes.FunctionCode{
+		S3Bucket: aws.String(l.config.Bucket),
+		S3Key:    aws.String(key),
+	}
+	_, err = l.createLambdaFunction(l.code)
 	if err != nil {
 		return false, err
 	}
@@ -203,8 +422,7 @@ func (l *Lambda) Deploy(zipPath string) (bool, error) {
 		return false, err
 	}
 
-	l.scheduleEvents()
-	err = l.apigateway.setup(functionArn)
+	err = l.release()
 	if err != nil {
 		return false, err
 	}
@@ -214,16 +432,35 @@ func (l *Lambda) Deploy(zipPath string) (bool, error) {
 		return false, err
 	}
 
-	if err = l.storage.Accessible(); err != nil {
-		return false, err
+	return false, nil
+}
+
+// deployImage pushes the function image and creates the function from it
+func (l *Lambda) deployImage(zipPath string) error {
+	defer l.removeImageDir()
+
+	image, err := l.registry.pushImage(zipPath)
+	if err != nil {
+		return err
 	}
 
-	err = l.storage.Delete(zipPath)
+	l.code = &lambdaTypes.FunctionCode{ImageUri: aws.String(image)}
+	_, err = l.createLambdaFunction(l.code)
 	if err != nil {
-		return false, err
+		return err
 	}
 
-	return false, nil
+	err = l.waitTillFunctionBecomesActive()
+	if err != nil {
+		return err
+	}
+
+	err = l.release()
+	if err != nil {
+		return err
+	}
+
+	return utils.RemoveLocalFile(zipPath)
 }
 
 func (l *Lambda) waitTillFunctionBecomesActive() error {
@@ -247,11 +484,44 @@ func (l *Lambda) waitTillFunctionBecomesUpdated() {
 	}
 }
 
-func (l *Lambda) scheduleEvents() {
+// tagFunction tags the function and its log group with the tags of the project
+func (l *Lambda) tagFunction(aliasArn string) error {
+	tags, err := resourceTags(l.config)
+	if err != nil {
+		return err
+	}
+
+	// alias ARNs are in the form arn:aws:lambda:<region>:<account>:function:<name>:<alias>
+	parts := strings.Split(aliasArn, ":")
+	functionArn := strings.Join(parts[:7], ":")
+	log.Debug("tagging lambda function...")
+	_, err = l.client.TagResource(context.TODO(), &lambda.TagResourceInput{
+		Resource: aws.String(functionArn),
+		Tags:     tags,
+	})
+	if err != nil {
+		return err
+	}
+
+	monitor, ok := l.monitor.(*CloudWatch)
+	if !ok {
+		return nil
+	}
+	groupName := fmt.Sprintf("/aws/lambda/%s", l.config.GetFunctionName())
+	groupArn := fmt.Sprintf("arn:%s:logs:%s:%s:log-group:%s", parts[1], parts[3], parts[4], groupName)
+	return monitor.ensureLogGroup(groupName, groupArn, tags)
+}
 
+// scheduleEvents reconciles the scheduled events of the function
+func (l *Lambda) scheduleEvents(functionArn *string) error {
+	return l.events.schedule(*functionArn)
 }
 
 func (l *Lambda) Update(zipPath string) error {
+	if l.isImage() {
+		return l.updateImage(zipPath)
+	}
+
 	if err := l.storage.Accessible(); err != nil {
 		var nfErr *s3Types.NotFound
 		if errors.As(err, &nfErr) {
@@ -268,20 +538,13 @@ func (l *Lambda) Update(zipPath string) error {
 		}
 	}
 
-	err := l.storage.Upload(zipPath)
+	key, err := l.uploadArchive(zipPath)
 	if err != nil {
 		return err
 	}
-
-	file, err := os.Open(zipPath)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	content, err := io.ReadAll(file)
-	if err != nil {
-		return err
+	l.code = &lambdaTypes.FunctionCode{
+		S3Bucket: aws.String(l.config.Bucket),
+		S3Key:    aws.String(key),
 	}
 
 	_, err = l.getLambdaFunction(l.config.GetFunctionName())
@@ -289,25 +552,22 @@ func (l *Lambda) Update(zipPath string) error {
 		return err
 	}
 
-	functionArn, err := l.updateLambdaFunction(content)
+	_, err = l.updateLambdaFunction(l.code)
 	if err != nil {
 		return err
 	}
 
 	l.waitTillFunctionBecomesUpdated()
-	l.scheduleEvents()
-
-	err = l.apigateway.setup(functionArn)
+	err = l.updateLambdaConfiguration()
 	if err != nil {
 		return err
 	}
-
-	err = utils.RemoveLocalFile(zipPath)
+	err = l.release()
 	if err != nil {
 		return err
 	}
 
-	err = l.storage.Delete(zipPath)
+	err = utils.RemoveLocalFile(zipPath)
 	if err != nil {
 		return err
 	}
@@ -315,47 +575,66 @@ func (l *Lambda) Update(zipPath string) error {
 	return nil
 }
 
-// Undeploy deletes a Lambda deployment
-func (l *Lambda) Undeploy() error {
-	deployed, err := l.isAlreadyDeployed()
+// uploadArchive uploads the deployment archive to the bucket and returns its key
+func (l *Lambda) uploadArchive(zipPath string) (string, error) {
+	storage, ok := l.storage.(*S3)
+	if !ok {
+		return filepath.Base(zipPath), l.storage.Upload(zipPath)
+	}
+	return storage.uploadArchive(zipPath)
+}
+
+// updateImage pushes a new function image and updates the function to it
+func (l *Lambda) updateImage(zipPath string) error {
+	defer l.removeImageDir()
+
+	_, err := l.getLambdaFunction(l.config.GetFunctionName())
 	if err != nil {
 		return err
 	}
-	if !deployed {
-		msg := "can't find a deployed project. Run 'jerm deploy' to deploy instead"
-		return errors.New(msg)
+
+	image, err := l.registry.pushImage(zipPath)
+	if err != nil {
+		return err
 	}
 
-	log.Debug("undeploying...")
-	err = l.apigateway.delete()
+	l.code = &lambdaTypes.FunctionCode{ImageUri: aws.String(image)}
+	_, err = l.updateLambdaFunctionImage(image)
 	if err != nil {
 		return err
 	}
 
-	err = l.apigateway.deleteLogs()
+	l.waitTillFunctionBecomesUpdated()
+	err = l.updateLambdaConfiguration()
+	if err != nil {
+		return err
+	}
+	err = l.release()
 	if err != nil {
 		return err
 	}
 
-	l.deleteLambdaFunction()
-	groupName := fmt.Sprintf("/aws/lambda/%s", l.config.GetFunctionName())
-	l.monitor.Clear(groupName)
+	return utils.RemoveLocalFile(zipPath)
+}
 
-	return nil
+// Undeploy deletes a Lambda deployment with every resource jerm created for it
+func (l *Lambda) Undeploy() error {
+	return l.Teardown(jerm.UndeployOptions{})
 }
 
-// deleteLambdaFunction deletes a Lambda function
-func (l *Lambda) deleteLambdaFunction() {
+// deleteLambdaFunction deletes a Lambda function with its versions and aliases
+func (l *Lambda) deleteLambdaFunction() error {
 	log.Debug("deleting lambda function...")
-	l.client.DeleteFunction(context.TODO(), &lambda.DeleteFunctionInput{
+	_, err := l.client.DeleteFunction(context.TODO(), &lambda.DeleteFunctionInput{
 		FunctionName: aws.String(l.config.GetFunctionName()),
 	})
+	return err
 }
 
-// Rollback rolls back a Lambda deployment to a number of previous versions `revision`
+// Rollback rolls back a Lambda deployment to a number of previous versions `steps`
+// by pointing the stage alias at the version
 func (l *Lambda) Rollback(steps int) error {
-	var revisions []int
-	versions, err := l.listLambdaVersions()
+	alias, err := l.getAlias()
 	if err != nil {
 		var rnfErr *lambdaTypes.ResourceNotFoundException
 		if errors.As(err, &rnfErr) {
@@ -364,68 +643,34 @@ func (l *Lambda) Rollback(steps int) error {
 		}
 		return err
 	}
-	if len(versions) > 1 && versions[len(versions)-1].PackageType == "Image" {
-		msg := "rollback unavailable for Docker deployment. Aborting"
-		return errors.New(msg)
-	}
-
-	if len(versions) <= steps+1 {
-		msg := "invalid revision for rollback. Aborting"
-		return errors.New(msg)
-	}
-
-	for _, revision := range versions {
-		version, _ := strconv.Atoi(*revision.Version)
-		revisions = append(revisions, version)
-	}
-
-	sort.Slice(revisions, func(i int, j int) bool {
-		return revisions[i] > revisions[j]
-	})
-
-	name := fmt.Sprintf("%s:%v", l.config.GetFunctionName(), revisions[steps])
-	function, err := l.getLambdaFunction(name)
-	if err != nil {
-		var rnfErr *lambdaTypes.ResourceNotFoundException
-		if errors.As(err, &rnfErr) {
-			msg := fmt.Sprintf("can't find a lambda function %s", name)
-			return errors.New(msg)
-		}
-		return err
-	}
 
-	location := function.Code.Location
-	log.Debug("fetching function code location...")
-	res, err := utils.Request(*location)
+	previous, err := l.previousVersions(aws.ToString(alias.FunctionVersion))
 	if err != nil {
 		return err
 	}
-
-	if res.StatusCode != 200 {
-		msg := fmt.Sprintf("Unable to get version %v of project %s", revisions[steps], l.config.GetFunctionName())
+	if steps < 1 || len(previous) < steps {
+		msg := "invalid revision for rollback. Aborting"
 		return errors.New(msg)
 	}
-	defer res.Body.Close()
 
-	log.Debug("reading fetched data...")
-	b, err := io.ReadAll(res.Body)
-	if err != nil {
-		return err
-	}
-
-	l.updateLambdaFunction(b)
-	return nil
+	return l.updateAlias(previous[steps-1], nil)
 }
 
+// listLambdaVersions lists the versions of the function, including $LATEST
 func (l *Lambda) listLambdaVersions() ([]lambdaTypes.FunctionConfiguration, error) {
 	log.Debug("list lambda versions by function...")
-	response, err := l.client.ListVersionsByFunction(context.TODO(), &lambda.ListVersionsByFunctionInput{
+	var versions []lambdaTypes.FunctionConfiguration
+	paginator := lambda.NewListVersionsByFunctionPaginator(l.client, &lambda.ListVersionsByFunctionInput{
 		FunctionName: aws.String(l.config.GetFunctionName()),
 	})
-	if err != nil {
-		return nil, err
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, resp.Versions...)
 	}
-	return response.Versions, err
+	return versions, nil
 }
 
 func (l *Lambda) isAlreadyDeployed() (bool, error) {
@@ -441,27 +686,46 @@ func (l *Lambda) isAlreadyDeployed() (bool, error) {
 	return len(versions) > 0, nil
 }
 
-func (l *Lambda) createLambdaFunction(zipPath string) (*string, error) {
+func (l *Lambda) createLambdaFunction(code *lambdaTypes.FunctionCode) (*string, error) {
 	name := l.config.GetFunctionName()
 	function, err := l.getLambdaFunction(name)
 	if err == nil {
 		return function.Configuration.FunctionArn, nil
 	}
-	fileName := filepath.Base(zipPath)
+	env, err := l.environment()
+	if err != nil {
+		return nil, err
+	}
+
+	vpc, err := l.vpc.vpcConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := resourceTags(l.config)
+	if err != nil {
+		return nil, err
+	}
+
 	log.Debug("creating lambda function...")
-	resp, err := l.client.CreateFunction(context.TODO(), &lambda.CreateFunctionInput{
-		Code: &lambdaTypes.FunctionCode{
-			S3Bucket: aws.String(l.config.Bucket),
-			S3Key:    aws.String(fileName),
-		},
+	input := &lambda.CreateFunctionInput{
+		Code:         code,
 		FunctionName: aws.String(name),
 		Description:  aws.String(l.description),
 		Role:         &l.config.Platform.Role,
-		Runtime:      lambdaTypes.Runtime(l.config.Platform.Runtime),
-		Handler:      aws.String(l.functionHandler),
-		Timeout:      aws.Int32(l.timeout),
-		Publish:      true,
-	})
+		Timeout:      aws.Int32(int32(l.config.Platform.Timeout)),
+		MemorySize:   aws.Int32(int32(l.config.Platform.Memory)),
+		Environment:  &lambdaTypes.Environment{Variables: env},
+		VpcConfig:    vpc,
+		Tags:         tags,
+	}
+	if code.ImageUri != nil {
+		input.PackageType = lambdaTypes.PackageTypeImage
+	} else {
+		input.Runtime = lambdaTypes.Runtime(l.config.Platform.Runtime)
+		input.Handler = aws.String(l.functionHandler)
+	}
+	resp, err := l.client.CreateFunction(context.TODO(), input)
 	if err != nil {
 		return nil, err
 	}
@@ -479,15 +743,205 @@ func (l *Lambda) getLambdaFunction(name string) (*lambda.GetFunctionOutput, erro
 	return resp, err
 }
 
-func (l *Lambda) updateLambdaFunction(content []byte) (*string, error) {
+// updateLambdaFunction updates the function code to the archive uploaded to the bucket
+func (l *Lambda) updateLambdaFunction(code *lambdaTypes.FunctionCode) (*string, error) {
 	log.Debug("updating lambda function code...")
 	resp, err := l.client.UpdateFunctionCode(context.TODO(), &lambda.UpdateFunctionCodeInput{
 		FunctionName: aws.String(l.config.GetFunctionName()),
-		ZipFile:      content,
-		Publish:      true,
+		S3Bucket:     code.S3Bucket,
+		S3Key:        code.S3Key,
 	})
 	if err != nil {
 		return nil, err
 	}
 	return resp.FunctionArn, nil
 }
+
+func (l *Lambda) updateLambdaFunctionImage(image string) (*string, error) {
+	log.Debug("updating lambda function image...")
+	resp, err := l.client.UpdateFunctionCode(context.TODO(), &lambda.UpdateFunctionCodeInput{
+		FunctionName: aws.String(l.config.GetFunctionName()),
+		ImageUri:     aws.String(image),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.FunctionArn, nil
+}
+
+// environment returns the environment variables of the function.
+// Secret references are resolved unless the function reads them at runtime.
+func (l *Lambda) environment() (map[string]string, error) {
+	env, err := l.config.GetEnvironment()
+	if err != nil {
+		return nil, err
+	}
+
+	routes := l.eventSources.routes()
+	for arn, function := range l.triggers.routes() {
+		routes[arn] = function
+	}
+	if len(routes) > 0 {
+		b, err := json.Marshal(routes)
+		if err != nil {
+			return nil, err
+		}
+		env[EventSourcesEnv] = string(b)
+	}
+
+	auth, err := l.config.GetAuth()
+	if err != nil {
+		return nil, err
+	}
+	if auth.Lambda != nil && auth.Lambda.Handler != "" {
+		env[AuthorizerEnv] = auth.Lambda.Handler
+	}
+
+	settings, err := l.config.GetApiGateway()
+	if err != nil {
+		return nil, err
+	}
+	if settings.Cors != nil && l.config.Platform.GetHttpType() == config.HttpRest {
+		b, err := json.Marshal(map[string]interface{}{
+			"origins":     settings.Cors.Origins,
+			"credentials": settings.Cors.Credentials,
+		})
+		if err != nil {
+			return nil, err
+		}
+		env[CorsEnv] = string(b)
+	}
+
+	if l.config.WebSocket != nil && l.config.WebSocket.Handler != "" {
+		env[WebSocketEnv] = l.config.WebSocket.Handler
+	}
+
+	if l.config.ResolvesSecretsAtRuntime() {
+		return env, nil
+	}
+	return l.secrets.resolve(env)
+}
+
+// updateLambdaConfiguration applies the changes of the Platform config
+// to the deployed function configuration
+func (l *Lambda) updateLambdaConfiguration() error {
+	function, err := l.getLambdaFunction(l.config.GetFunctionName())
+	if err != nil {
+		return err
+	}
+
+	env, err := l.environment()
+	if err != nil {
+		return err
+	}
+
+	vpc, err := l.vpc.vpcConfig()
+	if err != nil {
+		return err
+	}
+
+	input := l.configurationChanges(function.Configuration, env, vpc)
+	if input == nil {
+		log.Debug("lambda function configuration is up to date")
+		return nil
+	}
+
+	log.Debug("updating lambda function configuration...")
+	_, err = l.client.UpdateFunctionConfiguration(context.TODO(), input)
+	if err != nil {
+		return err
+	}
+
+	l.waitTillFunctionBecomesUpdated()
+	return nil
+}
+
+// configurationChanges diffs the live function configuration against the Platform config.
+// It returns nil if there's nothing to update.
+func (l *Lambda) configurationChanges(live *lambdaTypes.FunctionConfiguration, env map[string]string, vpc *lambdaTypes.VpcConfig) *lambda.UpdateFunctionConfigurationInput {
+	input := l.functionConfigurationChanges(l.config.GetFunctionName(), l.config.Platform.Memory, l.config.Platform.Timeout, live, env, vpc)
+
+	// runtime and handler are defined by the image of image functions
+	if live.PackageType != lambdaTypes.PackageTypeImage && l.functionHandler != "" && aws.ToString(live.Handler) != l.functionHandler {
+		if input == nil {
+			input = &lambda.UpdateFunctionConfigurationInput{FunctionName: aws.String(l.config.GetFunctionName())}
+		}
+		input.Handler = aws.String(l.functionHandler)
+	}
+	return input
+}
+
+// functionConfigurationChanges diffs the live configuration of a function of the
+// project against the Platform config with the memory and timeout of the function.
+// The handler is left out. It returns nil if there's nothing to update.
+func (l *Lambda) functionConfigurationChanges(name string, memorySize, timeoutSeconds int, live *lambdaTypes.FunctionConfiguration, env map[string]string, vpc *lambdaTypes.VpcConfig) *lambda.UpdateFunctionConfigurationInput {
+	input := &lambda.UpdateFunctionConfigurationInput{
+		FunctionName: aws.String(name),
+	}
+	changed := false
+
+	if memory := int32(memorySize); memory != 0 && aws.ToInt32(live.MemorySize) != memory {
+		input.MemorySize = aws.Int32(memory)
+		changed = true
+	}
+	if timeout := int32(timeoutSeconds); timeout != 0 && aws.ToInt32(live.Timeout) != timeout {
+		input.Timeout = aws.Int32(timeout)
+		changed = true
+	}
+	if role := l.config.Platform.Role; role != "" && aws.ToString(live.Role) != role {
+		input.Role = aws.String(role)
+		changed = true
+	}
+	if aws.ToString(live.Description) != l.description {
+		input.Description = aws.String(l.description)
+		changed = true
+	}
+	var liveEnv map[string]string
+	if live.Environment != nil {
+		liveEnv = live.Environment.Variables
+	}
+	if !equalEnvironment(liveEnv, env) {
+		input.Environment = &lambdaTypes.Environment{Variables: env}
+		changed = true
+	}
+
+	// an empty VPC configuration detaches the function from its VPC
+	if vpc == nil {
+		vpc = &lambdaTypes.VpcConfig{SubnetIds: []string{}, SecurityGroupIds: []string{}}
+	}
+	var liveSubnets, liveSecurityGroups []string
+	if live.VpcConfig != nil {
+		liveSubnets = live.VpcConfig.SubnetIds
+		liveSecurityGroups = live.VpcConfig.SecurityGroupIds
+	}
+	if !equalIds(liveSubnets, vpc.SubnetIds) || !equalIds(liveSecurityGroups, vpc.SecurityGroupIds) {
+		input.VpcConfig = vpc
+		changed = true
+	}
+
+	// the runtime is defined by the image of image functions
+	if live.PackageType != lambdaTypes.PackageTypeImage {
+		if runtime := l.config.Platform.Runtime; runtime != "" && string(live.Runtime) != runtime {
+			input.Runtime = lambdaTypes.Runtime(runtime)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+	return input
+}
+
+// equalEnvironment checks if two sets of environment variables are the same
+func equalEnvironment(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if value, ok := b[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
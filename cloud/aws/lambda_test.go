@@ -0,0 +1,176 @@
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+
+	"github.com/aws/smithy-go/middleware"
+	"github.com/spatocode/jerm/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLambdaBaseImageTag(t *testing.T) {
+	assert := assert.New(t)
+	l := &Lambda{config: &config.Config{}}
+	l.config.Platform.Runtime = "python3.11"
+	assert.Equal("3.11", l.baseImageTag())
+	l.config.Platform.Runtime = "nodejs18.x"
+	assert.Equal("18", l.baseImageTag())
+}
+
+func TestLambdaWriteDockerfile(t *testing.T) {
+	assert := assert.New(t)
+	l := &Lambda{config: &config.Config{}}
+	l.config.Platform.Runtime = "nodejs18.x"
+
+	packageDir := t.TempDir()
+	node := &config.Node{Runtime: &config.Runtime{Name: config.RuntimeNode}}
+	dir, err := l.writeDockerfile(node, packageDir, "index.handler")
+	assert.Nil(err)
+	assert.Equal(packageDir, dir)
+	b, err := os.ReadFile(filepath.Join(dir, "Dockerfile"))
+	assert.Nil(err)
+	assert.Contains(string(b), "FROM public.ecr.aws/lambda/nodejs:18")
+	assert.Contains(string(b), `CMD [ "index.handler" ]`)
+
+	executable := filepath.Join(t.TempDir(), "main")
+	err = os.WriteFile(executable, []byte("binary"), 0644)
+	assert.Nil(err)
+	golang := &config.Go{Runtime: &config.Runtime{Name: config.RuntimeGo}}
+	dir, err = l.writeDockerfile(golang, executable, "main")
+	assert.Nil(err)
+	assert.Equal(dir, l.imageDir)
+	defer l.removeImageDir()
+	b, err = os.ReadFile(filepath.Join(dir, "Dockerfile"))
+	assert.Nil(err)
+	assert.Contains(string(b), "COPY main ${LAMBDA_RUNTIME_DIR}/bootstrap")
+	info, err := os.Stat(filepath.Join(dir, "main"))
+	assert.Nil(err)
+	assert.Equal(os.FileMode(0755), info.Mode().Perm())
+	l.removeImageDir()
+	assert.NoDirExists(dir)
+	assert.Empty(l.imageDir)
+
+	_, err = l.writeDockerfile(&config.Runtime{Name: config.RuntimeUnknown}, packageDir, "")
+	assert.EqualError(err, "cannot detect runtime. please specify runtime in your Jerm.json file")
+}
+
+func TestNewLambdaKeepsPlatformConfig(t *testing.T) {
+	assert := assert.New(t)
+	cfg := &config.Config{Name: "test", Stage: "dev", SecretsMode: "invalid"}
+	cfg.Platform = config.Platform{Runtime: "python3.11", Handler: "app.handler", Memory: 1024, Timeout: 60, PackageType: config.PackageZip}
+
+	_, err := NewLambda(cfg)
+	assert.EqualError(err, "invalid secrets_mode invalid. Supported secrets modes are deploy and runtime")
+	assert.Equal(config.Lambda, cfg.Platform.Name)
+	assert.Equal(1024, cfg.Platform.Memory)
+	assert.Equal(60, cfg.Platform.Timeout)
+	assert.Equal("app.handler", cfg.Platform.Handler)
+}
+
+func TestLambdaConfigurationChanges(t *testing.T) {
+	assert := assert.New(t)
+	l := helperLambda(t, func(s *middleware.Stack) error { return nil })
+
+	live := &lambdaTypes.FunctionConfiguration{
+		MemorySize:  aws.Int32(1024),
+		Timeout:     aws.Int32(60),
+		Role:        aws.String("arn:aws:iam::123456789012:role/test"),
+		Description: aws.String("Jerm Deployment"),
+		Runtime:     lambdaTypes.Runtime("python3.11"),
+		Handler:     aws.String("handler.handler"),
+	}
+	assert.Nil(l.configurationChanges(live, map[string]string{}, nil))
+
+	live.MemorySize = aws.Int32(512)
+	live.Handler = aws.String("app.handler")
+	input := l.configurationChanges(live, map[string]string{}, nil)
+	assert.NotNil(input)
+	assert.Equal("test-dev", *input.FunctionName)
+	assert.Equal(int32(1024), *input.MemorySize)
+	assert.Equal("handler.handler", *input.Handler)
+	assert.Nil(input.Timeout)
+	assert.Nil(input.Role)
+	assert.Equal(lambdaTypes.Runtime(""), input.Runtime)
+
+	live.PackageType = lambdaTypes.PackageTypeImage
+	live.MemorySize = aws.Int32(1024)
+	live.Runtime = ""
+	assert.Nil(l.configurationChanges(live, map[string]string{}, nil))
+
+	env := map[string]string{"DEBUG": "false"}
+	input = l.configurationChanges(live, env, nil)
+	assert.NotNil(input)
+	assert.Equal(env, input.Environment.Variables)
+
+	live.Environment = &lambdaTypes.EnvironmentResponse{Variables: map[string]string{"DEBUG": "false"}}
+	assert.Nil(l.configurationChanges(live, env, nil))
+
+	input = l.configurationChanges(live, map[string]string{}, nil)
+	assert.NotNil(input)
+	assert.Empty(input.Environment.Variables)
+
+	vpc := &lambdaTypes.VpcConfig{SubnetIds: []string{"subnet-1", "subnet-2"}, SecurityGroupIds: []string{"sg-1"}}
+	input = l.configurationChanges(live, env, vpc)
+	assert.NotNil(input)
+	assert.Equal(vpc, input.VpcConfig)
+
+	live.VpcConfig = &lambdaTypes.VpcConfigResponse{SubnetIds: []string{"subnet-2", "subnet-1"}, SecurityGroupIds: []string{"sg-1"}}
+	assert.Nil(l.configurationChanges(live, env, vpc))
+
+	input = l.configurationChanges(live, env, nil)
+	assert.NotNil(input)
+	assert.Empty(input.VpcConfig.SubnetIds)
+	assert.Empty(input.VpcConfig.SecurityGroupIds)
+}
+
+func TestLambdaUpdateLambdaConfiguration(t *testing.T) {
+	assert := assert.New(t)
+	var updated *lambda.UpdateFunctionConfigurationInput
+	withAPIOptionsFunc, _ := mockApi(func(operation string, input interface{}) (interface{}, error) {
+		switch operation {
+		case "GetFunction":
+			return &lambda.GetFunctionOutput{Configuration: &lambdaTypes.FunctionConfiguration{
+				MemorySize:       aws.Int32(128),
+				Timeout:          aws.Int32(60),
+				Role:             aws.String("arn:aws:iam::123456789012:role/test"),
+				Description:      aws.String("Jerm Deployment"),
+				Runtime:          lambdaTypes.Runtime("python3.11"),
+				Handler:          aws.String("handler.handler"),
+				LastUpdateStatus: lambdaTypes.LastUpdateStatusSuccessful,
+			}}, nil
+		case "UpdateFunctionConfiguration":
+			updated = input.(*lambda.UpdateFunctionConfigurationInput)
+			return &lambda.UpdateFunctionConfigurationOutput{}, nil
+		}
+		return nil, errUnexpectedOperation
+	})
+	l := helperLambda(t, withAPIOptionsFunc)
+
+	err := l.updateLambdaConfiguration()
+	assert.Nil(err)
+	assert.NotNil(updated)
+	assert.Equal(int32(1024), *updated.MemorySize)
+	assert.Nil(updated.Timeout)
+}
+
+func TestLambdaUpdateLambdaFunction(t *testing.T) {
+	assert := assert.New(t)
+	withAPIOptionsFunc, recorder := mockApi(func(operation string, input interface{}) (interface{}, error) {
+		return &lambda.UpdateFunctionCodeOutput{FunctionArn: aws.String(testFunctionArn)}, nil
+	})
+	l := helperLambda(t, withAPIOptionsFunc)
+
+	arn, err := l.updateLambdaFunction(&lambdaTypes.FunctionCode{S3Bucket: aws.String("jerm-bucket"), S3Key: aws.String("test-dev/1.zip")})
+	assert.Nil(err)
+	assert.Equal(testFunctionArn, *arn)
+	input := recorder.inputs[0].(*lambda.UpdateFunctionCodeInput)
+	assert.Equal("jerm-bucket", *input.S3Bucket)
+	assert.Equal("test-dev/1.zip", *input.S3Key)
+	assert.Nil(input.ZipFile)
+}
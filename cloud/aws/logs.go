@@ -158,3 +158,26 @@ func (c *CloudWatch) Clear(name string) error {
 	err := c.deleteLogGroup(name)
 	return err
 }
+
+// ensureLogGroup creates a log group tagged with tags, or tags it if it exists
+func (c *CloudWatch) ensureLogGroup(name, arn string, tags map[string]string) error {
+	log.Debug(fmt.Sprintf("creating log group %s...", name))
+	_, err := c.client.CreateLogGroup(context.TODO(), &cloudwatchlogs.CreateLogGroupInput{
+		LogGroupName: aws.String(name),
+		Tags:         tags,
+	})
+	if err == nil {
+		return nil
+	}
+	var raeErr *cwTypes.ResourceAlreadyExistsException
+	if !errors.As(err, &raeErr) {
+		return err
+	}
+
+	log.Debug(fmt.Sprintf("tagging log group %s...", name))
+	_, err = c.client.TagResource(context.TODO(), &cloudwatchlogs.TagResourceInput{
+		ResourceArn: aws.String(arn),
+		Tags:        tags,
+	})
+	return err
+}
@@ -0,0 +1,57 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/spatocode/jerm/internal/log"
+)
+
+// Metrics is the AWS CloudWatch metrics operations
+type Metrics struct {
+	config *config.Config
+	client *cloudwatch.Client
+}
+
+// NewMetrics creates a new AWS Metrics object
+func NewMetrics(cfg *config.Config, awsConfig aws.Config) *Metrics {
+	return &Metrics{
+		config: cfg,
+		client: cloudwatch.NewFromConfig(awsConfig),
+	}
+}
+
+// functionErrors returns the number of errors of a version of the function
+// invoked through the stage alias since start
+func (m *Metrics) functionErrors(version string, start time.Time) (float64, error) {
+	log.Debug(fmt.Sprintf("fetching errors of lambda version %s...", version))
+	name := m.config.GetFunctionName()
+	resp, err := m.client.GetMetricStatistics(context.TODO(), &cloudwatch.GetMetricStatisticsInput{
+		Namespace:  aws.String("AWS/Lambda"),
+		MetricName: aws.String("Errors"),
+		Dimensions: []cwTypes.Dimension{
+			{Name: aws.String("FunctionName"), Value: aws.String(name)},
+			{Name: aws.String("Resource"), Value: aws.String(fmt.Sprintf("%s:%s", name, m.config.Stage))},
+			{Name: aws.String("ExecutedVersion"), Value: aws.String(version)},
+		},
+		StartTime:  aws.Time(start.Truncate(time.Minute)),
+		EndTime:    aws.Time(time.Now()),
+		Period:     aws.Int32(60),
+		Statistics: []cwTypes.Statistic{cwTypes.StatisticSum},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var errorCount float64
+	for _, datapoint := range resp.Datapoints {
+		errorCount += aws.ToFloat64(datapoint.Sum)
+	}
+	return errorCount, nil
+}
@@ -0,0 +1,32 @@
+package aws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsFunctionErrors(t *testing.T) {
+	assert := assert.New(t)
+	withAPIOptionsFunc, recorder := mockApi(func(operation string, input interface{}) (interface{}, error) {
+		return &cloudwatch.GetMetricStatisticsOutput{
+			Datapoints: []cwTypes.Datapoint{{Sum: aws.Float64(1)}, {Sum: aws.Float64(2)}},
+		}, nil
+	})
+	l := helperLambda(t, withAPIOptionsFunc)
+
+	errorCount, err := l.metrics.functionErrors("2", time.Now())
+	assert.Nil(err)
+	assert.Equal(float64(3), errorCount)
+	input := recorder.inputs[0].(*cloudwatch.GetMetricStatisticsInput)
+	assert.Equal("Errors", *input.MetricName)
+	assert.Equal([]cwTypes.Dimension{
+		{Name: aws.String("FunctionName"), Value: aws.String("test-dev")},
+		{Name: aws.String("Resource"), Value: aws.String("test-dev:dev")},
+		{Name: aws.String("ExecutedVersion"), Value: aws.String("2")},
+	}, input.Dimensions)
+}
@@ -0,0 +1,119 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+
+	"github.com/spatocode/jerm/internal/log"
+)
+
+// Prune deletes the function versions and deployment archives beyond the retention
+// of the project and returns them. Versions referenced by aliases are kept.
+// With dryRun nothing is deleted.
+func (l *Lambda) Prune(dryRun bool) ([]string, error) {
+	keep, err := l.config.GetRetainedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := l.prunableVersions(keep)
+	if err != nil {
+		return nil, err
+	}
+
+	var pruned []string
+	for _, version := range versions {
+		pruned = append(pruned, fmt.Sprintf("lambda version %s:%s", l.config.GetFunctionName(), version))
+		if dryRun {
+			continue
+		}
+		err = l.deleteLambdaVersion(version)
+		if err != nil {
+			return pruned, err
+		}
+	}
+
+	storage, ok := l.storage.(*S3)
+	if !ok {
+		return pruned, nil
+	}
+	archives, err := storage.listArchives()
+	if err != nil {
+		return pruned, err
+	}
+	if len(archives) <= keep {
+		return pruned, nil
+	}
+	for _, archive := range archives[keep:] {
+		key := aws.ToString(archive.Key)
+		pruned = append(pruned, fmt.Sprintf("s3 object s3://%s/%s", l.config.Bucket, key))
+		if dryRun {
+			continue
+		}
+		err = storage.Delete(key)
+		if err != nil {
+			return pruned, err
+		}
+	}
+	return pruned, nil
+}
+
+// prunableVersions lists the published versions beyond the keep latest ones
+// that aren't referenced by an alias
+func (l *Lambda) prunableVersions(keep int) ([]string, error) {
+	versions, err := l.Versions()
+	if err != nil {
+		return nil, err
+	}
+
+	aliased, err := l.aliasedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	var prunable []string
+	for idx, v := range versions {
+		if idx < keep || aliased[v.Version] {
+			continue
+		}
+		prunable = append(prunable, v.Version)
+	}
+	return prunable, nil
+}
+
+// aliasedVersions returns the versions the aliases of the function route traffic to
+func (l *Lambda) aliasedVersions() (map[string]bool, error) {
+	log.Debug("listing lambda aliases...")
+	versions := map[string]bool{}
+	paginator := lambda.NewListAliasesPaginator(l.client, &lambda.ListAliasesInput{
+		FunctionName: aws.String(l.config.GetFunctionName()),
+	})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, err
+		}
+		for _, alias := range resp.Aliases {
+			versions[aws.ToString(alias.FunctionVersion)] = true
+			if alias.RoutingConfig == nil {
+				continue
+			}
+			for version := range alias.RoutingConfig.AdditionalVersionWeights {
+				versions[version] = true
+			}
+		}
+	}
+	return versions, nil
+}
+
+func (l *Lambda) deleteLambdaVersion(version string) error {
+	log.Debug(fmt.Sprintf("deleting lambda version %s...", version))
+	_, err := l.client.DeleteFunction(context.TODO(), &lambda.DeleteFunctionInput{
+		FunctionName: aws.String(l.config.GetFunctionName()),
+		Qualifier:    aws.String(version),
+	})
+	return err
+}
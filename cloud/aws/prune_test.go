@@ -0,0 +1,107 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/spatocode/jerm/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLambdaPrune(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Now()
+
+	cases := []struct {
+		name    string
+		dryRun  bool
+		pruned  []string
+		deleted []string
+	}{
+		{
+			name:   "list prunable resources",
+			dryRun: true,
+			pruned: []string{
+				"lambda version test-dev:4",
+				"lambda version test-dev:1",
+				"s3 object s3://jerm-bucket/test-dev/1.zip",
+			},
+		},
+		{
+			name: "prune resources",
+			pruned: []string{
+				"lambda version test-dev:4",
+				"lambda version test-dev:1",
+				"s3 object s3://jerm-bucket/test-dev/1.zip",
+			},
+			deleted: []string{"4", "1", "test-dev/1.zip"},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			withAPIOptionsFunc, recorder := mockApi(func(operation string, input interface{}) (interface{}, error) {
+				switch operation {
+				case "ListVersionsByFunction":
+					output := &lambda.ListVersionsByFunctionOutput{Versions: []lambdaTypes.FunctionConfiguration{{Version: aws.String("$LATEST")}}}
+					for version := 1; version <= 6; version++ {
+						output.Versions = append(output.Versions, lambdaTypes.FunctionConfiguration{Version: aws.String(fmt.Sprint(version))})
+					}
+					return output, nil
+				case "GetAlias":
+					return &lambda.GetAliasOutput{FunctionVersion: aws.String("6")}, nil
+				case "ListAliases":
+					return &lambda.ListAliasesOutput{Aliases: []lambdaTypes.AliasConfiguration{
+						{Name: aws.String("dev"), FunctionVersion: aws.String("6")},
+						{Name: aws.String("prod"), FunctionVersion: aws.String("2"), RoutingConfig: &lambdaTypes.AliasRoutingConfiguration{
+							AdditionalVersionWeights: map[string]float64{"3": 0.1},
+						}},
+					}}, nil
+				case "DeleteFunction":
+					return &lambda.DeleteFunctionOutput{}, nil
+				case "ListObjectsV2":
+					var objects []s3Types.Object
+					for _, object := range []s3Types.Object{
+						{Key: aws.String("test-dev/2.zip"), LastModified: aws.Time(now.Add(-time.Hour))},
+						{Key: aws.String("static/index.html"), LastModified: aws.Time(now.Add(-2 * time.Hour))},
+						{Key: aws.String("test-dev/3.zip"), LastModified: aws.Time(now)},
+						{Key: aws.String("test-dev/1.zip"), LastModified: aws.Time(now.Add(-2 * time.Hour))},
+						{Key: aws.String("test-dev-worker/1.zip"), LastModified: aws.Time(now.Add(-3 * time.Hour))},
+					} {
+						if strings.HasPrefix(*object.Key, *input.(*s3.ListObjectsV2Input).Prefix) {
+							objects = append(objects, object)
+						}
+					}
+					return &s3.ListObjectsV2Output{Contents: objects}, nil
+				case "DeleteObject":
+					return &s3.DeleteObjectOutput{}, nil
+				}
+				return nil, errUnexpectedOperation
+			})
+			l := helperLambda(t, withAPIOptionsFunc)
+			l.config.Retention = &config.Retention{Versions: 2}
+
+			pruned, err := l.Prune(tt.dryRun)
+			assert.Nil(err)
+			assert.Equal(tt.pruned, pruned)
+
+			var deleted []string
+			for _, input := range recorder.inputs {
+				switch input := input.(type) {
+				case *lambda.DeleteFunctionInput:
+					deleted = append(deleted, *input.Qualifier)
+				case *s3.DeleteObjectInput:
+					deleted = append(deleted, *input.Key)
+				}
+			}
+			assert.Equal(tt.deleted, deleted)
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cwTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	taggingTypes "github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/spatocode/jerm/internal/log"
+)
+
+// deployRoutes creates or updates the functions of the routes from the code of the
+// deployment and returns the routes of the REST API. Route functions aren't
+// versioned; the API invokes their latest code.
+func (l *Lambda) deployRoutes() ([]apiRoute, error) {
+	routes, err := l.config.GetRoutes()
+	if err != nil {
+		return nil, err
+	}
+
+	var apiRoutes []apiRoute
+	for _, name := range l.config.RouteNames() {
+		route := routes[name]
+		functionArn, err := l.deployRoute(name, route)
+		if err != nil {
+			return nil, fmt.Errorf("route %s: %w", name, err)
+		}
+		apiRoutes = append(apiRoutes, apiRoute{
+			path:        route.Path,
+			methods:     route.Methods,
+			functionArn: functionArn,
+		})
+	}
+
+	return apiRoutes, nil
+}
+
+// removeStaleRoutes deletes the functions of the routes removed from the config.
+// It runs once the API no longer serves them.
+func (l *Lambda) removeStaleRoutes() error {
+	deployed, err := l.routeFunctions()
+	if err != nil {
+		return err
+	}
+	for name, function := range deployed {
+		if _, ok := l.config.Routes[name]; ok {
+			continue
+		}
+		log.Debug(fmt.Sprintf("deleting function of removed route %s...", name))
+		err = l.deleteRouteFunction(function)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deployRoute creates the function of a route or updates its code and configuration.
+// It returns the ARN of the function.
+func (l *Lambda) deployRoute(name string, route config.Route) (string, error) {
+	if l.code == nil {
+		return "", errors.New("can't find the code of the deployment")
+	}
+	function := l.config.RouteFunctionName(name)
+
+	env, err := l.environment()
+	if err != nil {
+		return "", err
+	}
+	vpc, err := l.vpc.vpcConfig()
+	if err != nil {
+		return "", err
+	}
+	memory, timeout := l.routeSize(route)
+
+	live, err := l.getLambdaFunction(function)
+	if err != nil {
+		var rnfErr *lambdaTypes.ResourceNotFoundException
+		if !errors.As(err, &rnfErr) {
+			return "", err
+		}
+
+		tags, err := resourceTags(l.config)
+		if err != nil {
+			return "", err
+		}
+		tags[config.TagRoute] = name
+
+		log.Debug(fmt.Sprintf("creating lambda function %s...", function))
+		input := &lambda.CreateFunctionInput{
+			Code:         l.code,
+			FunctionName: aws.String(function),
+			Description:  aws.String(l.description),
+			Role:         &l.config.Platform.Role,
+			Timeout:      aws.Int32(int32(timeout)),
+			MemorySize:   aws.Int32(int32(memory)),
+			Environment:  &lambdaTypes.Environment{Variables: env},
+			VpcConfig:    vpc,
+			Tags:         tags,
+		}
+		if l.code.ImageUri != nil {
+			input.PackageType = lambdaTypes.PackageTypeImage
+			if route.Handler != "" {
+				input.ImageConfig = &lambdaTypes.ImageConfig{Command: []string{route.Handler}}
+			}
+		} else {
+			input.Runtime = lambdaTypes.Runtime(l.config.Platform.Runtime)
+			input.Handler = aws.String(l.routeHandler(route))
+		}
+		resp, err := l.client.CreateFunction(context.TODO(), input)
+		if err != nil {
+			return "", err
+		}
+		err = lambda.NewFunctionActiveV2Waiter(l.client).Wait(context.TODO(), &lambda.GetFunctionInput{
+			FunctionName: aws.String(function),
+		}, time.Second*l.maxWaiterDuration)
+		return aws.ToString(resp.FunctionArn), err
+	}
+
+	log.Debug(fmt.Sprintf("updating lambda function code of %s...", function))
+	_, err = l.client.UpdateFunctionCode(context.TODO(), &lambda.UpdateFunctionCodeInput{
+		FunctionName: aws.String(function),
+		S3Bucket:     l.code.S3Bucket,
+		S3Key:        l.code.S3Key,
+		ImageUri:     l.code.ImageUri,
+	})
+	if err != nil {
+		return "", err
+	}
+	err = l.waitForRouteUpdate(function)
+	if err != nil {
+		return "", err
+	}
+
+	input := l.functionConfigurationChanges(function, memory, timeout, live.Configuration, env, vpc)
+	if live.Configuration.PackageType != lambdaTypes.PackageTypeImage {
+		handler := l.routeHandler(route)
+		if aws.ToString(live.Configuration.Handler) != handler {
+			if input == nil {
+				input = &lambda.UpdateFunctionConfigurationInput{FunctionName: aws.String(function)}
+			}
+			input.Handler = aws.String(handler)
+		}
+	}
+	if input != nil {
+		log.Debug(fmt.Sprintf("updating lambda function configuration of %s...", function))
+		_, err = l.client.UpdateFunctionConfiguration(context.TODO(), input)
+		if err != nil {
+			return "", err
+		}
+		err = l.waitForRouteUpdate(function)
+		if err != nil {
+			return "", err
+		}
+	}
+	return aws.ToString(live.Configuration.FunctionArn), nil
+}
+
+// routeSize returns the memory and timeout of the function of a route.
+// They default to the ones of the project.
+func (l *Lambda) routeSize(route config.Route) (int, int) {
+	memory, timeout := l.config.Platform.Memory, l.config.Platform.Timeout
+	if route.Memory != 0 {
+		memory = route.Memory
+	}
+	if route.Timeout != 0 {
+		timeout = route.Timeout
+	}
+	return memory, timeout
+}
+
+// routeHandler returns the handler of the function of a route
+func (l *Lambda) routeHandler(route config.Route) string {
+	if route.Handler != "" {
+		return route.Handler
+	}
+	return l.functionHandler
+}
+
+// waitForRouteUpdate waits for the update of the function of a route to complete
+func (l *Lambda) waitForRouteUpdate(function string) error {
+	return lambda.NewFunctionUpdatedV2Waiter(l.client).Wait(context.TODO(), &lambda.GetFunctionInput{
+		FunctionName: aws.String(function),
+	}, time.Second*l.maxWaiterDuration)
+}
+
+// routeFunctions returns the names of the deployed functions of the routes by route
+// from the tags of the functions
+func (l *Lambda) routeFunctions() (map[string]string, error) {
+	functions := map[string]string{}
+	paginator := resourcegroupstaggingapi.NewGetResourcesPaginator(l.inventory.client, &resourcegroupstaggingapi.GetResourcesInput{
+		ResourceTypeFilters: []string{"lambda:function"},
+		TagFilters: []taggingTypes.TagFilter{
+			{Key: aws.String(config.TagProject), Values: []string{l.config.Name}},
+			{Key: aws.String(config.TagStage), Values: []string{l.config.Stage}},
+			{Key: aws.String(config.TagRoute)},
+		},
+	})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, err
+		}
+		for _, mapping := range resp.ResourceTagMappingList {
+			for _, tag := range mapping.Tags {
+				if aws.ToString(tag.Key) == config.TagRoute {
+					// function ARNs are in the form arn:aws:lambda:<region>:<account>:function:<name>
+					parts := strings.Split(aws.ToString(mapping.ResourceARN), ":")
+					functions[aws.ToString(tag.Value)] = parts[len(parts)-1]
+				}
+			}
+		}
+	}
+	return functions, nil
+}
+
+// deleteRouteFunction deletes the function of a route with its log group
+func (l *Lambda) deleteRouteFunction(name string) error {
+	_, err := l.client.DeleteFunction(context.TODO(), &lambda.DeleteFunctionInput{
+		FunctionName: aws.String(name),
+	})
+	var rnfErr *lambdaTypes.ResourceNotFoundException
+	if err != nil && !errors.As(err, &rnfErr) {
+		return err
+	}
+
+	err = l.monitor.Clear(fmt.Sprintf("/aws/lambda/%s", name))
+	var cwRnfErr *cwTypes.ResourceNotFoundException
+	if err != nil && !errors.As(err, &cwRnfErr) {
+		return err
+	}
+	return nil
+}
+
+// routeTeardownSteps lists the functions of the routes
+func (l *Lambda) routeTeardownSteps() ([]teardownStep, error) {
+	functions, err := l.routeFunctions()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for name := range functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var steps []teardownStep
+	for _, name := range names {
+		function := functions[name]
+		steps = append(steps, teardownStep{fmt.Sprintf("lambda function %s of route %s", function, name), func() error {
+			return l.deleteRouteFunction(function)
+		}})
+	}
+	return steps, nil
+}
@@ -0,0 +1,144 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	taggingTypes "github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
+	"github.com/spatocode/jerm/config"
+	"github.com/stretchr/testify/assert"
+)
+
+var testRouteFunctions = map[string]*lambdaTypes.FunctionConfiguration{
+	"test-dev-new": {
+		FunctionArn:      aws.String("arn:aws:lambda:us-west-1:123456789012:function:test-dev-new"),
+		MemorySize:       aws.Int32(1024),
+		Timeout:          aws.Int32(60),
+		Role:             aws.String("arn:aws:iam::123456789012:role/test"),
+		Description:      aws.String("Jerm Deployment"),
+		Runtime:          lambdaTypes.Runtime("python3.11"),
+		Handler:          aws.String("handler.handler"),
+		LastUpdateStatus: lambdaTypes.LastUpdateStatusSuccessful,
+	},
+	"test-dev-old": {FunctionArn: aws.String("arn:aws:lambda:us-west-1:123456789012:function:test-dev-old")},
+}
+
+func TestLambdaDeployRoutes(t *testing.T) {
+	assert := assert.New(t)
+	code := &lambdaTypes.FunctionCode{S3Bucket: aws.String("jerm-bucket"), S3Key: aws.String("test-dev.zip")}
+
+	cases := []struct {
+		name       string
+		deployed   map[string]*lambdaTypes.FunctionConfiguration
+		route      config.Route
+		routes     []apiRoute
+		operations []string
+		check      func(inputs []interface{})
+	}{
+		{
+			name:       "create route function",
+			deployed:   map[string]*lambdaTypes.FunctionConfiguration{},
+			route:      config.Route{Path: "/reports", Methods: []string{"post"}, Handler: "reports.handler", Memory: 2048, Timeout: 300},
+			routes:     []apiRoute{{path: "/reports", methods: []string{"POST"}, functionArn: "arn:aws:lambda:us-west-1:123456789012:function:test-dev-new"}},
+			operations: []string{"GetFunction", "CreateFunction", "GetFunction"},
+			check: func(inputs []interface{}) {
+				create := inputs[1].(*lambda.CreateFunctionInput)
+				assert.Equal("test-dev-new", *create.FunctionName)
+				assert.Equal("reports.handler", *create.Handler)
+				assert.Equal(int32(2048), *create.MemorySize)
+				assert.Equal(int32(300), *create.Timeout)
+				assert.Equal("new", create.Tags[config.TagRoute])
+				assert.Equal(code, create.Code)
+			},
+		},
+		{
+			name:       "update route function",
+			deployed:   testRouteFunctions,
+			route:      config.Route{Path: "/reports", Handler: "reports.handler"},
+			routes:     []apiRoute{{path: "/reports", methods: []string{"ANY"}, functionArn: "arn:aws:lambda:us-west-1:123456789012:function:test-dev-new"}},
+			operations: []string{"GetFunction", "UpdateFunctionCode", "GetFunction", "UpdateFunctionConfiguration", "GetFunction"},
+			check: func(inputs []interface{}) {
+				update := inputs[1].(*lambda.UpdateFunctionCodeInput)
+				assert.Equal("jerm-bucket", *update.S3Bucket)
+				assert.Equal("test-dev.zip", *update.S3Key)
+				configuration := inputs[3].(*lambda.UpdateFunctionConfigurationInput)
+				assert.Equal("reports.handler", *configuration.Handler)
+				assert.Nil(configuration.MemorySize)
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			deployed := map[string]*lambdaTypes.FunctionConfiguration{}
+			for name, function := range tt.deployed {
+				deployed[name] = function
+			}
+			withAPIOptionsFunc, recorder := mockApi(func(operation string, input interface{}) (interface{}, error) {
+				switch operation {
+				case "GetFunction":
+					function, ok := deployed[*input.(*lambda.GetFunctionInput).FunctionName]
+					if !ok {
+						return nil, &lambdaTypes.ResourceNotFoundException{}
+					}
+					return &lambda.GetFunctionOutput{Configuration: function}, nil
+				case "CreateFunction":
+					name := *input.(*lambda.CreateFunctionInput).FunctionName
+					deployed[name] = &lambdaTypes.FunctionConfiguration{
+						FunctionArn: aws.String("arn:aws:lambda:us-west-1:123456789012:function:" + name),
+						State:       lambdaTypes.StateActive,
+					}
+					return &lambda.CreateFunctionOutput{FunctionArn: deployed[name].FunctionArn}, nil
+				case "UpdateFunctionCode":
+					return &lambda.UpdateFunctionCodeOutput{}, nil
+				case "UpdateFunctionConfiguration":
+					return &lambda.UpdateFunctionConfigurationOutput{}, nil
+				}
+				return nil, errUnexpectedOperation
+			})
+			l := helperLambda(t, withAPIOptionsFunc)
+			l.code = code
+			l.config.Routes = map[string]config.Route{"new": tt.route}
+
+			routes, err := l.deployRoutes()
+			assert.Nil(err)
+			assert.Equal(tt.routes, routes)
+			assert.Equal(tt.operations, recorder.operations)
+			tt.check(recorder.inputs)
+		})
+	}
+}
+
+func TestLambdaRemoveStaleRoutes(t *testing.T) {
+	assert := assert.New(t)
+	withAPIOptionsFunc, recorder := mockApi(func(operation string, input interface{}) (interface{}, error) {
+		switch operation {
+		case "GetResources":
+			var mappings []taggingTypes.ResourceTagMapping
+			for name, function := range testRouteFunctions {
+				mappings = append(mappings, taggingTypes.ResourceTagMapping{
+					ResourceARN: function.FunctionArn,
+					Tags:        []taggingTypes.Tag{{Key: aws.String(config.TagRoute), Value: aws.String(strings.TrimPrefix(name, "test-dev-"))}},
+				})
+			}
+			return &resourcegroupstaggingapi.GetResourcesOutput{ResourceTagMappingList: mappings}, nil
+		case "DeleteFunction":
+			return &lambda.DeleteFunctionOutput{}, nil
+		case "DeleteLogGroup":
+			return &cloudwatchlogs.DeleteLogGroupOutput{}, nil
+		}
+		return nil, errUnexpectedOperation
+	})
+	l := helperLambda(t, withAPIOptionsFunc)
+	l.config.Routes = map[string]config.Route{"new": {Path: "/reports", Handler: "reports.handler"}}
+
+	err := l.removeStaleRoutes()
+	assert.Nil(err)
+	assert.Equal([]string{"GetResources", "DeleteFunction", "DeleteLogGroup"}, recorder.operations)
+	assert.Equal("test-dev-old", *recorder.inputs[1].(*lambda.DeleteFunctionInput).FunctionName)
+}
@@ -0,0 +1,130 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmTypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/spatocode/jerm/internal/log"
+)
+
+// Secrets is the AWS SSM Parameter Store and Secrets Manager operations
+type Secrets struct {
+	config         *config.Config
+	ssm            *ssm.Client
+	secretsManager *secretsmanager.Client
+}
+
+// NewSecrets creates a new AWS Secrets object
+func NewSecrets(cfg *config.Config, awsConfig aws.Config) *Secrets {
+	return &Secrets{
+		config:         cfg,
+		ssm:            ssm.NewFromConfig(awsConfig),
+		secretsManager: secretsmanager.NewFromConfig(awsConfig),
+	}
+}
+
+// resolve replaces the secret references in the environment with their values
+func (s *Secrets) resolve(env map[string]string) (map[string]string, error) {
+	resolved := make(map[string]string, len(env))
+	for k, v := range env {
+		reference, ok := config.ParseSecretReference(v)
+		if !ok {
+			resolved[k] = v
+			continue
+		}
+
+		value, err := s.getSecretValue(reference)
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve %s of environment variable %s: %s", reference, k, err)
+		}
+		resolved[k] = value
+	}
+	return resolved, nil
+}
+
+// getSecretValue fetches the value of a secret reference
+func (s *Secrets) getSecretValue(reference config.SecretReference) (string, error) {
+	log.Debug(fmt.Sprintf("fetching secret %s...", reference))
+	if reference.Store == config.SecretsManager {
+		resp, err := s.secretsManager.GetSecretValue(context.TODO(), &secretsmanager.GetSecretValueInput{
+			SecretId: aws.String(reference.Name),
+		})
+		if err != nil {
+			return "", err
+		}
+		return aws.ToString(resp.SecretString), nil
+	}
+	return s.getParameter(reference.Name)
+}
+
+// getParameter fetches the decrypted value of an SSM parameter
+func (s *Secrets) getParameter(name string) (string, error) {
+	resp, err := s.ssm.GetParameter(context.TODO(), &ssm.GetParameterInput{
+		Name:           aws.String(name),
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(resp.Parameter.Value), nil
+}
+
+// parameterName returns the SSM parameter name of a secret of the project stage
+func (s *Secrets) parameterName(key string) string {
+	return fmt.Sprintf("%s/%s", s.config.GetSecretsPath(), key)
+}
+
+// set stores a secret of the project stage as an encrypted SSM parameter
+func (s *Secrets) set(key, value string) error {
+	log.Debug(fmt.Sprintf("storing secret %s...", key))
+	_, err := s.ssm.PutParameter(context.TODO(), &ssm.PutParameterInput{
+		Name:      aws.String(s.parameterName(key)),
+		Value:     aws.String(value),
+		Type:      ssmTypes.ParameterTypeSecureString,
+		Overwrite: aws.Bool(true),
+	})
+	return err
+}
+
+// get fetches a secret of the project stage
+func (s *Secrets) get(key string) (string, error) {
+	value, err := s.getParameter(s.parameterName(key))
+	if err != nil {
+		var pnfErr *ssmTypes.ParameterNotFound
+		if errors.As(err, &pnfErr) {
+			return "", fmt.Errorf("can't find secret %s", key)
+		}
+		return "", err
+	}
+	return value, nil
+}
+
+// list lists the secret keys of the project stage
+func (s *Secrets) list() ([]string, error) {
+	log.Debug("listing secrets...")
+	var keys []string
+	path := s.config.GetSecretsPath()
+	paginator := ssm.NewGetParametersByPathPaginator(s.ssm, &ssm.GetParametersByPathInput{
+		Path: aws.String(path),
+	})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, err
+		}
+		for _, parameter := range resp.Parameters {
+			keys = append(keys, strings.TrimPrefix(aws.ToString(parameter.Name), path+"/"))
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
@@ -0,0 +1,110 @@
+package aws
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmTypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSecrets(t *testing.T) {
+	assert := assert.New(t)
+	cfg := &config.Config{}
+	s := NewSecrets(cfg, aws.Config{})
+	assert.Equal(cfg, s.config)
+	assert.NotNil(s.ssm)
+	assert.NotNil(s.secretsManager)
+}
+
+func TestSecretsResolve(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		name        string
+		environment map[string]string
+		mock        func(operation string, input interface{}) (interface{}, error)
+		env         map[string]string
+		want        error
+		wantErr     bool
+	}{
+		{
+			name: "resolve secrets",
+			environment: map[string]string{
+				"DEBUG":       "true",
+				"DB_PASSWORD": "ssm:/myapp/db_password",
+				"DB":          "secretsmanager:prod/db",
+			},
+			mock: func(operation string, input interface{}) (interface{}, error) {
+				switch operation {
+				case "GetParameter":
+					return &ssm.GetParameterOutput{Parameter: &ssmTypes.Parameter{Value: aws.String("p4ssw0rd")}}, nil
+				case "GetSecretValue":
+					return &secretsmanager.GetSecretValueOutput{SecretString: aws.String(`{"user":"admin"}`)}, nil
+				}
+				return nil, errUnexpectedOperation
+			},
+			env: map[string]string{
+				"DEBUG":       "true",
+				"DB_PASSWORD": "p4ssw0rd",
+				"DB":          `{"user":"admin"}`,
+			},
+		},
+		{
+			name:        "resolve secrets error",
+			environment: map[string]string{"DB_PASSWORD": "ssm:/myapp/db_password"},
+			mock: func(operation string, input interface{}) (interface{}, error) {
+				return nil, fmt.Errorf("GetParameterError")
+			},
+			want:    fmt.Errorf("unable to resolve ssm:/myapp/db_password of environment variable DB_PASSWORD: operation error SSM: GetParameter, GetParameterError"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			withAPIOptionsFunc, _ := mockApi(tt.mock)
+			l := helperLambda(t, withAPIOptionsFunc)
+
+			env, err := l.secrets.resolve(tt.environment)
+			if tt.wantErr {
+				assert.EqualError(err, tt.want.Error())
+				return
+			}
+			assert.Nil(err)
+			assert.Equal(tt.env, env)
+		})
+	}
+}
+
+func TestSecretsSetAndList(t *testing.T) {
+	assert := assert.New(t)
+	withAPIOptionsFunc, recorder := mockApi(func(operation string, input interface{}) (interface{}, error) {
+		switch operation {
+		case "PutParameter":
+			return &ssm.PutParameterOutput{}, nil
+		case "GetParametersByPath":
+			return &ssm.GetParametersByPathOutput{Parameters: []ssmTypes.Parameter{
+				{Name: aws.String("/jerm/test/dev/TOKEN")},
+				{Name: aws.String("/jerm/test/dev/DB_PASSWORD")},
+			}}, nil
+		}
+		return nil, errUnexpectedOperation
+	})
+	l := helperLambda(t, withAPIOptionsFunc)
+
+	err := l.secrets.set("DB_PASSWORD", "p4ssw0rd")
+	assert.Nil(err)
+	put := recorder.inputs[0].(*ssm.PutParameterInput)
+	assert.Equal("/jerm/test/dev/DB_PASSWORD", *put.Name)
+	assert.Equal(ssmTypes.ParameterTypeSecureString, put.Type)
+
+	keys, err := l.secrets.list()
+	assert.Nil(err)
+	assert.Equal([]string{"DB_PASSWORD", "TOKEN"}, keys)
+}
@@ -6,10 +6,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	s3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
 
 	"github.com/spatocode/jerm/config"
 	"github.com/spatocode/jerm/internal/log"
@@ -32,6 +37,23 @@ func NewS3(config *config.Config, awsConfig aws.Config) *S3 {
 
 // upload a file to AWS S3 bucket
 func (s *S3) Upload(filePath string) error {
+	return s.uploadObject(filepath.Base(filePath), filePath)
+}
+
+// uploadArchive uploads a deployment archive under a key of its own and returns the key.
+// Archives are kept in the bucket until they are pruned.
+func (s *S3) uploadArchive(zipPath string) (string, error) {
+	key := fmt.Sprintf("%s%d.zip", s.archivePrefix(), time.Now().UnixNano())
+	return key, s.uploadObject(key, zipPath)
+}
+
+// archivePrefix is the key prefix of the deployment archives of the function
+func (s *S3) archivePrefix() string {
+	return fmt.Sprintf("%s/", s.config.GetFunctionName())
+}
+
+// uploadObject uploads a file to the bucket under key
+func (s *S3) uploadObject(key, filePath string) error {
 	f, err := os.Stat(filePath)
 	if err != nil || f.Size() == 0 {
 		msg := "encountered issue with packaged file"
@@ -44,11 +66,10 @@ func (s *S3) Upload(filePath string) error {
 	}
 	defer file.Close()
 
-	fileName := filepath.Base(filePath)
-	log.Debug(fmt.Sprintf("uploading file %s...", fileName))
+	log.Debug(fmt.Sprintf("uploading file %s...", key))
 	_, err = s.client.PutObject(context.TODO(), &s3.PutObjectInput{
 		Bucket: aws.String(s.config.Bucket),
-		Key:    aws.String(fileName),
+		Key:    aws.String(key),
 		Body:   file,
 	})
 	if err != nil {
@@ -84,19 +105,169 @@ func (s *S3) Delete(filePath string) error {
 	return nil
 }
 
-// CreateBucket creates an AWS S3 bucket
+// CreateBucket creates an AWS S3 bucket tagged with the tags of the project
 func (s *S3) CreateBucket(isConfig bool) error {
 	log.Debug(fmt.Sprintf("creating s3 bucket with config %t...", isConfig))
+	input := &s3.CreateBucketInput{
+		Bucket: aws.String(s.config.Bucket),
+	}
 	if isConfig {
-		_, err := s.client.CreateBucket(context.TODO(), &s3.CreateBucketInput{
-			Bucket: aws.String(s.config.Bucket),
-			CreateBucketConfiguration: &s3Types.CreateBucketConfiguration{
-				LocationConstraint: s3Types.BucketLocationConstraint(s.awsConfig.Region),
-			},
-		})
+		input.CreateBucketConfiguration = &s3Types.CreateBucketConfiguration{
+			LocationConstraint: s3Types.BucketLocationConstraint(s.awsConfig.Region),
+		}
+	}
+	_, err := s.client.CreateBucket(context.TODO(), input)
+	if err != nil {
+		return err
+	}
+	return s.tagBucket()
+}
+
+// tagBucket tags the bucket with the tags of the project. Only buckets
+// created by jerm are tagged since tagging replaces the tags of a bucket.
+func (s *S3) tagBucket() error {
+	tags, err := resourceTags(s.config)
+	if err != nil {
 		return err
 	}
-	_, err := s.client.CreateBucket(context.TODO(), &s3.CreateBucketInput{
+	var tagSet []s3Types.Tag
+	for _, key := range sortedTagKeys(tags) {
+		tagSet = append(tagSet, s3Types.Tag{Key: aws.String(key), Value: aws.String(tags[key])})
+	}
+
+	log.Debug(fmt.Sprintf("tagging s3 bucket %s...", s.config.Bucket))
+	_, err = s.client.PutBucketTagging(context.TODO(), &s3.PutBucketTaggingInput{
+		Bucket:  aws.String(s.config.Bucket),
+		Tagging: &s3Types.Tagging{TagSet: tagSet},
+	})
+	return err
+}
+
+// putLambdaNotifications replaces the Lambda notifications of a bucket whose IDs
+// match ids. The rest of the notification configuration is kept as is.
+func (s *S3) putLambdaNotifications(bucket string, ids *regexp.Regexp, notifications []s3Types.LambdaFunctionConfiguration) error {
+	log.Debug(fmt.Sprintf("fetching notification configuration of s3 bucket %s...", bucket))
+	current, err := s.client.GetBucketNotificationConfiguration(context.TODO(), &s3.GetBucketNotificationConfigurationInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		return err
+	}
+
+	var lambdas []s3Types.LambdaFunctionConfiguration
+	for _, notification := range current.LambdaFunctionConfigurations {
+		if !ids.MatchString(aws.ToString(notification.Id)) {
+			lambdas = append(lambdas, notification)
+		}
+	}
+	lambdas = append(lambdas, notifications...)
+
+	log.Debug(fmt.Sprintf("updating notification configuration of s3 bucket %s...", bucket))
+	_, err = s.client.PutBucketNotificationConfiguration(context.TODO(), &s3.PutBucketNotificationConfigurationInput{
+		Bucket: aws.String(bucket),
+		NotificationConfiguration: &s3Types.NotificationConfiguration{
+			EventBridgeConfiguration:     current.EventBridgeConfiguration,
+			LambdaFunctionConfigurations: lambdas,
+			QueueConfigurations:          current.QueueConfigurations,
+			TopicConfigurations:          current.TopicConfigurations,
+		},
+	})
+	return err
+}
+
+// listArchives lists the deployment archives of the function, newest first
+func (s *S3) listArchives() ([]s3Types.Object, error) {
+	log.Debug(fmt.Sprintf("listing archives of s3 bucket %s...", s.config.Bucket))
+	var archives []s3Types.Object
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.config.Bucket),
+		Prefix: aws.String(s.archivePrefix()),
+	})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			var nsbErr *s3Types.NoSuchBucket
+			if errors.As(err, &nsbErr) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		for _, object := range resp.Contents {
+			if strings.HasSuffix(aws.ToString(object.Key), ".zip") {
+				archives = append(archives, object)
+			}
+		}
+	}
+	sort.Slice(archives, func(i, j int) bool {
+		return aws.ToTime(archives[i].LastModified).After(aws.ToTime(archives[j].LastModified))
+	})
+	return archives, nil
+}
+
+// ownsBucket reports whether the bucket was created for the stage of the project,
+// which jerm tells by the project and stage tags of the bucket
+func (s *S3) ownsBucket() (bool, error) {
+	log.Debug(fmt.Sprintf("fetching tags of s3 bucket %s...", s.config.Bucket))
+	resp, err := s.client.GetBucketTagging(context.TODO(), &s3.GetBucketTaggingInput{
+		Bucket: aws.String(s.config.Bucket),
+	})
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchTagSet" {
+			return false, nil
+		}
+		return false, err
+	}
+
+	tags := map[string]string{}
+	for _, tag := range resp.TagSet {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return tags[config.TagProject] == s.config.Name && tags[config.TagStage] == s.config.Stage, nil
+}
+
+// deleteObjects deletes the deployment archives and templates of the function
+func (s *S3) deleteObjects() error {
+	archives, err := s.listArchives()
+	if err != nil {
+		return err
+	}
+	for _, archive := range archives {
+		err = s.Delete(aws.ToString(archive.Key))
+		if err != nil {
+			return err
+		}
+	}
+
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.config.Bucket),
+		Prefix: aws.String(fmt.Sprintf("%s-template-", s.config.GetFunctionName())),
+	})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return err
+		}
+		for _, object := range resp.Contents {
+			err = s.Delete(aws.ToString(object.Key))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// deleteBucket deletes the deployment archives and templates of the function and the bucket.
+// A bucket holding other objects isn't deleted.
+func (s *S3) deleteBucket() error {
+	err := s.deleteObjects()
+	if err != nil {
+		return err
+	}
+
+	log.Debug(fmt.Sprintf("deleting s3 bucket %s...", s.config.Bucket))
+	_, err = s.client.DeleteBucket(context.TODO(), &s3.DeleteBucketInput{
 		Bucket: aws.String(s.config.Bucket),
 	})
 	return err
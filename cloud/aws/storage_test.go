@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awsMiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
 	awsConfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 
@@ -255,6 +256,19 @@ func TestS3Upload(t *testing.T) {
 	}
 }
 
+func TestS3UploadArchive(t *testing.T) {
+	assert := assert.New(t)
+	withAPIOptionsFunc, recorder := mockApi(func(operation string, input interface{}) (interface{}, error) {
+		return &s3.PutObjectOutput{}, nil
+	})
+	s3Client := NewS3(&config.Config{Name: "test", Stage: "dev", Bucket: "testbucket"}, mockAwsConfig(t, withAPIOptionsFunc))
+
+	key, err := s3Client.uploadArchive("../../assets/tests/testfile2")
+	assert.Nil(err)
+	assert.Regexp(`^test-dev/\d+\.zip$`, key)
+	assert.Equal(key, *recorder.inputs[0].(*s3.PutObjectInput).Key)
+}
+
 func TestS3CreateBucket(t *testing.T) {
 	assert := assert.New(t)
 
@@ -287,6 +301,11 @@ func TestS3CreateBucket(t *testing.T) {
 						middleware.FinalizeMiddlewareFunc(
 							"CreateBucketMock",
 							func(ctx context.Context, fi middleware.FinalizeInput, fh middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+								if awsMiddleware.GetOperationName(ctx) == "PutBucketTagging" {
+									return middleware.FinalizeOutput{
+										Result: &s3.PutBucketTaggingOutput{},
+									}, middleware.Metadata{}, nil
+								}
 								return middleware.FinalizeOutput{
 									Result: &s3.CreateBucketOutput{},
 								}, middleware.Metadata{}, nil
@@ -0,0 +1,160 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	taggingTypes "github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
+
+	"github.com/spatocode/jerm"
+	"github.com/spatocode/jerm/config"
+	"github.com/spatocode/jerm/internal/log"
+)
+
+// resourceTags returns the tags of the resources jerm creates for the project
+func resourceTags(cfg *config.Config) (map[string]string, error) {
+	tags, err := cfg.GetTags()
+	if err != nil {
+		return nil, err
+	}
+	tags[config.TagVersion] = jerm.Version
+	return tags, nil
+}
+
+// sortedTagKeys returns the keys of tags in order so that tag lists are stable
+func sortedTagKeys(tags map[string]string) []string {
+	var keys []string
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Inventory is the AWS Resource Groups Tagging API operations
+type Inventory struct {
+	config *config.Config
+	region string
+	client *resourcegroupstaggingapi.Client
+	lambda *lambda.Client
+}
+
+// NewInventory creates a new AWS Inventory object
+func NewInventory(cfg *config.Config, awsConfig aws.Config) *Inventory {
+	return &Inventory{
+		config: cfg,
+		region: awsConfig.Region,
+		client: resourcegroupstaggingapi.NewFromConfig(awsConfig),
+		lambda: lambda.NewFromConfig(awsConfig),
+	}
+}
+
+// list lists the jerm deployments of the account in the region from the tags of
+// their resources. Deployments whose function no longer exists are orphaned.
+func (i *Inventory) list() ([]jerm.Deployment, error) {
+	log.Debug("listing tagged resources...")
+	resources := map[[2]string][]string{}
+	websockets := map[string]bool{}
+	paginator := resourcegroupstaggingapi.NewGetResourcesPaginator(i.client, &resourcegroupstaggingapi.GetResourcesInput{
+		TagFilters: []taggingTypes.TagFilter{{Key: aws.String(config.TagProject)}},
+	})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, err
+		}
+		for _, mapping := range resp.ResourceTagMappingList {
+			var project, stage string
+			for _, tag := range mapping.Tags {
+				switch aws.ToString(tag.Key) {
+				case config.TagProject:
+					project = aws.ToString(tag.Value)
+				case config.TagStage:
+					stage = aws.ToString(tag.Value)
+				case config.TagWebSocket:
+					websockets[aws.ToString(mapping.ResourceARN)] = true
+				}
+			}
+			key := [2]string{project, stage}
+			resources[key] = append(resources[key], aws.ToString(mapping.ResourceARN))
+		}
+	}
+
+	var deployments []jerm.Deployment
+	for key, arns := range resources {
+		deployment, err := i.deployment(key[0], key[1], arns, websockets)
+		if err != nil {
+			return nil, err
+		}
+		deployments = append(deployments, deployment)
+	}
+	sort.Slice(deployments, func(a, b int) bool {
+		if deployments[a].Project != deployments[b].Project {
+			return deployments[a].Project < deployments[b].Project
+		}
+		return deployments[a].Stage < deployments[b].Stage
+	})
+	return deployments, nil
+}
+
+// deployment describes a project stage from the ARNs of its resources.
+// The URL of the deployment isn't the URL of its WebSocket API.
+func (i *Inventory) deployment(project, stage string, arns []string, websockets map[string]bool) (jerm.Deployment, error) {
+	deployment := jerm.Deployment{Project: project, Stage: stage}
+	sort.Strings(arns)
+
+	var function string
+	for _, arn := range arns {
+		// function ARNs are in the form arn:aws:lambda:<region>:<account>:function:<name>
+		// REST API ARNs in the form arn:aws:apigateway:<region>::/restapis/<id> and
+		// HTTP API ARNs in the form arn:aws:apigateway:<region>::/apis/<id>
+		parts := strings.Split(arn, ":")
+		switch {
+		case len(parts) == 7 && parts[2] == "lambda" && parts[5] == "function":
+			// the functions of routes are named after the function of the project
+			if function == "" || parts[6] == fmt.Sprintf("%s-%s", project, stage) {
+				function = parts[6]
+			}
+		case len(parts) == 6 && parts[2] == "apigateway" && strings.Count(parts[5], "/") == 2 && strings.HasPrefix(parts[5], "/restapis/"):
+			apiId := strings.TrimPrefix(parts[5], "/restapis/")
+			deployment.URL = fmt.Sprintf("https://%s.execute-api.%s.amazonaws.com/%s", apiId, i.region, stage)
+		case len(parts) == 6 && parts[2] == "apigateway" && strings.Count(parts[5], "/") == 2 && strings.HasPrefix(parts[5], "/apis/") && !websockets[arn]:
+			apiId := strings.TrimPrefix(parts[5], "/apis/")
+			deployment.URL = fmt.Sprintf("https://%s.execute-api.%s.amazonaws.com", apiId, i.region)
+		}
+	}
+
+	if function == "" {
+		deployment.Orphaned = arns
+		return deployment, nil
+	}
+
+	log.Debug(fmt.Sprintf("getting lambda function %s...", function))
+	resp, err := i.lambda.GetFunction(context.TODO(), &lambda.GetFunctionInput{
+		FunctionName: aws.String(function),
+	})
+	if err != nil {
+		var rnfErr *lambdaTypes.ResourceNotFoundException
+		if errors.As(err, &rnfErr) {
+			deployment.Orphaned = arns
+			return deployment, nil
+		}
+		return deployment, err
+	}
+
+	deployment.Runtime = string(resp.Configuration.Runtime)
+	if resp.Configuration.PackageType == lambdaTypes.PackageTypeImage {
+		deployment.Runtime = "image"
+	}
+	lastModified, _ := time.Parse(lambdaTimeLayout, aws.ToString(resp.Configuration.LastModified))
+	deployment.LastDeployed = lastModified.UTC()
+	return deployment, nil
+}
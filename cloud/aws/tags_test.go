@@ -0,0 +1,96 @@
+package aws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	taggingTypes "github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
+	"github.com/spatocode/jerm"
+	"github.com/spatocode/jerm/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceTags(t *testing.T) {
+	assert := assert.New(t)
+	cfg := &config.Config{Name: "test", Stage: "dev", Tags: map[string]string{"team": "payments"}}
+	tags, err := resourceTags(cfg)
+	assert.Nil(err)
+	assert.Equal(map[string]string{
+		"team":            "payments",
+		config.TagProject: "test",
+		config.TagStage:   "dev",
+		config.TagVersion: jerm.Version,
+	}, tags)
+	assert.Equal([]string{config.TagProject, config.TagStage, config.TagVersion, "team"}, sortedTagKeys(tags))
+}
+
+func resourceTagMapping(arn, project, stage string) taggingTypes.ResourceTagMapping {
+	return taggingTypes.ResourceTagMapping{
+		ResourceARN: aws.String(arn),
+		Tags: []taggingTypes.Tag{
+			{Key: aws.String(config.TagProject), Value: aws.String(project)},
+			{Key: aws.String(config.TagStage), Value: aws.String(stage)},
+		},
+	}
+}
+
+func TestInventoryList(t *testing.T) {
+	assert := assert.New(t)
+	withAPIOptionsFunc, _ := mockApi(func(operation string, input interface{}) (interface{}, error) {
+		switch operation {
+		case "GetResources":
+			return &resourcegroupstaggingapi.GetResourcesOutput{ResourceTagMappingList: []taggingTypes.ResourceTagMapping{
+				resourceTagMapping(testFunctionArn, "test", "dev"),
+				resourceTagMapping("arn:aws:apigateway:us-west-1::/restapis/abc123", "test", "dev"),
+				resourceTagMapping("arn:aws:apigateway:us-west-1::/restapis/abc123/stages/dev", "test", "dev"),
+				resourceTagMapping("arn:aws:lambda:us-west-1:123456789012:function:test-prod", "test", "prod"),
+				resourceTagMapping("arn:aws:lambda:us-west-1:123456789012:function:test-staging", "test", "staging"),
+				resourceTagMapping("arn:aws:apigateway:us-west-1::/apis/http1", "test", "staging"),
+				{
+					ResourceARN: aws.String("arn:aws:apigateway:us-west-1::/apis/ws1"),
+					Tags: append(resourceTagMapping("", "test", "staging").Tags,
+						taggingTypes.Tag{Key: aws.String(config.TagWebSocket), Value: aws.String("true")}),
+				},
+				resourceTagMapping("arn:aws:iam::123456789012:role/old-dev-JermLambdaServiceExecutionRole", "old", "dev"),
+			}}, nil
+		case "GetFunction":
+			if aws.ToString(input.(*lambda.GetFunctionInput).FunctionName) == "test-prod" {
+				return nil, &lambdaTypes.ResourceNotFoundException{}
+			}
+			return &lambda.GetFunctionOutput{Configuration: &lambdaTypes.FunctionConfiguration{
+				Runtime:      lambdaTypes.RuntimePython310,
+				LastModified: aws.String("2023-07-01T12:30:00.000+0000"),
+			}}, nil
+		default:
+			return nil, errUnexpectedOperation
+		}
+	})
+	l := helperLambda(t, withAPIOptionsFunc)
+
+	deployments, err := l.inventory.list()
+	assert.Nil(err)
+	assert.Len(deployments, 4)
+	assert.Equal(jerm.Deployment{
+		Project:  "old",
+		Stage:    "dev",
+		Orphaned: []string{"arn:aws:iam::123456789012:role/old-dev-JermLambdaServiceExecutionRole"},
+	}, deployments[0])
+	assert.Equal(jerm.Deployment{
+		Project:      "test",
+		Stage:        "dev",
+		URL:          "https://abc123.execute-api.us-west-1.amazonaws.com/dev",
+		Runtime:      "python3.10",
+		LastDeployed: time.Date(2023, 7, 1, 12, 30, 0, 0, time.UTC),
+	}, deployments[1])
+	assert.Equal(jerm.Deployment{
+		Project:  "test",
+		Stage:    "prod",
+		Orphaned: []string{"arn:aws:lambda:us-west-1:123456789012:function:test-prod"},
+	}, deployments[2])
+	assert.Equal("staging", deployments[3].Stage)
+	assert.Equal("https://http1.execute-api.us-west-1.amazonaws.com", deployments[3].URL)
+}
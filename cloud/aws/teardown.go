@@ -0,0 +1,231 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cwTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+
+	"github.com/spatocode/jerm"
+	"github.com/spatocode/jerm/internal/log"
+)
+
+// teardownStep deletes a resource of the project
+type teardownStep struct {
+	resource string
+	delete   func() error
+}
+
+// UndeployPlan lists the resources of the project deleted by undeploy,
+// in the order they're deleted
+func (l *Lambda) UndeployPlan(opts jerm.UndeployOptions) ([]string, error) {
+	steps, err := l.teardownSteps(opts)
+	if err != nil {
+		return nil, err
+	}
+	var resources []string
+	for _, step := range steps {
+		resources = append(resources, step.resource)
+	}
+	return resources, nil
+}
+
+// Teardown deletes the resources of the project in dependency order. A resource
+// that fails to be deleted doesn't stop the teardown; the errors are returned together.
+func (l *Lambda) Teardown(opts jerm.UndeployOptions) error {
+	steps, err := l.teardownSteps(opts)
+	if err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		msg := "can't find a deployed project. Run 'jerm deploy' to deploy instead"
+		return errors.New(msg)
+	}
+
+	var errs []error
+	for _, step := range steps {
+		log.Debug(fmt.Sprintf("deleting %s...", step.resource))
+		err = step.delete()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", step.resource, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// teardownSteps lists the resources of the project with how to delete them.
+// Resources using another resource are deleted first.
+func (l *Lambda) teardownSteps(opts jerm.UndeployOptions) ([]teardownStep, error) {
+	var steps []teardownStep
+	name := l.config.GetFunctionName()
+
+	if l.config.Domain != nil {
+		domainSteps, err := l.domains.teardownSteps()
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, domainSteps...)
+	}
+
+	apiIds, err := l.apigateway.getRestApis()
+	if err != nil {
+		return nil, err
+	}
+	if len(apiIds) > 0 {
+		planId, err := l.apigateway.getUsagePlanId()
+		if err != nil {
+			return nil, err
+		}
+		if planId != "" {
+			steps = append(steps, teardownStep{fmt.Sprintf("api keys of %s", name), l.apigateway.deleteApiKeys})
+		}
+		steps = append(steps,
+			teardownStep{fmt.Sprintf("api gateway execution logs of %s", name), l.apigateway.deleteLogs},
+			teardownStep{fmt.Sprintf("api gateway %s", name), l.apigateway.delete},
+		)
+	}
+
+	httpApis, err := l.httpApi.getApis()
+	if err != nil {
+		return nil, err
+	}
+	if len(httpApis) > 0 {
+		steps = append(steps, teardownStep{fmt.Sprintf("http api %s", name), l.httpApi.delete})
+	}
+
+	webSocketApis, err := l.webSocket.getApis()
+	if err != nil {
+		return nil, err
+	}
+	if len(webSocketApis) > 0 {
+		steps = append(steps, teardownStep{fmt.Sprintf("websocket api %s", name), l.webSocket.delete})
+	}
+
+	routeSteps, err := l.routeTeardownSteps()
+	if err != nil {
+		return nil, err
+	}
+	steps = append(steps, routeSteps...)
+
+	function, err := l.getLambdaFunction(name)
+	if err != nil {
+		var rnfErr *lambdaTypes.ResourceNotFoundException
+		if !errors.As(err, &rnfErr) {
+			return nil, err
+		}
+	} else {
+		functionSteps, err := l.functionTeardownSteps(aws.ToString(function.Configuration.FunctionArn))
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, functionSteps...)
+	}
+
+	if l.isImage() {
+		exists, err := l.registry.repositoryExists()
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			steps = append(steps, teardownStep{fmt.Sprintf("ecr repository %s", name), l.registry.deleteRepository})
+		}
+	}
+
+	if !opts.KeepRole {
+		steps = append(steps, teardownStep{fmt.Sprintf("iam role %s", l.access.roleName), l.access.deleteIAMRole})
+	}
+
+	storage, ok := l.storage.(*S3)
+	if !opts.KeepBucket && ok && storage.Accessible() == nil {
+		owned, err := storage.ownsBucket()
+		if err != nil {
+			return nil, err
+		}
+		if owned {
+			steps = append(steps, teardownStep{fmt.Sprintf("s3 bucket %s", l.config.Bucket), storage.deleteBucket})
+		} else {
+			steps = append(steps, teardownStep{fmt.Sprintf("s3 objects of %s in bucket %s", name, l.config.Bucket), storage.deleteObjects})
+		}
+	}
+	return steps, nil
+}
+
+// functionTeardownSteps lists the function with its event sources and logs
+func (l *Lambda) functionTeardownSteps(functionArn string) ([]teardownStep, error) {
+	var steps []teardownStep
+	aliasArn := fmt.Sprintf("%s:%s", functionArn, l.config.Stage)
+
+	rules, err := l.events.listRules(aliasArn)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		rule := rule
+		steps = append(steps, teardownStep{fmt.Sprintf("eventbridge rule %s", rule), func() error {
+			return l.events.deleteRule(rule, aliasArn)
+		}})
+	}
+
+	mappings, err := l.eventSources.listMappings(aliasArn)
+	if err != nil {
+		return nil, err
+	}
+	for _, mapping := range mappings {
+		mapping := mapping
+		steps = append(steps, teardownStep{fmt.Sprintf("event source mapping of %s", aws.ToString(mapping.EventSourceArn)), func() error {
+			return l.eventSources.deleteMapping(mapping)
+		}})
+	}
+
+	permissions, err := l.triggers.listPermissions(aliasArn)
+	if err != nil {
+		return nil, err
+	}
+	var sids []string
+	for sid := range permissions {
+		sids = append(sids, sid)
+	}
+	sort.Strings(sids)
+	for _, sid := range sids {
+		sid, source := sid, permissions[sid]
+		trigger := "sns"
+		if strings.HasPrefix(sid, s3PermissionPrefix) {
+			trigger = "s3"
+		}
+		steps = append(steps, teardownStep{fmt.Sprintf("%s trigger of %s", trigger, source), func() error {
+			return l.triggers.remove(aliasArn, sid, source)
+		}})
+	}
+
+	url, err := l.getFunctionUrl()
+	if err != nil {
+		return nil, err
+	}
+	if url != "" {
+		steps = append(steps, teardownStep{fmt.Sprintf("lambda function url %s", url), l.deleteFunctionUrl})
+	}
+
+	versions, err := l.Versions()
+	if err != nil {
+		return nil, err
+	}
+	steps = append(steps, teardownStep{
+		fmt.Sprintf("lambda function %s with %d published versions", l.config.GetFunctionName(), len(versions)),
+		l.deleteLambdaFunction,
+	})
+
+	groupName := fmt.Sprintf("/aws/lambda/%s", l.config.GetFunctionName())
+	steps = append(steps, teardownStep{fmt.Sprintf("log group %s", groupName), func() error {
+		err := l.monitor.Clear(groupName)
+		var rnfErr *cwTypes.ResourceNotFoundException
+		if err != nil && !errors.As(err, &rnfErr) {
+			return err
+		}
+		return nil
+	}})
+	return steps, nil
+}
@@ -0,0 +1,230 @@
+package aws
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/apigateway"
+	apigatewayTypes "github.com/aws/aws-sdk-go-v2/service/apigateway/types"
+	"github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
+	agv2Types "github.com/aws/aws-sdk-go-v2/service/apigatewayv2/types"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	cfTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	taggingTypes "github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/spatocode/jerm"
+	"github.com/spatocode/jerm/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// deletes filters the operations deleting resources of the project
+func deletes(operations []string) []string {
+	var deleted []string
+	for _, operation := range operations {
+		if strings.HasPrefix(operation, "Delete") || operation == "PutBucketNotificationConfiguration" {
+			deleted = append(deleted, operation)
+		}
+	}
+	return deleted
+}
+
+func TestLambdaUndeploy(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		name        string
+		bucketStage string
+		options     jerm.UndeployOptions
+		plan        []string
+		deletes     []string
+		want        error
+		wantErr     bool
+	}{
+		{
+			name:        "undeploy",
+			bucketStage: "dev",
+			plan: []string{
+				"api gateway execution logs of test-dev",
+				"api gateway test-dev",
+				"http api test-dev",
+				"websocket api test-dev",
+				"lambda function test-dev-reports of route reports",
+				"eventbridge rule test-dev-keep-warm",
+				"event source mapping of arn:aws:sqs:us-west-1:123456789012:orders",
+				"s3 trigger of arn:aws:s3:::old.bucket",
+				"sns trigger of arn:aws:sns:us-west-1:123456789012:old",
+				"lambda function url https://abc.lambda-url.us-west-1.on.aws/",
+				"lambda function test-dev with 2 published versions",
+				"log group /aws/lambda/test-dev",
+				"iam role test-dev-JermLambdaServiceExecutionRole",
+				"s3 bucket jerm-bucket",
+			},
+			deletes: []string{
+				"DeleteLogGroup", "DeleteStack",
+				"DeleteApi",
+				"DeleteApi",
+				"DeleteFunction", "DeleteLogGroup",
+				"DeleteRule",
+				"DeleteEventSourceMapping",
+				"PutBucketNotificationConfiguration",
+				"DeleteFunctionUrlConfig",
+				"DeleteFunction",
+				"DeleteLogGroup",
+				"DeleteRolePolicy", "DeleteRole",
+				"DeleteBucket",
+			},
+			want:    errors.New("iam role test-dev-JermLambdaServiceExecutionRole: operation error IAM: DeleteRole, role in use"),
+			wantErr: true,
+		},
+		{
+			name:        "undeploy keeping bucket and role",
+			bucketStage: "dev",
+			options:     jerm.UndeployOptions{KeepBucket: true, KeepRole: true},
+			plan:        []string{"log group /aws/lambda/test-dev"},
+			deletes: []string{
+				"DeleteLogGroup", "DeleteStack",
+				"DeleteApi",
+				"DeleteApi",
+				"DeleteFunction", "DeleteLogGroup",
+				"DeleteRule",
+				"DeleteEventSourceMapping",
+				"PutBucketNotificationConfiguration",
+				"DeleteFunctionUrlConfig",
+				"DeleteFunction",
+				"DeleteLogGroup",
+			},
+		},
+		{
+			name:        "undeploy from shared bucket",
+			bucketStage: "prod",
+			options:     jerm.UndeployOptions{KeepRole: true},
+			plan:        []string{"s3 objects of test-dev in bucket jerm-bucket"},
+			deletes: []string{
+				"DeleteLogGroup", "DeleteStack",
+				"DeleteApi",
+				"DeleteApi",
+				"DeleteFunction", "DeleteLogGroup",
+				"DeleteRule",
+				"DeleteEventSourceMapping",
+				"PutBucketNotificationConfiguration",
+				"DeleteFunctionUrlConfig",
+				"DeleteFunction",
+				"DeleteLogGroup",
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			withAPIOptionsFunc, recorder := mockApi(func(operation string, input interface{}) (interface{}, error) {
+				switch operation {
+				case "GetRestApis":
+					return &apigateway.GetRestApisOutput{Items: []apigatewayTypes.RestApi{
+						{Id: aws.String("api1"), Name: aws.String("test-dev")},
+						{Id: aws.String("api2"), Name: aws.String("other")},
+					}}, nil
+				case "GetApis":
+					return &apigatewayv2.GetApisOutput{Items: []agv2Types.Api{
+						{ApiId: aws.String("http1"), Name: aws.String("test-dev"), ProtocolType: agv2Types.ProtocolTypeHttp},
+						{ApiId: aws.String("ws1"), Name: aws.String("test-dev"), ProtocolType: agv2Types.ProtocolTypeWebsocket},
+					}}, nil
+				case "GetResources":
+					return &resourcegroupstaggingapi.GetResourcesOutput{ResourceTagMappingList: []taggingTypes.ResourceTagMapping{
+						{
+							ResourceARN: aws.String("arn:aws:lambda:us-west-1:123456789012:function:test-dev-reports"),
+							Tags:        []taggingTypes.Tag{{Key: aws.String("jerm:route"), Value: aws.String("reports")}},
+						},
+					}}, nil
+				case "GetFunctionUrlConfig":
+					return &lambda.GetFunctionUrlConfigOutput{FunctionUrl: aws.String("https://abc.lambda-url.us-west-1.on.aws/")}, nil
+				case "GetFunction":
+					return &lambda.GetFunctionOutput{Configuration: &lambdaTypes.FunctionConfiguration{FunctionArn: aws.String(testFunctionArn)}}, nil
+				case "ListRuleNamesByTarget":
+					return &eventbridge.ListRuleNamesByTargetOutput{RuleNames: []string{"test-dev-keep-warm"}}, nil
+				case "ListEventSourceMappings":
+					return &lambda.ListEventSourceMappingsOutput{EventSourceMappings: []lambdaTypes.EventSourceMappingConfiguration{
+						{UUID: aws.String("1"), EventSourceArn: aws.String("arn:aws:sqs:us-west-1:123456789012:orders")},
+					}}, nil
+				case "GetPolicy":
+					return &lambda.GetPolicyOutput{Policy: aws.String(testFunctionPolicy)}, nil
+				case "ListVersionsByFunction":
+					return &lambda.ListVersionsByFunctionOutput{Versions: []lambdaTypes.FunctionConfiguration{
+						{Version: aws.String("$LATEST")}, {Version: aws.String("1")}, {Version: aws.String("2")},
+					}}, nil
+				case "GetAlias":
+					return &lambda.GetAliasOutput{FunctionVersion: aws.String("2")}, nil
+				case "HeadBucket":
+					return &s3.HeadBucketOutput{}, nil
+				case "GetBucketTagging":
+					return &s3.GetBucketTaggingOutput{TagSet: []s3Types.Tag{
+						{Key: aws.String(config.TagProject), Value: aws.String("test")},
+						{Key: aws.String(config.TagStage), Value: aws.String(tt.bucketStage)},
+					}}, nil
+				case "GetStages":
+					return &apigateway.GetStagesOutput{Item: []apigatewayTypes.Stage{{StageName: aws.String("dev")}}}, nil
+				case "DescribeStacks":
+					return &cloudformation.DescribeStacksOutput{Stacks: []cfTypes.Stack{
+						{Tags: []cfTypes.Tag{{Key: aws.String("JermProject"), Value: aws.String("test-dev")}}},
+					}}, nil
+				case "GetBucketNotificationConfiguration":
+					return &s3.GetBucketNotificationConfigurationOutput{}, nil
+				case "ListSubscriptionsByTopic":
+					return &sns.ListSubscriptionsByTopicOutput{}, nil
+				case "ListObjectsV2":
+					return &s3.ListObjectsV2Output{}, nil
+				case "DeleteRole":
+					return nil, errors.New("role in use")
+				case "DeleteLogGroup":
+					return &cloudwatchlogs.DeleteLogGroupOutput{}, nil
+				case "DeleteStack":
+					return &cloudformation.DeleteStackOutput{}, nil
+				case "RemoveTargets":
+					return &eventbridge.RemoveTargetsOutput{}, nil
+				case "DeleteRule":
+					return &eventbridge.DeleteRuleOutput{}, nil
+				case "RemovePermission":
+					return &lambda.RemovePermissionOutput{}, nil
+				case "DeleteEventSourceMapping":
+					return &lambda.DeleteEventSourceMappingOutput{}, nil
+				case "PutBucketNotificationConfiguration":
+					return &s3.PutBucketNotificationConfigurationOutput{}, nil
+				case "DeleteApi":
+					return &apigatewayv2.DeleteApiOutput{}, nil
+				case "DeleteFunctionUrlConfig":
+					return &lambda.DeleteFunctionUrlConfigOutput{}, nil
+				case "DeleteFunction":
+					return &lambda.DeleteFunctionOutput{}, nil
+				case "DeleteRolePolicy":
+					return &iam.DeleteRolePolicyOutput{}, nil
+				case "DeleteBucket":
+					return &s3.DeleteBucketOutput{}, nil
+				}
+				return nil, errUnexpectedOperation
+			})
+			l := helperLambda(t, withAPIOptionsFunc)
+
+			plan, err := l.UndeployPlan(tt.options)
+			assert.Nil(err)
+			assert.Equal(tt.plan, plan[len(plan)-len(tt.plan):])
+			assert.Empty(deletes(recorder.operations))
+
+			err = l.Teardown(tt.options)
+			if tt.wantErr {
+				assert.EqualError(err, tt.want.Error())
+			} else {
+				assert.Nil(err)
+			}
+			assert.Equal(tt.deletes, deletes(recorder.operations))
+		})
+	}
+}
@@ -0,0 +1,315 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	s3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/spatocode/jerm/internal/log"
+)
+
+const (
+	s3PermissionPrefix  = "jerm-s3-"
+	snsPermissionPrefix = "jerm-sns-"
+)
+
+// Triggers is the AWS S3 and SNS trigger operations
+type Triggers struct {
+	config  *config.Config
+	storage *S3
+	sns     *sns.Client
+	lambda  *lambda.Client
+}
+
+// NewTriggers creates a new AWS Triggers object
+func NewTriggers(cfg *config.Config, awsConfig aws.Config) *Triggers {
+	return &Triggers{
+		config:  cfg,
+		storage: NewS3(cfg, awsConfig),
+		sns:     sns.NewFromConfig(awsConfig),
+		lambda:  lambda.NewFromConfig(awsConfig),
+	}
+}
+
+// sync reconciles the S3 and SNS triggers of the function with the config.
+// Triggers removed from the config are deleted.
+func (t *Triggers) sync(functionArn string) error {
+	triggers, err := t.config.GetTriggers()
+	if err != nil {
+		return err
+	}
+	return t.reconcile(functionArn, triggers)
+}
+
+// delete deletes the S3 and SNS triggers of the function
+func (t *Triggers) delete(functionArn string) error {
+	return t.reconcile(functionArn, config.Triggers{})
+}
+
+// routes returns the bucket and topic ARNs mapped to the user functions handling them
+func (t *Triggers) routes() map[string]string {
+	routes := map[string]string{}
+	if t.config.Triggers == nil {
+		return routes
+	}
+	for _, trigger := range t.config.Triggers.S3 {
+		if trigger.Function != "" {
+			routes[trigger.BucketArn()] = trigger.Function
+		}
+	}
+	for _, trigger := range t.config.Triggers.SNS {
+		if trigger.Function != "" {
+			routes[trigger.Topic] = trigger.Function
+		}
+	}
+	return routes
+}
+
+func (t *Triggers) reconcile(functionArn string, triggers config.Triggers) error {
+	// the invoke permissions of the function record the sources of its triggers
+	permissions, err := t.listPermissions(functionArn)
+	if err != nil {
+		return err
+	}
+
+	buckets := map[string][]config.S3Trigger{}
+	var bucketOrder []string
+	for _, trigger := range triggers.S3 {
+		if _, ok := buckets[trigger.Bucket]; !ok {
+			bucketOrder = append(bucketOrder, trigger.Bucket)
+		}
+		buckets[trigger.Bucket] = append(buckets[trigger.Bucket], trigger)
+	}
+
+	for _, bucket := range bucketOrder {
+		arn := fmt.Sprintf("arn:aws:s3:::%s", bucket)
+		err = t.addPermission(functionArn, s3PermissionPrefix, "s3.amazonaws.com", arn)
+		if err != nil {
+			return err
+		}
+		err = t.storage.putLambdaNotifications(bucket, t.notificationIds(), t.notifications(functionArn, buckets[bucket]))
+		if err != nil {
+			return err
+		}
+		delete(permissions, permissionId(s3PermissionPrefix, arn))
+	}
+
+	topics := map[string]bool{}
+	for _, trigger := range triggers.SNS {
+		topics[trigger.Topic] = true
+		err = t.addPermission(functionArn, snsPermissionPrefix, "sns.amazonaws.com", trigger.Topic)
+		if err != nil {
+			return err
+		}
+		err = t.subscribe(functionArn, trigger.Topic)
+		if err != nil {
+			return err
+		}
+		delete(permissions, permissionId(snsPermissionPrefix, trigger.Topic))
+	}
+
+	for sid, arn := range permissions {
+		err = t.remove(functionArn, sid, arn)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remove removes the trigger of the source of an invoke permission of the function
+func (t *Triggers) remove(functionArn, sid, sourceArn string) error {
+	var err error
+	switch {
+	case strings.HasPrefix(sid, s3PermissionPrefix):
+		bucket := strings.TrimPrefix(sourceArn, "arn:aws:s3:::")
+		err = t.storage.putLambdaNotifications(bucket, t.notificationIds(), nil)
+	case strings.HasPrefix(sid, snsPermissionPrefix):
+		err = t.unsubscribe(functionArn, sourceArn)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return t.removePermission(functionArn, sid)
+}
+
+// notificationPrefix is the prefix of the IDs of the jerm notifications of a bucket
+func (t *Triggers) notificationPrefix() string {
+	return fmt.Sprintf("jerm-%s-", t.config.GetFunctionName())
+}
+
+// notificationIds matches the IDs of the jerm notifications of a bucket. Functions
+// whose names share a prefix, like app-dev and app-dev-worker, don't match each other.
+func (t *Triggers) notificationIds() *regexp.Regexp {
+	return regexp.MustCompile(fmt.Sprintf(`^%s\d+$`, regexp.QuoteMeta(t.notificationPrefix())))
+}
+
+// notifications returns the Lambda notifications of the triggers of a bucket
+func (t *Triggers) notifications(functionArn string, triggers []config.S3Trigger) []s3Types.LambdaFunctionConfiguration {
+	var notifications []s3Types.LambdaFunctionConfiguration
+	for idx, trigger := range triggers {
+		notification := s3Types.LambdaFunctionConfiguration{
+			Id:                aws.String(fmt.Sprintf("%s%d", t.notificationPrefix(), idx)),
+			LambdaFunctionArn: aws.String(functionArn),
+		}
+		for _, event := range trigger.GetEvents() {
+			notification.Events = append(notification.Events, s3Types.Event(event))
+		}
+
+		var rules []s3Types.FilterRule
+		if trigger.Prefix != "" {
+			rules = append(rules, s3Types.FilterRule{Name: s3Types.FilterRuleNamePrefix, Value: aws.String(trigger.Prefix)})
+		}
+		if trigger.Suffix != "" {
+			rules = append(rules, s3Types.FilterRule{Name: s3Types.FilterRuleNameSuffix, Value: aws.String(trigger.Suffix)})
+		}
+		if len(rules) > 0 {
+			notification.Filter = &s3Types.NotificationConfigurationFilter{
+				Key: &s3Types.S3KeyFilter{FilterRules: rules},
+			}
+		}
+		notifications = append(notifications, notification)
+	}
+	return notifications
+}
+
+// subscribe subscribes the function to a topic. Subscribing twice is a no-op.
+func (t *Triggers) subscribe(functionArn, topic string) error {
+	log.Debug(fmt.Sprintf("subscribing to sns topic %s...", topic))
+	_, err := t.sns.Subscribe(context.TODO(), &sns.SubscribeInput{
+		TopicArn: aws.String(topic),
+		Protocol: aws.String("lambda"),
+		Endpoint: aws.String(functionArn),
+	})
+	return err
+}
+
+// unsubscribe removes the subscriptions of the function to a topic
+func (t *Triggers) unsubscribe(functionArn, topic string) error {
+	log.Debug(fmt.Sprintf("unsubscribing from sns topic %s...", topic))
+	paginator := sns.NewListSubscriptionsByTopicPaginator(t.sns, &sns.ListSubscriptionsByTopicInput{
+		TopicArn: aws.String(topic),
+	})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return err
+		}
+		for _, subscription := range resp.Subscriptions {
+			if aws.ToString(subscription.Endpoint) != functionArn {
+				continue
+			}
+			_, err = t.sns.Unsubscribe(context.TODO(), &sns.UnsubscribeInput{
+				SubscriptionArn: subscription.SubscriptionArn,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// addPermission allows a service to invoke the function on events of a source
+func (t *Triggers) addPermission(functionArn, prefix, principal, sourceArn string) error {
+	input := &lambda.AddPermissionInput{
+		FunctionName: aws.String(functionArn),
+		StatementId:  aws.String(permissionId(prefix, sourceArn)),
+		Action:       aws.String("lambda:InvokeFunction"),
+		Principal:    aws.String(principal),
+		SourceArn:    aws.String(sourceArn),
+	}
+	if prefix == s3PermissionPrefix {
+		// bucket ARNs don't contain the account, so a bucket of another account could use the same name
+		input.SourceAccount = aws.String(strings.Split(functionArn, ":")[4])
+	}
+
+	_, err := t.lambda.AddPermission(context.TODO(), input)
+	if err != nil {
+		var rcErr *lambdaTypes.ResourceConflictException
+		if errors.As(err, &rcErr) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+func (t *Triggers) removePermission(functionArn, sid string) error {
+	_, err := t.lambda.RemovePermission(context.TODO(), &lambda.RemovePermissionInput{
+		FunctionName: aws.String(functionArn),
+		StatementId:  aws.String(sid),
+	})
+	if err != nil {
+		var rnfErr *lambdaTypes.ResourceNotFoundException
+		if errors.As(err, &rnfErr) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// listPermissions lists the statement IDs of the jerm trigger permissions
+// of the function with their source ARNs
+func (t *Triggers) listPermissions(functionArn string) (map[string]string, error) {
+	permissions := map[string]string{}
+	resp, err := t.lambda.GetPolicy(context.TODO(), &lambda.GetPolicyInput{
+		FunctionName: aws.String(functionArn),
+	})
+	if err != nil {
+		var rnfErr *lambdaTypes.ResourceNotFoundException
+		if errors.As(err, &rnfErr) {
+			return permissions, nil
+		}
+		return nil, err
+	}
+
+	var policy struct {
+		Statement []struct {
+			Sid       string
+			Condition map[string]map[string]interface{}
+		}
+	}
+	err = json.Unmarshal([]byte(aws.ToString(resp.Policy)), &policy)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, statement := range policy.Statement {
+		if !strings.HasPrefix(statement.Sid, s3PermissionPrefix) && !strings.HasPrefix(statement.Sid, snsPermissionPrefix) {
+			continue
+		}
+		for _, condition := range statement.Condition {
+			if arn, ok := condition["AWS:SourceArn"].(string); ok {
+				permissions[statement.Sid] = arn
+			}
+		}
+	}
+	return permissions, nil
+}
+
+// permissionId returns the statement ID of the invoke permission of a source
+func permissionId(prefix, sourceArn string) string {
+	name := sourceArn[strings.LastIndex(sourceArn, ":")+1:]
+	if prefix == snsPermissionPrefix {
+		// topics of different regions may have the same name
+		parts := strings.Split(sourceArn, ":")
+		if len(parts) > 3 {
+			name = fmt.Sprintf("%s-%s", parts[3], name)
+		}
+	}
+	return prefix + strings.ReplaceAll(name, ".", "-")
+}
@@ -0,0 +1,153 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	snsTypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const testFunctionPolicy = `{
+	"Version": "2012-10-17",
+	"Statement": [
+		{"Sid": "jerm-s3-old-bucket", "Condition": {"ArnLike": {"AWS:SourceArn": "arn:aws:s3:::old.bucket"}}},
+		{"Sid": "jerm-sns-us-west-1-old", "Condition": {"ArnLike": {"AWS:SourceArn": "arn:aws:sns:us-west-1:123456789012:old"}}},
+		{"Sid": "test-dev-report", "Condition": {"ArnLike": {"AWS:SourceArn": "arn:aws:events:us-west-1:123456789012:rule/test"}}}
+	]
+}`
+
+func TestNewTriggers(t *testing.T) {
+	assert := assert.New(t)
+	cfg := &config.Config{}
+	tr := NewTriggers(cfg, aws.Config{})
+	assert.Equal(cfg, tr.config)
+	assert.NotNil(tr.storage)
+	assert.NotNil(tr.sns)
+	assert.NotNil(tr.lambda)
+}
+
+func TestTriggersSync(t *testing.T) {
+	assert := assert.New(t)
+	withAPIOptionsFunc, recorder := mockApi(func(operation string, input interface{}) (interface{}, error) {
+		switch operation {
+		case "GetPolicy":
+			return &lambda.GetPolicyOutput{Policy: aws.String(testFunctionPolicy)}, nil
+		case "AddPermission":
+			return &lambda.AddPermissionOutput{}, nil
+		case "RemovePermission":
+			return &lambda.RemovePermissionOutput{}, nil
+		case "GetBucketNotificationConfiguration":
+			return &s3.GetBucketNotificationConfigurationOutput{
+				LambdaFunctionConfigurations: []s3Types.LambdaFunctionConfiguration{
+					{Id: aws.String("jerm-test-dev-0"), LambdaFunctionArn: aws.String(testFunctionArn), Events: []s3Types.Event{"s3:ObjectCreated:*"}},
+					{Id: aws.String("other"), LambdaFunctionArn: aws.String("arn:aws:lambda:us-west-1:123456789012:function:other"), Events: []s3Types.Event{"s3:ObjectCreated:*"}},
+				},
+				QueueConfigurations: []s3Types.QueueConfiguration{
+					{Id: aws.String("queue"), QueueArn: aws.String("arn:aws:sqs:us-west-1:123456789012:queue"), Events: []s3Types.Event{"s3:ObjectRemoved:*"}},
+				},
+			}, nil
+		case "PutBucketNotificationConfiguration":
+			return &s3.PutBucketNotificationConfigurationOutput{}, nil
+		case "Subscribe":
+			return &sns.SubscribeOutput{}, nil
+		case "ListSubscriptionsByTopic":
+			return &sns.ListSubscriptionsByTopicOutput{Subscriptions: []snsTypes.Subscription{
+				{SubscriptionArn: aws.String("sub-1"), Endpoint: aws.String(testAliasArn)},
+				{SubscriptionArn: aws.String("sub-2"), Endpoint: aws.String("arn:aws:lambda:us-west-1:123456789012:function:other")},
+			}}, nil
+		case "Unsubscribe":
+			return &sns.UnsubscribeOutput{}, nil
+		default:
+			return nil, errUnexpectedOperation
+		}
+	})
+	l := helperLambda(t, withAPIOptionsFunc)
+	l.config.Triggers = &config.Triggers{
+		S3:  []config.S3Trigger{{Bucket: "uploads", Prefix: "images/", Suffix: ".jpg", Function: "app.images.resize"}},
+		SNS: []config.SNSTrigger{{Topic: "arn:aws:sns:us-west-1:123456789012:alerts"}},
+	}
+
+	err := l.triggers.sync(testAliasArn)
+	assert.Nil(err)
+	assert.Equal([]string{
+		"GetPolicy",
+		"AddPermission", "GetBucketNotificationConfiguration", "PutBucketNotificationConfiguration",
+		"AddPermission", "Subscribe",
+	}, recorder.operations[:6])
+	assert.ElementsMatch([]string{
+		"GetBucketNotificationConfiguration", "PutBucketNotificationConfiguration", "RemovePermission",
+		"ListSubscriptionsByTopic", "Unsubscribe", "RemovePermission",
+	}, recorder.operations[6:])
+
+	permission := recorder.inputs[1].(*lambda.AddPermissionInput)
+	assert.Equal("jerm-s3-uploads", *permission.StatementId)
+	assert.Equal("arn:aws:s3:::uploads", *permission.SourceArn)
+	assert.Equal("123456789012", *permission.SourceAccount)
+
+	notifications := recorder.inputs[3].(*s3.PutBucketNotificationConfigurationInput).NotificationConfiguration
+	assert.Len(notifications.QueueConfigurations, 1)
+	assert.Len(notifications.LambdaFunctionConfigurations, 2)
+	assert.Equal("other", *notifications.LambdaFunctionConfigurations[0].Id)
+	jerm := notifications.LambdaFunctionConfigurations[1]
+	assert.Equal("jerm-test-dev-0", *jerm.Id)
+	assert.Equal(testAliasArn, *jerm.LambdaFunctionArn)
+	assert.Equal([]s3Types.Event{config.DefaultS3TriggerEvent}, jerm.Events)
+	assert.Len(jerm.Filter.Key.FilterRules, 2)
+
+	permission = recorder.inputs[4].(*lambda.AddPermissionInput)
+	assert.Equal("jerm-sns-us-west-1-alerts", *permission.StatementId)
+	assert.Nil(permission.SourceAccount)
+
+	for _, input := range recorder.inputs[6:] {
+		if unsubscribe, ok := input.(*sns.UnsubscribeInput); ok {
+			assert.Equal("sub-1", *unsubscribe.SubscriptionArn)
+		}
+		if put, ok := input.(*s3.PutBucketNotificationConfigurationInput); ok {
+			assert.Equal("old.bucket", *put.Bucket)
+			assert.Len(put.NotificationConfiguration.LambdaFunctionConfigurations, 1)
+		}
+	}
+
+	assert.Equal(map[string]string{"arn:aws:s3:::uploads": "app.images.resize"}, l.triggers.routes())
+}
+
+func TestTriggersNotificationIds(t *testing.T) {
+	assert := assert.New(t)
+	withAPIOptionsFunc, recorder := mockApi(func(operation string, input interface{}) (interface{}, error) {
+		switch operation {
+		case "GetBucketNotificationConfiguration":
+			return &s3.GetBucketNotificationConfigurationOutput{
+				LambdaFunctionConfigurations: []s3Types.LambdaFunctionConfiguration{
+					{Id: aws.String("jerm-app-dev-0"), LambdaFunctionArn: aws.String(testFunctionArn), Events: []s3Types.Event{"s3:ObjectCreated:*"}},
+					{Id: aws.String("jerm-app-dev-worker-0"), LambdaFunctionArn: aws.String(testFunctionArn), Events: []s3Types.Event{"s3:ObjectCreated:*"}},
+					{Id: aws.String("jerm-app-dev-1"), LambdaFunctionArn: aws.String(testFunctionArn), Events: []s3Types.Event{"s3:ObjectCreated:*"}},
+				},
+			}, nil
+		case "PutBucketNotificationConfiguration":
+			return &s3.PutBucketNotificationConfigurationOutput{}, nil
+		default:
+			return nil, errUnexpectedOperation
+		}
+	})
+	awsCfg := mockAwsConfig(t, withAPIOptionsFunc)
+	app := NewTriggers(&config.Config{Name: "app", Stage: "dev"}, awsCfg)
+	worker := NewTriggers(&config.Config{Name: "app-dev", Stage: "worker"}, awsCfg)
+
+	assert.True(app.notificationIds().MatchString("jerm-app-dev-1"))
+	assert.False(app.notificationIds().MatchString("jerm-app-dev-worker-0"))
+	assert.True(worker.notificationIds().MatchString("jerm-app-dev-worker-0"))
+	assert.False(worker.notificationIds().MatchString("jerm-app-dev-0"))
+
+	err := app.storage.putLambdaNotifications("uploads", app.notificationIds(), nil)
+	assert.Nil(err)
+	notifications := recorder.inputs[1].(*s3.PutBucketNotificationConfigurationInput).NotificationConfiguration
+	assert.Len(notifications.LambdaFunctionConfigurations, 1)
+	assert.Equal("jerm-app-dev-worker-0", *notifications.LambdaFunctionConfigurations[0].Id)
+}
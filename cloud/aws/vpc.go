@@ -0,0 +1,106 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/spatocode/jerm/internal/log"
+)
+
+// VPC is the AWS VPC operations
+type VPC struct {
+	config *config.Config
+	client *ec2.Client
+}
+
+// NewVPC creates a new AWS VPC object
+func NewVPC(cfg *config.Config, awsConfig aws.Config) *VPC {
+	return &VPC{
+		config: cfg,
+		client: ec2.NewFromConfig(awsConfig),
+	}
+}
+
+// vpcConfig returns the VPC configuration of the function.
+// It returns nil if the function isn't in a VPC.
+func (v *VPC) vpcConfig() (*lambdaTypes.VpcConfig, error) {
+	vpc := v.config.Platform.Vpc
+	if vpc == nil {
+		return nil, nil
+	}
+
+	subnets := vpc.SubnetIds
+	if len(subnets) == 0 && len(vpc.SubnetTags) > 0 {
+		var err error
+		subnets, err = v.discoverSubnets(vpc.SubnetTags)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(subnets) == 0 || len(vpc.SecurityGroupIds) == 0 {
+		return nil, errors.New("vpc requires subnets and security_group_ids")
+	}
+
+	return &lambdaTypes.VpcConfig{
+		SubnetIds:        subnets,
+		SecurityGroupIds: vpc.SecurityGroupIds,
+	}, nil
+}
+
+// discoverSubnets finds the IDs of the subnets tagged with all the tags
+func (v *VPC) discoverSubnets(tags map[string]string) ([]string, error) {
+	log.Debug("discovering subnets by tags...")
+	var filters []ec2Types.Filter
+	for k, value := range tags {
+		filters = append(filters, ec2Types.Filter{
+			Name:   aws.String(fmt.Sprintf("tag:%s", k)),
+			Values: []string{value},
+		})
+	}
+
+	var subnets []string
+	paginator := ec2.NewDescribeSubnetsPaginator(v.client, &ec2.DescribeSubnetsInput{
+		Filters: filters,
+	})
+	for paginator.HasMorePages() {
+		resp, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, err
+		}
+		for _, subnet := range resp.Subnets {
+			subnets = append(subnets, aws.ToString(subnet.SubnetId))
+		}
+	}
+
+	if len(subnets) == 0 {
+		return nil, errors.New("can't find subnets with the subnet_tags")
+	}
+	sort.Strings(subnets)
+	return subnets, nil
+}
+
+// equalIds checks if two lists contain the same IDs in any order
+func equalIds(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	x := append([]string{}, a...)
+	y := append([]string{}, b...)
+	sort.Strings(x)
+	sort.Strings(y)
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
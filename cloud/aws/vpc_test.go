@@ -0,0 +1,81 @@
+package aws
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewVPC(t *testing.T) {
+	assert := assert.New(t)
+	cfg := &config.Config{}
+	v := NewVPC(cfg, aws.Config{})
+	assert.Equal(cfg, v.config)
+	assert.NotNil(v.client)
+}
+
+func TestVPCConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		name    string
+		vpc     *config.Vpc
+		want    *lambdaTypes.VpcConfig
+		filters []ec2Types.Filter
+		err     error
+	}{
+		{
+			name: "no vpc",
+		},
+		{
+			name: "vpc without security groups",
+			vpc:  &config.Vpc{SubnetIds: []string{"subnet-3"}},
+			err:  fmt.Errorf("vpc requires subnets and security_group_ids"),
+		},
+		{
+			name: "vpc with subnets",
+			vpc:  &config.Vpc{SubnetIds: []string{"subnet-3"}, SecurityGroupIds: []string{"sg-1"}},
+			want: &lambdaTypes.VpcConfig{SubnetIds: []string{"subnet-3"}, SecurityGroupIds: []string{"sg-1"}},
+		},
+		{
+			name:    "vpc with subnet tags",
+			vpc:     &config.Vpc{SubnetTags: map[string]string{"tier": "private"}, SecurityGroupIds: []string{"sg-1"}},
+			want:    &lambdaTypes.VpcConfig{SubnetIds: []string{"subnet-1", "subnet-2"}, SecurityGroupIds: []string{"sg-1"}},
+			filters: []ec2Types.Filter{{Name: aws.String("tag:tier"), Values: []string{"private"}}},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			withAPIOptionsFunc, recorder := mockApi(func(operation string, input interface{}) (interface{}, error) {
+				return &ec2.DescribeSubnetsOutput{Subnets: []ec2Types.Subnet{
+					{SubnetId: aws.String("subnet-2")},
+					{SubnetId: aws.String("subnet-1")},
+				}}, nil
+			})
+			l := helperLambda(t, withAPIOptionsFunc)
+			l.config.Platform.Vpc = tt.vpc
+
+			vpc, err := l.vpc.vpcConfig()
+			if tt.err != nil {
+				assert.EqualError(err, tt.err.Error())
+				return
+			}
+			assert.Nil(err)
+			assert.Equal(tt.want, vpc)
+
+			var filters []ec2Types.Filter
+			for _, input := range recorder.inputs {
+				filters = append(filters, input.(*ec2.DescribeSubnetsInput).Filters...)
+			}
+			assert.Equal(tt.filters, filters)
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
+	agv2Types "github.com/aws/aws-sdk-go-v2/service/apigatewayv2/types"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/spatocode/jerm/internal/log"
+)
+
+// WebSocketApi is the AWS API Gateway v2 WebSocket API operations
+type WebSocketApi struct {
+	config *config.Config
+	region string
+	client *apigatewayv2.Client
+}
+
+// NewWebSocketApi creates a new AWS WebSocketApi object
+func NewWebSocketApi(cfg *config.Config, awsConfig aws.Config) *WebSocketApi {
+	return &WebSocketApi{
+		config: cfg,
+		region: awsConfig.Region,
+		client: apigatewayv2.NewFromConfig(awsConfig),
+	}
+}
+
+// serveWebSocket exposes the stage alias through the WebSocket API of the project,
+// or deletes the WebSocket API when the project no longer has one
+func (l *Lambda) serveWebSocket(aliasArn string) error {
+	if l.config.WebSocket == nil {
+		return l.webSocket.delete()
+	}
+
+	url, err := l.webSocket.setup(aliasArn)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s %s\n", log.Magenta("websocket:"), log.Green(url))
+	return nil
+}
+
+// setup creates the WebSocket API of the function, or updates the existing one,
+// and returns its URL. Every route of the API is integrated with the function
+// and served by an auto deployed stage named after the stage of the project.
+func (w *WebSocketApi) setup(functionArn string) (string, error) {
+	websocket, err := w.config.GetWebSocket()
+	if err != nil {
+		return "", err
+	}
+
+	apis, err := w.getApis()
+	if err != nil {
+		return "", err
+	}
+
+	var apiId, endpoint *string
+	if len(apis) > 0 {
+		log.Debug("updating WebSocket API...")
+		resp, err := w.client.UpdateApi(context.TODO(), &apigatewayv2.UpdateApiInput{
+			ApiId:                    apis[0].ApiId,
+			RouteSelectionExpression: aws.String(websocket.RouteSelectionExpression),
+		})
+		if err != nil {
+			return "", err
+		}
+		apiId, endpoint = resp.ApiId, resp.ApiEndpoint
+	} else {
+		tags, err := w.tags()
+		if err != nil {
+			return "", err
+		}
+
+		log.Debug("creating WebSocket API...")
+		resp, err := w.client.CreateApi(context.TODO(), &apigatewayv2.CreateApiInput{
+			Name:                     aws.String(w.config.GetFunctionName()),
+			ProtocolType:             agv2Types.ProtocolTypeWebsocket,
+			RouteSelectionExpression: aws.String(websocket.RouteSelectionExpression),
+			Description:              aws.String("Automatically created by Jerm"),
+			Tags:                     tags,
+		})
+		if err != nil {
+			return "", err
+		}
+		apiId, endpoint = resp.ApiId, resp.ApiEndpoint
+	}
+
+	integrationId, err := w.setupIntegration(apiId, functionArn)
+	if err != nil {
+		return "", err
+	}
+
+	err = w.setupRoutes(apiId, websocket.RouteKeys(), fmt.Sprintf("integrations/%s", integrationId))
+	if err != nil {
+		return "", err
+	}
+
+	err = w.setupStage(apiId)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/%s", aws.ToString(endpoint), w.config.Stage), nil
+}
+
+// tags returns the tags of the WebSocket API. The WebSocket tag tells it apart
+// from the HTTP API of the project.
+func (w *WebSocketApi) tags() (map[string]string, error) {
+	tags, err := resourceTags(w.config)
+	if err != nil {
+		return nil, err
+	}
+	tags[config.TagWebSocket] = "true"
+	return tags, nil
+}
+
+// setupIntegration creates the Lambda proxy integration of the API with the function,
+// or points the existing one at the function, and returns its ID
+func (w *WebSocketApi) setupIntegration(apiId *string, functionArn string) (string, error) {
+	uri := lambdaInvocationUri(w.region, functionArn)
+
+	resp, err := w.client.GetIntegrations(context.TODO(), &apigatewayv2.GetIntegrationsInput{
+		ApiId: apiId,
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, integration := range resp.Items {
+		if integration.IntegrationType != agv2Types.IntegrationTypeAwsProxy {
+			continue
+		}
+		if aws.ToString(integration.IntegrationUri) != uri || aws.ToString(integration.CredentialsArn) != w.config.Platform.Role {
+			log.Debug("updating WebSocket API integration...")
+			_, err = w.client.UpdateIntegration(context.TODO(), &apigatewayv2.UpdateIntegrationInput{
+				ApiId:          apiId,
+				IntegrationId:  integration.IntegrationId,
+				IntegrationUri: aws.String(uri),
+				CredentialsArn: aws.String(w.config.Platform.Role),
+			})
+			if err != nil {
+				return "", err
+			}
+		}
+		return aws.ToString(integration.IntegrationId), nil
+	}
+
+	log.Debug("creating WebSocket API integration...")
+	integration, err := w.client.CreateIntegration(context.TODO(), &apigatewayv2.CreateIntegrationInput{
+		ApiId:             apiId,
+		IntegrationType:   agv2Types.IntegrationTypeAwsProxy,
+		IntegrationMethod: aws.String("POST"),
+		IntegrationUri:    aws.String(uri),
+		CredentialsArn:    aws.String(w.config.Platform.Role),
+	})
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(integration.IntegrationId), nil
+}
+
+// setupRoutes creates the routes of the route keys with the target and
+// deletes the routes removed from the config
+func (w *WebSocketApi) setupRoutes(apiId *string, routeKeys []string, target string) error {
+	routes, err := w.getRoutes(apiId)
+	if err != nil {
+		return err
+	}
+
+	existing := map[string]agv2Types.Route{}
+	for _, route := range routes {
+		existing[aws.ToString(route.RouteKey)] = route
+	}
+	for _, key := range routeKeys {
+		route, ok := existing[key]
+		delete(existing, key)
+		if !ok {
+			log.Debug(fmt.Sprintf("creating WebSocket API route %s...", key))
+			_, err = w.client.CreateRoute(context.TODO(), &apigatewayv2.CreateRouteInput{
+				ApiId:    apiId,
+				RouteKey: aws.String(key),
+				Target:   aws.String(target),
+			})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if aws.ToString(route.Target) != target {
+			log.Debug(fmt.Sprintf("updating WebSocket API route %s...", key))
+			_, err = w.client.UpdateRoute(context.TODO(), &apigatewayv2.UpdateRouteInput{
+				ApiId:   apiId,
+				RouteId: route.RouteId,
+				Target:  aws.String(target),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for key, route := range existing {
+		log.Debug(fmt.Sprintf("deleting WebSocket API route %s...", key))
+		_, err = w.client.DeleteRoute(context.TODO(), &apigatewayv2.DeleteRouteInput{
+			ApiId:   apiId,
+			RouteId: route.RouteId,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getRoutes lists the routes of the API
+func (w *WebSocketApi) getRoutes(apiId *string) ([]agv2Types.Route, error) {
+	var routes []agv2Types.Route
+	input := &apigatewayv2.GetRoutesInput{ApiId: apiId, MaxResults: aws.String("500")}
+	for {
+		resp, err := w.client.GetRoutes(context.TODO(), input)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, resp.Items...)
+		if resp.NextToken == nil {
+			return routes, nil
+		}
+		input.NextToken = resp.NextToken
+	}
+}
+
+// setupStage creates the stage of the API if it doesn't exist. The stage is
+// deployed automatically whenever the API changes.
+func (w *WebSocketApi) setupStage(apiId *string) error {
+	_, err := w.client.GetStage(context.TODO(), &apigatewayv2.GetStageInput{
+		ApiId:     apiId,
+		StageName: aws.String(w.config.Stage),
+	})
+	if err == nil {
+		return nil
+	}
+	var nfErr *agv2Types.NotFoundException
+	if !errors.As(err, &nfErr) {
+		return err
+	}
+
+	tags, err := w.tags()
+	if err != nil {
+		return err
+	}
+
+	log.Debug("creating WebSocket API stage...")
+	_, err = w.client.CreateStage(context.TODO(), &apigatewayv2.CreateStageInput{
+		ApiId:      apiId,
+		StageName:  aws.String(w.config.Stage),
+		AutoDeploy: true,
+		Tags:       tags,
+	})
+	return err
+}
+
+// getApis lists the WebSocket APIs of the function
+func (w *WebSocketApi) getApis() ([]agv2Types.Api, error) {
+	return findApis(w.client, w.config.GetFunctionName(), agv2Types.ProtocolTypeWebsocket)
+}
+
+// delete deletes the WebSocket APIs of the function
+func (w *WebSocketApi) delete() error {
+	apis, err := w.getApis()
+	if err != nil {
+		return err
+	}
+	for _, api := range apis {
+		log.Debug(fmt.Sprintf("deleting WebSocket API %s...", aws.ToString(api.ApiId)))
+		_, err := w.client.DeleteApi(context.TODO(), &apigatewayv2.DeleteApiInput{
+			ApiId: api.ApiId,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
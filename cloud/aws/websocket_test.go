@@ -0,0 +1,154 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
+	agv2Types "github.com/aws/aws-sdk-go-v2/service/apigatewayv2/types"
+	"github.com/spatocode/jerm/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const testInvocationUri = "arn:aws:apigateway:us-west-1:lambda:path/2015-03-31/functions/" + testAliasArn + "/invocations"
+
+func TestWebSocketApiSetup(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		name         string
+		apis         []agv2Types.Api
+		integrations []agv2Types.Integration
+		routes       []agv2Types.Route
+		stage        bool
+		url          string
+		check        func(inputs []interface{})
+	}{
+		{
+			name: "create websocket api",
+			apis: []agv2Types.Api{
+				{ApiId: aws.String("http1"), Name: aws.String("test-dev"), ProtocolType: agv2Types.ProtocolTypeHttp},
+			},
+			url: "wss://new.execute-api.us-west-1.amazonaws.com/dev",
+			check: func(inputs []interface{}) {
+				created := inputs[1].(*apigatewayv2.CreateApiInput)
+				assert.Equal("test-dev", *created.Name)
+				assert.Equal(agv2Types.ProtocolTypeWebsocket, created.ProtocolType)
+				assert.Equal("$request.body.action", *created.RouteSelectionExpression)
+				assert.Equal("true", created.Tags[config.TagWebSocket])
+				integration := inputs[3].(*apigatewayv2.CreateIntegrationInput)
+				assert.Equal("new", *integration.ApiId)
+				assert.Equal(agv2Types.IntegrationTypeAwsProxy, integration.IntegrationType)
+				assert.Equal(testInvocationUri, *integration.IntegrationUri)
+				assert.Equal("arn:aws:iam::123456789012:role/test", *integration.CredentialsArn)
+				var routeKeys []string
+				for _, input := range inputs[5:9] {
+					route := input.(*apigatewayv2.CreateRouteInput)
+					assert.Equal("integrations/int1", *route.Target)
+					routeKeys = append(routeKeys, *route.RouteKey)
+				}
+				assert.Equal([]string{"$connect", "$disconnect", "$default", "sendMessage"}, routeKeys)
+				stage := inputs[10].(*apigatewayv2.CreateStageInput)
+				assert.Equal("dev", *stage.StageName)
+				assert.True(stage.AutoDeploy)
+				assert.Len(inputs, 11)
+			},
+		},
+		{
+			name: "update websocket api",
+			apis: []agv2Types.Api{
+				{ApiId: aws.String("ws1"), Name: aws.String("test-dev"), ProtocolType: agv2Types.ProtocolTypeWebsocket},
+			},
+			integrations: []agv2Types.Integration{
+				{IntegrationId: aws.String("int1"), IntegrationType: agv2Types.IntegrationTypeAwsProxy, IntegrationUri: aws.String("old"), CredentialsArn: aws.String("arn:aws:iam::123456789012:role/test")},
+			},
+			routes: []agv2Types.Route{
+				{RouteId: aws.String("r1"), RouteKey: aws.String("$connect"), Target: aws.String("integrations/int1")},
+				{RouteId: aws.String("r2"), RouteKey: aws.String("$disconnect"), Target: aws.String("integrations/int1")},
+				{RouteId: aws.String("r3"), RouteKey: aws.String("$default"), Target: aws.String("integrations/old")},
+				{RouteId: aws.String("r4"), RouteKey: aws.String("leave"), Target: aws.String("integrations/int1")},
+			},
+			stage: true,
+			url:   "wss://ws1.execute-api.us-west-1.amazonaws.com/dev",
+			check: func(inputs []interface{}) {
+				assert.Equal("ws1", *inputs[1].(*apigatewayv2.UpdateApiInput).ApiId)
+				integration := inputs[3].(*apigatewayv2.UpdateIntegrationInput)
+				assert.Equal("int1", *integration.IntegrationId)
+				assert.Equal(testInvocationUri, *integration.IntegrationUri)
+				route := inputs[5].(*apigatewayv2.UpdateRouteInput)
+				assert.Equal("r3", *route.RouteId)
+				assert.Equal("integrations/int1", *route.Target)
+				assert.Equal("sendMessage", *inputs[6].(*apigatewayv2.CreateRouteInput).RouteKey)
+				assert.Equal("r4", *inputs[7].(*apigatewayv2.DeleteRouteInput).RouteId)
+				assert.IsType(&apigatewayv2.GetStageInput{}, inputs[8])
+				assert.Len(inputs, 9)
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			withAPIOptionsFunc, recorder := mockApi(func(operation string, input interface{}) (interface{}, error) {
+				switch operation {
+				case "GetApis":
+					return &apigatewayv2.GetApisOutput{Items: tt.apis}, nil
+				case "CreateApi":
+					return &apigatewayv2.CreateApiOutput{ApiId: aws.String("new"), ApiEndpoint: aws.String("wss://new.execute-api.us-west-1.amazonaws.com")}, nil
+				case "UpdateApi":
+					return &apigatewayv2.UpdateApiOutput{ApiId: aws.String("ws1"), ApiEndpoint: aws.String("wss://ws1.execute-api.us-west-1.amazonaws.com")}, nil
+				case "GetIntegrations":
+					return &apigatewayv2.GetIntegrationsOutput{Items: tt.integrations}, nil
+				case "CreateIntegration":
+					return &apigatewayv2.CreateIntegrationOutput{IntegrationId: aws.String("int1")}, nil
+				case "UpdateIntegration":
+					return &apigatewayv2.UpdateIntegrationOutput{}, nil
+				case "GetRoutes":
+					return &apigatewayv2.GetRoutesOutput{Items: tt.routes}, nil
+				case "CreateRoute":
+					return &apigatewayv2.CreateRouteOutput{}, nil
+				case "UpdateRoute":
+					return &apigatewayv2.UpdateRouteOutput{}, nil
+				case "DeleteRoute":
+					return &apigatewayv2.DeleteRouteOutput{}, nil
+				case "GetStage":
+					if !tt.stage {
+						return nil, &agv2Types.NotFoundException{}
+					}
+					return &apigatewayv2.GetStageOutput{}, nil
+				case "CreateStage":
+					return &apigatewayv2.CreateStageOutput{}, nil
+				}
+				return nil, errUnexpectedOperation
+			})
+			l := helperLambda(t, withAPIOptionsFunc)
+			l.config.WebSocket = &config.WebSocket{Routes: []string{"sendMessage"}}
+
+			url, err := l.webSocket.setup(testAliasArn)
+			assert.Nil(err)
+			assert.Equal(tt.url, url)
+			tt.check(recorder.inputs)
+		})
+	}
+}
+
+func TestWebSocketApiDelete(t *testing.T) {
+	assert := assert.New(t)
+	withAPIOptionsFunc, recorder := mockApi(func(operation string, input interface{}) (interface{}, error) {
+		switch operation {
+		case "GetApis":
+			return &apigatewayv2.GetApisOutput{Items: []agv2Types.Api{
+				{ApiId: aws.String("http1"), Name: aws.String("test-dev"), ProtocolType: agv2Types.ProtocolTypeHttp},
+				{ApiId: aws.String("ws1"), Name: aws.String("test-dev"), ProtocolType: agv2Types.ProtocolTypeWebsocket},
+			}}, nil
+		case "DeleteApi":
+			return &apigatewayv2.DeleteApiOutput{}, nil
+		}
+		return nil, errUnexpectedOperation
+	})
+	l := helperLambda(t, withAPIOptionsFunc)
+
+	err := l.webSocket.delete()
+	assert.Nil(err)
+	assert.Len(recorder.inputs, 2)
+	assert.Equal("ws1", *recorder.inputs[1].(*apigatewayv2.DeleteApiInput).ApiId)
+}
@@ -0,0 +1,171 @@
+package azure
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/spatocode/jerm/internal/log"
+	"github.com/spatocode/jerm/internal/rest"
+	"github.com/spatocode/jerm/internal/utils"
+)
+
+const (
+	azureAuthDocsUrl    = "https://learn.microsoft.com/en-us/cli/azure/authenticate-azure-cli"
+	managementEndpoint  = "https://management.azure.com"
+	webApiVersion       = "2022-03-01"
+	storageApiVersion   = "2022-09-01"
+	resourceApiVersion  = "2021-04-01"
+	accessTokenEnv      = "AZURE_ACCESS_TOKEN"
+	subscriptionEnv     = "AZURE_SUBSCRIPTION_ID"
+	DefaultLocation     = "eastus"
+	DefaultWaitDuration = 600
+)
+
+// credentials provides Azure Resource Manager access tokens.
+// Tokens are read from the environment or from the Azure CLI.
+type credentials struct {
+	utils.ShellCommand
+	mu    sync.Mutex
+	token string
+}
+
+func newCredentials(cmd utils.ShellCommand) *credentials {
+	return &credentials{ShellCommand: cmd}
+}
+
+// authorize sets an access token on a request
+func (c *credentials) authorize(req *http.Request) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token == "" {
+		c.token = os.Getenv(accessTokenEnv)
+	}
+	if c.token == "" {
+		log.Debug("fetching access token with az account get-access-token...")
+		out, err := c.RunCommand("az", "account", "get-access-token", "--query", "accessToken", "-o", "tsv")
+		if err != nil || strings.TrimSpace(out) == "" {
+			msg := fmt.Sprintf("Unable to get Azure credentials. Ensure you're logged in with the Azure CLI before using Jerm. See here for more info %s", azureAuthDocsUrl)
+			return errors.New(msg)
+		}
+		c.token = strings.TrimSpace(out)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	return nil
+}
+
+// subscription returns the configured Azure subscription
+// or the default subscription of the Azure CLI
+func (c *credentials) subscription(cfg *config.Config) (string, error) {
+	if cfg.Platform.Subscription != "" {
+		return cfg.Platform.Subscription, nil
+	}
+	if s := os.Getenv(subscriptionEnv); s != "" {
+		return s, nil
+	}
+
+	out, err := c.RunCommand("az", "account", "show", "--query", "id", "-o", "tsv")
+	if err != nil || strings.TrimSpace(out) == "" {
+		return "", errors.New("cannot find an Azure subscription. Please specify subscription in your jerm.json file")
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// arm is an Azure Resource Manager client which waits on long running operations
+type arm struct {
+	*rest.Client
+	maxWaiterDuration time.Duration
+	pollInterval      time.Duration
+}
+
+// Do sends a JSON request and waits for the operation to complete
+// if Azure accepts it as a long running operation.
+func (a *arm) Do(method, path string, in, out interface{}) error {
+	req, err := a.NewJSONRequest(method, path, in)
+	if err != nil {
+		return err
+	}
+	res, err := a.Response(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	location := res.Header.Get("Location")
+	if res.StatusCode != http.StatusAccepted || location == "" {
+		return rest.Decode(res, out)
+	}
+
+	return a.wait(location, out)
+}
+
+// wait polls a long running operation location until it's done
+func (a *arm) wait(location string, out interface{}) error {
+	deadline := time.Now().Add(time.Second * a.maxWaiterDuration)
+	for {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for operation %s", location)
+		}
+		time.Sleep(a.pollInterval)
+		log.Debug(fmt.Sprintf("waiting for operation %s...", location))
+
+		req, err := a.NewRequest(http.MethodGet, location, nil)
+		if err != nil {
+			return err
+		}
+		res, err := a.Response(req)
+		if err != nil {
+			return err
+		}
+		if res.StatusCode != http.StatusAccepted {
+			err = rest.Decode(res, out)
+			res.Body.Close()
+			return err
+		}
+		res.Body.Close()
+	}
+}
+
+// waitForProvisioning polls a resource until it's provisioned
+func (a *arm) waitForProvisioning(path string) error {
+	deadline := time.Now().Add(time.Second * a.maxWaiterDuration)
+	for {
+		resource := struct {
+			Properties struct {
+				ProvisioningState string `json:"provisioningState"`
+			} `json:"properties"`
+		}{}
+		err := a.Do(http.MethodGet, path, nil, &resource)
+		if err != nil {
+			return err
+		}
+
+		switch resource.Properties.ProvisioningState {
+		case "", "Succeeded":
+			return nil
+		case "Failed", "Canceled":
+			return fmt.Errorf("provisioning of %s %s", path, strings.ToLower(resource.Properties.ProvisioningState))
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for %s provisioning", path)
+		}
+		time.Sleep(a.pollInterval)
+	}
+}
+
+// hostUrl returns the URL of a host.
+// When an endpoint is configured, hosts are addressed as paths of the endpoint
+// so that a single local stand-in can serve every API.
+func hostUrl(cfg *config.Config, host string) string {
+	if cfg.Platform.Endpoint != "" {
+		return fmt.Sprintf("%s/%s", strings.TrimSuffix(cfg.Platform.Endpoint, "/"), host)
+	}
+	return fmt.Sprintf("https://%s", host)
+}
@@ -0,0 +1,759 @@
+package azure
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spatocode/jerm"
+	"github.com/spatocode/jerm/config"
+	"github.com/spatocode/jerm/config/handlers"
+	"github.com/spatocode/jerm/internal/log"
+	"github.com/spatocode/jerm/internal/rest"
+	"github.com/spatocode/jerm/internal/utils"
+)
+
+const (
+	stagingSlot       = "staging"
+	deploymentSetting = "JERM_DEPLOYMENT"
+	manageFunction    = "jerm_manage"
+	manageRoute       = "jerm/manage"
+	consumptionPlan   = "Y1"
+)
+
+func init() {
+	jerm.RegisterPlatform(config.AzureFunctions, func(cfg *config.Config) (jerm.CloudPlatform, error) {
+		f, err := NewFunctions(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return f, nil
+	})
+}
+
+// Functions is the Azure Functions operations.
+// Updates are deployed to a staging slot which is then swapped into production,
+// so the staging slot always holds the previous deployment for rollbacks.
+type Functions struct {
+	credentials   *credentials
+	monitor       jerm.CloudMonitor
+	config        *config.Config
+	subscription  string
+	resourceGroup string
+	location      string
+	client        *arm
+	kudu          *rest.Client
+}
+
+type site struct {
+	Properties struct {
+		DefaultHostName string `json:"defaultHostName"`
+		State           string `json:"state"`
+	} `json:"properties"`
+}
+
+// NewFunctions instantiates a new Azure Functions service
+func NewFunctions(cfg *config.Config) (*Functions, error) {
+	f := &Functions{
+		credentials:   newCredentials(utils.Command()),
+		config:        cfg,
+		location:      cfg.Region,
+		resourceGroup: cfg.Platform.ResourceGroup,
+	}
+
+	if f.config.Platform.Name == "" {
+		f.config.Platform.Name = config.AzureFunctions
+	}
+	err := f.config.Platform.Defaults()
+	if err != nil {
+		return nil, err
+	}
+
+	if f.location == "" {
+		f.location = DefaultLocation
+	}
+	if f.resourceGroup == "" {
+		f.resourceGroup = cfg.GetFunctionName()
+	}
+
+	f.subscription, err = f.credentials.subscription(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := managementEndpoint
+	if cfg.Platform.Endpoint != "" {
+		endpoint = cfg.Platform.Endpoint
+	}
+	f.client = &arm{
+		Client:            rest.NewClient(endpoint, f.credentials.authorize),
+		maxWaiterDuration: DefaultWaitDuration,
+		pollInterval:      time.Second * 3,
+	}
+	f.kudu = rest.NewClient("", f.credentials.authorize)
+	f.monitor = NewLogStream(cfg, f.kudu)
+
+	return f, nil
+}
+
+func (f *Functions) WithMonitor(monitor jerm.CloudMonitor) {
+	f.monitor = monitor
+}
+
+// Build builds the deployment package for Azure Functions
+func (f *Functions) Build() (string, error) {
+	log.Debug("building Jerm project for Azure Functions...")
+
+	r := config.NewRuntime()
+
+	go func() {
+		err := f.config.ToJson(jerm.DefaultConfigFile)
+		if err != nil {
+			log.PrintWarn(err)
+		}
+	}()
+
+	var (
+		packageDir, function string
+		err                  error
+	)
+	switch runtime := r.(type) {
+	case *config.Go:
+		// custom handlers run the executable built from the project
+		_, function, err = runtime.Build(f.config)
+		if err != nil {
+			return "", err
+		}
+		packageDir, _, err = runtime.Runtime.Build(f.config)
+	case *config.Python:
+		runtime.WithHandlerTemplate(handlers.AzureFunctionsHandlerDjango)
+		packageDir, function, err = r.Build(f.config)
+	case *config.Runtime:
+		runtime.WithHandlerTemplate(handlers.AzureFunctionsHandlerStaticPage)
+		packageDir, function, err = r.Build(f.config)
+	default:
+		packageDir, function, err = r.Build(f.config)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	err = f.createHostFiles(packageDir, function)
+	if err != nil {
+		return "", err
+	}
+
+	return packageDir, nil
+}
+
+// createHostFiles generates the host.json file of the Function App and the
+// function.json file of its HTTP function. Django projects also get an
+// admin level function which runs management commands.
+func (f *Functions) createHostFiles(packageDir, function string) error {
+	if function == "" {
+		return errors.New("cannot find a function handler. Please specify handler in your jerm.json file")
+	}
+
+	host := map[string]interface{}{
+		"version": "2.0",
+		"extensionBundle": map[string]string{
+			"id":      "Microsoft.Azure.Functions.ExtensionBundle",
+			"version": "[4.*, 5.0.0)",
+		},
+		"extensions": map[string]interface{}{
+			"http": map[string]string{"routePrefix": ""},
+		},
+	}
+
+	name := f.functionName()
+	module := strings.TrimSuffix(function, "."+name)
+	functions := map[string]map[string]interface{}{}
+	switch strings.Split(f.config.Platform.Runtime, "|")[0] {
+	case "custom":
+		host["customHandler"] = map[string]interface{}{
+			"description":                 map[string]string{"defaultExecutablePath": function},
+			"enableForwardingHttpRequest": true,
+		}
+		functions[name] = f.httpFunction("anonymous", "{*path}", "res")
+	case "python":
+		err := f.createPythonPackage(packageDir, module, name)
+		if err != nil {
+			return err
+		}
+		functions[name] = f.httpFunction("anonymous", "{*path}", "$return")
+		functions[name]["scriptFile"] = "__init__.py"
+		functions[name]["entryPoint"] = name
+		if f.config.Platform.Handler == "" {
+			functions[manageFunction] = f.httpFunction("admin", manageRoute, "$return")
+			functions[manageFunction]["scriptFile"] = fmt.Sprintf("../%s/__init__.py", name)
+			functions[manageFunction]["entryPoint"] = "manage"
+		}
+	default:
+		functions[name] = f.httpFunction("anonymous", "{*path}", "$return")
+		functions[name]["scriptFile"] = fmt.Sprintf("../%s.js", module)
+		functions[name]["entryPoint"] = name
+	}
+
+	hostFile := filepath.Join(packageDir, "host.json")
+	if !utils.FileExists(hostFile) {
+		err := f.writeJson(hostFile, host)
+		if err != nil {
+			return err
+		}
+	}
+
+	for fn, content := range functions {
+		err := os.MkdirAll(filepath.Join(packageDir, fn), 0755)
+		if err != nil {
+			return err
+		}
+		err = f.writeJson(filepath.Join(packageDir, fn, "function.json"), content)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// httpFunction returns the function.json content of an HTTP triggered function
+func (f *Functions) httpFunction(authLevel, route, output string) map[string]interface{} {
+	return map[string]interface{}{
+		"bindings": []map[string]string{
+			{"type": "httpTrigger", "direction": "in", "name": "req", "authLevel": authLevel, "route": route},
+			{"type": "http", "direction": "out", "name": output},
+		},
+	}
+}
+
+// createPythonPackage makes the handler importable from the package of the
+// function, which is where Azure Functions loads Python functions from
+func (f *Functions) createPythonPackage(packageDir, module, name string) error {
+	dir := filepath.Join(packageDir, name)
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	initFile := filepath.Join(dir, "__init__.py")
+	if f.config.Platform.Handler == "" {
+		// the generated handler is written to handler.py
+		return os.Rename(filepath.Join(packageDir, module+".py"), initFile)
+	}
+	content := fmt.Sprintf("from %s import %s\n", module, name)
+	return os.WriteFile(initFile, []byte(content), 0644)
+}
+
+func (f *Functions) writeJson(file string, content interface{}) error {
+	b, err := json.MarshalIndent(content, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, b, 0644)
+}
+
+// Deploy creates the Function App and its resources and deploys the package to it
+func (f *Functions) Deploy(zipPath string) (bool, error) {
+	deployed, err := f.isAlreadyDeployed()
+	if err != nil {
+		return false, err
+	}
+
+	if deployed {
+		return true, nil
+	}
+
+	err = f.createResourceGroup()
+	if err != nil {
+		return false, err
+	}
+
+	connection, err := f.createStorageAccount()
+	if err != nil {
+		return false, err
+	}
+
+	planId, err := f.createPlan()
+	if err != nil {
+		return false, err
+	}
+
+	err = f.createSite("", planId, connection)
+	if err != nil {
+		return false, err
+	}
+
+	err = f.createSite(stagingSlot, planId, connection)
+	if err != nil {
+		return false, err
+	}
+
+	err = f.zipDeploy("", zipPath)
+	if err != nil {
+		return false, err
+	}
+
+	err = f.printUrl()
+	if err != nil {
+		return false, err
+	}
+
+	err = utils.RemoveLocalFile(zipPath)
+	if err != nil {
+		return false, err
+	}
+
+	return false, nil
+}
+
+// Update deploys the package to the staging slot and swaps it into production
+func (f *Functions) Update(zipPath string) error {
+	settings, err := f.getAppSettings(stagingSlot)
+	if err != nil {
+		return err
+	}
+	settings[deploymentSetting] = strconv.FormatInt(time.Now().Unix(), 10)
+	err = f.client.Do(http.MethodPut, f.apiPath(f.sitePath(stagingSlot)+"/config/appsettings", webApiVersion),
+		map[string]interface{}{"properties": settings}, nil)
+	if err != nil {
+		return err
+	}
+
+	err = f.zipDeploy(stagingSlot, zipPath)
+	if err != nil {
+		return err
+	}
+
+	err = f.swap()
+	if err != nil {
+		return err
+	}
+
+	err = f.printUrl()
+	if err != nil {
+		return err
+	}
+
+	err = utils.RemoveLocalFile(zipPath)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Undeploy deletes the Function App, its slots, plan and storage account,
+// and the resource group if jerm created it
+func (f *Functions) Undeploy() error {
+	deployed, err := f.isAlreadyDeployed()
+	if err != nil {
+		return err
+	}
+	if !deployed {
+		msg := "can't find a deployed project. Run 'jerm deploy' to deploy instead"
+		return errors.New(msg)
+	}
+
+	log.Debug("undeploying...")
+	path := fmt.Sprintf("%s&deleteEmptyServerFarm=true", f.apiPath(f.sitePath(""), webApiVersion))
+	err = f.client.Do(http.MethodDelete, path, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	err = f.client.Do(http.MethodDelete, f.apiPath(f.resourcePath("Microsoft.Web/serverfarms", f.planName()), webApiVersion), nil, nil)
+	if err != nil && !rest.IsNotFound(err) {
+		return err
+	}
+
+	err = f.client.Do(http.MethodDelete, f.apiPath(f.storageAccountPath(), storageApiVersion), nil, nil)
+	if err != nil && !rest.IsNotFound(err) {
+		return err
+	}
+
+	owned, err := f.isResourceGroupOwned()
+	if err != nil {
+		return err
+	}
+	if owned {
+		log.Debug(fmt.Sprintf("deleting resource group %s...", f.resourceGroup))
+		err = f.client.Do(http.MethodDelete, f.apiPath(f.resourceGroupPath(), resourceApiVersion), nil, nil)
+		if err != nil && !rest.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Rollback swaps the previous deployment held by the staging slot back into production.
+// Deployment slots only keep a single previous deployment.
+func (f *Functions) Rollback(steps int) error {
+	deployed, err := f.isAlreadyDeployed()
+	if err != nil {
+		return err
+	}
+	if !deployed {
+		msg := "can't find a deployed project. Run 'jerm deploy' to deploy instead"
+		return errors.New(msg)
+	}
+
+	if steps != 1 {
+		return errors.New("rollback with deployment slots only supports a single step. Aborting")
+	}
+
+	settings, err := f.getAppSettings(stagingSlot)
+	if err != nil {
+		return err
+	}
+	if settings[deploymentSetting] == "" {
+		msg := "invalid revision for rollback. Aborting"
+		return errors.New(msg)
+	}
+
+	return f.swap()
+}
+
+// Logs streams the Function App logs
+func (f *Functions) Logs() {
+	f.monitor.Watch()
+}
+
+// Invoke invokes the management function with a command using the master key
+func (f *Functions) Invoke(command string) error {
+	keys := struct {
+		MasterKey string `json:"masterKey"`
+	}{}
+	err := f.client.Do(http.MethodPost, f.apiPath(f.sitePath("")+"/host/default/listkeys", webApiVersion), nil, &keys)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/%s", hostUrl(f.config, f.hostName("", false)), manageRoute)
+	req, err := f.kudu.NewJSONRequest(http.MethodPost, url, map[string]string{"manage": command})
+	if err != nil {
+		return err
+	}
+	req.Header.Set("x-functions-key", keys.MasterKey)
+
+	res, err := f.kudu.Response(req)
+	if err != nil {
+		return fmt.Errorf("%s - encountered an error while invoking function", err)
+	}
+	defer res.Body.Close()
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	log.PrintInfo(string(b))
+	return nil
+}
+
+func (f *Functions) isAlreadyDeployed() (bool, error) {
+	err := f.client.Do(http.MethodGet, f.apiPath(f.sitePath(""), webApiVersion), nil, nil)
+	if err != nil {
+		if rest.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// createResourceGroup creates the resource group unless it exists.
+// Resource groups created by jerm are tagged so that Undeploy deletes them.
+func (f *Functions) createResourceGroup() error {
+	path := f.apiPath(f.resourceGroupPath(), resourceApiVersion)
+	err := f.client.Do(http.MethodGet, path, nil, nil)
+	if err == nil || !rest.IsNotFound(err) {
+		return err
+	}
+
+	log.Debug(fmt.Sprintf("creating resource group %s...", f.resourceGroup))
+	tags, err := f.config.GetTags()
+	if err != nil {
+		return err
+	}
+	group := map[string]interface{}{
+		"location": f.location,
+		"tags":     tags,
+	}
+	return f.client.Do(http.MethodPut, path, group, nil)
+}
+
+// isResourceGroupOwned checks if the resource group was created by jerm for the project
+func (f *Functions) isResourceGroupOwned() (bool, error) {
+	group := struct {
+		Tags map[string]string `json:"tags"`
+	}{}
+	err := f.client.Do(http.MethodGet, f.apiPath(f.resourceGroupPath(), resourceApiVersion), nil, &group)
+	if err != nil {
+		if rest.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return group.Tags[config.TagProject] == f.config.Name && group.Tags[config.TagStage] == f.config.Stage, nil
+}
+
+// createStorageAccount creates the storage account required by
+// the Function App and returns its connection string
+func (f *Functions) createStorageAccount() (string, error) {
+	log.Debug(fmt.Sprintf("creating storage account %s...", f.storageAccountName()))
+	path := f.storageAccountPath()
+	account := map[string]interface{}{
+		"location": f.location,
+		"kind":     "StorageV2",
+		"sku":      map[string]string{"name": "Standard_LRS"},
+	}
+	err := f.client.Do(http.MethodPut, f.apiPath(path, storageApiVersion), account, nil)
+	if err != nil {
+		return "", err
+	}
+
+	err = f.client.waitForProvisioning(f.apiPath(path, storageApiVersion))
+	if err != nil {
+		return "", err
+	}
+
+	keys := struct {
+		Keys []struct {
+			Value string `json:"value"`
+		} `json:"keys"`
+	}{}
+	err = f.client.Do(http.MethodPost, f.apiPath(path+"/listKeys", storageApiVersion), nil, &keys)
+	if err != nil {
+		return "", err
+	}
+	if len(keys.Keys) == 0 {
+		return "", fmt.Errorf("cannot find keys of storage account %s", f.storageAccountName())
+	}
+
+	connection := fmt.Sprintf("DefaultEndpointsProtocol=https;AccountName=%s;AccountKey=%s;EndpointSuffix=core.windows.net",
+		f.storageAccountName(), keys.Keys[0].Value)
+	return connection, nil
+}
+
+// createPlan creates the plan of the Function App. It defaults to the
+// Consumption plan, and Elastic Premium plans are created for EP SKUs.
+func (f *Functions) createPlan() (string, error) {
+	log.Debug(fmt.Sprintf("creating app service plan %s...", f.planName()))
+	path := f.resourcePath("Microsoft.Web/serverfarms", f.planName())
+	sku := f.config.Platform.Plan
+	if sku == "" {
+		sku = consumptionPlan
+	}
+
+	var plan map[string]interface{}
+	switch {
+	case sku == consumptionPlan:
+		plan = map[string]interface{}{
+			"location":   f.location,
+			"kind":       "functionapp",
+			"sku":        map[string]string{"name": sku, "tier": "Dynamic"},
+			"properties": map[string]interface{}{"reserved": true},
+		}
+	case strings.HasPrefix(sku, "EP"):
+		plan = map[string]interface{}{
+			"location": f.location,
+			"kind":     "elastic",
+			"sku":      map[string]string{"name": sku, "tier": "ElasticPremium"},
+			"properties": map[string]interface{}{
+				"reserved":                  true,
+				"maximumElasticWorkerCount": 20,
+			},
+		}
+	default:
+		return "", fmt.Errorf("unsupported plan %s. Azure Functions plan must be Y1 or one of EP1, EP2 and EP3", sku)
+	}
+	err := f.client.Do(http.MethodPut, f.apiPath(path, webApiVersion), plan, nil)
+	if err != nil {
+		return "", err
+	}
+	return path, f.client.waitForProvisioning(f.apiPath(path, webApiVersion))
+}
+
+// createSite creates the Function App or one of its slots
+func (f *Functions) createSite(slot, planId, connection string) error {
+	path := f.sitePath(slot)
+	log.Debug(fmt.Sprintf("creating function app %s...", f.hostName(slot, false)))
+	stack := strings.Split(f.config.Platform.Runtime, "|")
+	settings := []map[string]string{
+		{"name": "FUNCTIONS_EXTENSION_VERSION", "value": "~4"},
+		{"name": "FUNCTIONS_WORKER_RUNTIME", "value": stack[0]},
+		{"name": "AzureWebJobsStorage", "value": connection},
+		{"name": "WEBSITE_CONTENTAZUREFILECONNECTIONSTRING", "value": connection},
+		{"name": "WEBSITE_CONTENTSHARE", "value": strings.ToLower(strings.Split(f.hostName(slot, false), ".")[0])},
+	}
+	if slot == "" {
+		// marks the content of the slot as a jerm deployment
+		settings = append(settings, map[string]string{"name": deploymentSetting, "value": strconv.FormatInt(time.Now().Unix(), 10)})
+	}
+	siteConfig := map[string]interface{}{
+		"appSettings": settings,
+	}
+	if len(stack) > 1 {
+		siteConfig["linuxFxVersion"] = strings.ToUpper(f.config.Platform.Runtime)
+	}
+	site := map[string]interface{}{
+		"location": f.location,
+		"kind":     "functionapp,linux",
+		"properties": map[string]interface{}{
+			"serverFarmId": planId,
+			"reserved":     true,
+			"siteConfig":   siteConfig,
+		},
+	}
+	err := f.client.Do(http.MethodPut, f.apiPath(path, webApiVersion), site, nil)
+	if err != nil {
+		return err
+	}
+	return f.client.waitForProvisioning(f.apiPath(path, webApiVersion))
+}
+
+// zipDeploy deploys a package to a slot through the Kudu zip deploy API
+func (f *Functions) zipDeploy(slot, zipPath string) error {
+	log.Debug(fmt.Sprintf("deploying package to function app %s...", f.hostName(slot, false)))
+	file, err := os.Open(zipPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scm := hostUrl(f.config, f.hostName(slot, true))
+	req, err := f.kudu.NewRequest(http.MethodPost, scm+"/api/zipdeploy?isAsync=true", file)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/zip")
+	err = f.kudu.Send(req, nil)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(time.Second * f.client.maxWaiterDuration)
+	for {
+		deployment := struct {
+			Status   int    `json:"status"`
+			Complete bool   `json:"complete"`
+			Message  string `json:"status_text"`
+		}{}
+		err = f.kudu.Do(http.MethodGet, scm+"/api/deployments/latest", nil, &deployment)
+		if err != nil {
+			return err
+		}
+		if deployment.Complete {
+			// Kudu marks failed deployments with status 3 and successful ones with 4
+			if deployment.Status == 3 {
+				return fmt.Errorf("zip deployment failed. %s", deployment.Message)
+			}
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.New("timed out waiting for zip deployment")
+		}
+		time.Sleep(f.client.pollInterval)
+	}
+}
+
+// swap swaps the staging slot into production
+func (f *Functions) swap() error {
+	log.Debug("swapping staging slot into production...")
+	swap := map[string]interface{}{"targetSlot": "production", "preserveVnet": true}
+	return f.client.Do(http.MethodPost, f.apiPath(f.sitePath(stagingSlot)+"/slotsswap", webApiVersion), swap, nil)
+}
+
+func (f *Functions) getAppSettings(slot string) (map[string]string, error) {
+	settings := struct {
+		Properties map[string]string `json:"properties"`
+	}{}
+	err := f.client.Do(http.MethodPost, f.apiPath(f.sitePath(slot)+"/config/appsettings/list", webApiVersion), nil, &settings)
+	if err != nil {
+		return nil, err
+	}
+	if settings.Properties == nil {
+		settings.Properties = make(map[string]string)
+	}
+	return settings.Properties, nil
+}
+
+func (f *Functions) printUrl() error {
+	s := &site{}
+	err := f.client.Do(http.MethodGet, f.apiPath(f.sitePath(""), webApiVersion), nil, s)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s %s\n", log.Magenta("url:"), log.Green(fmt.Sprintf("https://%s", s.Properties.DefaultHostName)))
+	return nil
+}
+
+// functionName is the name of the function within the Function App
+func (f *Functions) functionName() string {
+	handler := f.config.Platform.Handler
+	if handler == "" {
+		return config.DefaultServerlessFunction
+	}
+	s := strings.Split(handler, ".")
+	return s[len(s)-1]
+}
+
+// hostName returns the app or SCM host name of a slot
+func (f *Functions) hostName(slot string, scm bool) string {
+	name := f.config.GetFunctionName()
+	if slot != "" {
+		name = fmt.Sprintf("%s-%s", name, slot)
+	}
+	if scm {
+		return fmt.Sprintf("%s.scm.azurewebsites.net", name)
+	}
+	return fmt.Sprintf("%s.azurewebsites.net", name)
+}
+
+func (f *Functions) resourceGroupPath() string {
+	return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s", f.subscription, f.resourceGroup)
+}
+
+func (f *Functions) resourcePath(provider, name string) string {
+	return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/%s/%s", f.subscription, f.resourceGroup, provider, name)
+}
+
+// sitePath returns the resource path of the Function App or one of its slots
+func (f *Functions) sitePath(slot string) string {
+	path := f.resourcePath("Microsoft.Web/sites", f.config.GetFunctionName())
+	if slot != "" {
+		path = fmt.Sprintf("%s/slots/%s", path, slot)
+	}
+	return path
+}
+
+func (f *Functions) storageAccountPath() string {
+	return f.resourcePath("Microsoft.Storage/storageAccounts", f.storageAccountName())
+}
+
+// storageAccountName derives a valid storage account name from the bucket
+func (f *Functions) storageAccountName() string {
+	name := regexp.MustCompile("[^a-z0-9]").ReplaceAllString(strings.ToLower(f.config.Bucket), "")
+	if len(name) > 24 {
+		name = name[:24]
+	}
+	for len(name) < 3 {
+		name += "0"
+	}
+	return name
+}
+
+func (f *Functions) planName() string {
+	return fmt.Sprintf("%s-plan", f.config.GetFunctionName())
+}
+
+func (f *Functions) apiPath(path, version string) string {
+	return fmt.Sprintf("%s?api-version=%s", path, version)
+}
@@ -0,0 +1,313 @@
+package azure
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/spatocode/jerm/config/handlers"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAzure is a local stand-in for the Azure Resource Manager,
+// Kudu and Function App host APIs
+type fakeAzure struct {
+	mu         sync.Mutex
+	resources  map[string]bool
+	groups     map[string]map[string]string
+	plan       string
+	content    map[string]string
+	settings   map[string]map[string]string
+	invoked    string
+	operations int
+}
+
+func newFakeAzure(t *testing.T) (*fakeAzure, *httptest.Server) {
+	fake := &fakeAzure{
+		resources: make(map[string]bool),
+		groups:    make(map[string]map[string]string),
+		content:   make(map[string]string),
+		settings:  map[string]map[string]string{"production": {}, "staging": {}},
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func (a *fakeAzure) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	path := r.URL.Path
+	slot := "production"
+	if strings.Contains(path, "/slots/staging") || strings.HasPrefix(path, "/test-dev-staging.") {
+		slot = "staging"
+	}
+
+	switch {
+	case strings.HasPrefix(path, "/operations/"):
+		a.operations++
+		if a.operations < 2 {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		a.content["production"], a.content["staging"] = a.content["staging"], a.content["production"]
+		a.settings["production"], a.settings["staging"] = a.settings["staging"], a.settings["production"]
+	case strings.HasSuffix(path, "/slotsswap"):
+		a.operations = 0
+		w.Header().Set("Location", "http://"+r.Host+"/operations/swap")
+		w.WriteHeader(http.StatusAccepted)
+	case strings.HasSuffix(path, "/config/appsettings/list"):
+		json.NewEncoder(w).Encode(map[string]interface{}{"properties": a.settings[slot]})
+	case strings.HasSuffix(path, "/config/appsettings"):
+		body := struct {
+			Properties map[string]string `json:"properties"`
+		}{}
+		json.NewDecoder(r.Body).Decode(&body)
+		a.settings[slot] = body.Properties
+	case strings.HasSuffix(path, "/storageAccounts/testbucket/listKeys"):
+		w.Write([]byte(`{"keys": [{"value": "key"}]}`))
+	case strings.HasSuffix(path, "/host/default/listkeys"):
+		w.Write([]byte(`{"masterKey": "master"}`))
+	case strings.HasSuffix(path, "/api/zipdeploy"):
+		b, _ := io.ReadAll(r.Body)
+		a.content[slot] = string(b)
+		w.WriteHeader(http.StatusAccepted)
+	case strings.HasSuffix(path, "/api/deployments/latest"):
+		w.Write([]byte(`{"status": 4, "complete": true}`))
+	case strings.HasSuffix(path, "/api/logstream"):
+		w.Write([]byte("2023-01-01 connected\nhello\n"))
+	case strings.HasSuffix(path, "/jerm/manage"):
+		if r.Header.Get("x-functions-key") != "master" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		b, _ := io.ReadAll(r.Body)
+		a.invoked = string(b)
+		w.WriteHeader(http.StatusAccepted)
+	case strings.HasPrefix(path, "/subscriptions/"):
+		switch r.Method {
+		case http.MethodPut:
+			a.resources[path] = true
+			if !strings.Contains(path, "/providers/") {
+				body := struct {
+					Tags map[string]string `json:"tags"`
+				}{}
+				json.NewDecoder(r.Body).Decode(&body)
+				a.groups[path] = body.Tags
+			}
+			if strings.Contains(path, "/serverfarms/") {
+				body := struct {
+					Sku struct {
+						Name string `json:"name"`
+					} `json:"sku"`
+				}{}
+				json.NewDecoder(r.Body).Decode(&body)
+				a.plan = body.Sku.Name
+			}
+			if strings.Contains(path, "/sites/") {
+				body := struct {
+					Properties struct {
+						SiteConfig struct {
+							AppSettings []map[string]string `json:"appSettings"`
+						} `json:"siteConfig"`
+					} `json:"properties"`
+				}{}
+				json.NewDecoder(r.Body).Decode(&body)
+				for _, setting := range body.Properties.SiteConfig.AppSettings {
+					a.settings[slot][setting["name"]] = setting["value"]
+				}
+			}
+			w.Write([]byte(`{"properties": {"provisioningState": "Succeeded"}}`))
+		case http.MethodDelete:
+			if !a.resources[path] {
+				http.NotFound(w, r)
+				return
+			}
+			for resource := range a.resources {
+				if strings.HasPrefix(resource, path) {
+					delete(a.resources, resource)
+				}
+			}
+		case http.MethodGet:
+			if !a.resources[path] {
+				http.NotFound(w, r)
+				return
+			}
+			if tags, ok := a.groups[path]; ok {
+				json.NewEncoder(w).Encode(map[string]interface{}{"tags": tags})
+				return
+			}
+			w.Write([]byte(`{"properties": {"defaultHostName": "test-dev.azurewebsites.net", "provisioningState": "Succeeded"}}`))
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func helperFunctions(t *testing.T, endpoint string) *Functions {
+	t.Setenv(accessTokenEnv, "token")
+	cfg := &config.Config{
+		Name:   "test",
+		Stage:  "dev",
+		Bucket: "test-bucket",
+		Platform: config.Platform{
+			Name:          config.AzureFunctions,
+			Runtime:       "python|3.11",
+			Subscription:  "sub",
+			ResourceGroup: "group",
+			Endpoint:      endpoint,
+		},
+	}
+	f, err := NewFunctions(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.client.pollInterval = time.Millisecond
+	return f
+}
+
+func helperArchive(t *testing.T, content string) string {
+	archive := filepath.Join(t.TempDir(), "jerm.zip")
+	err := os.WriteFile(archive, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return archive
+}
+
+func TestNewFunctions(t *testing.T) {
+	assert := assert.New(t)
+	f := helperFunctions(t, "http://localhost")
+	assert.Equal("sub", f.subscription)
+	assert.Equal("group", f.resourceGroup)
+	assert.Equal(DefaultLocation, f.location)
+	assert.Equal("testbucket", f.storageAccountName())
+	assert.Equal("test-dev-staging.scm.azurewebsites.net", f.hostName(stagingSlot, true))
+	assert.Equal("/subscriptions/sub/resourceGroups/group/providers/Microsoft.Web/sites/test-dev/slots/staging", f.sitePath(stagingSlot))
+	assert.Equal("http://localhost/test-dev.azurewebsites.net", hostUrl(f.config, f.hostName("", false)))
+}
+
+func TestFunctionsDeployLifecycle(t *testing.T) {
+	assert := assert.New(t)
+	fake, server := newFakeAzure(t)
+	f := helperFunctions(t, server.URL)
+
+	err := f.Rollback(1)
+	assert.EqualError(err, "can't find a deployed project. Run 'jerm deploy' to deploy instead")
+
+	deployed, err := f.Deploy(helperArchive(t, "v1"))
+	assert.Nil(err)
+	assert.False(deployed)
+	assert.Equal("v1", fake.content["production"])
+	assert.True(fake.resources[f.sitePath(stagingSlot)])
+	assert.True(fake.resources[f.storageAccountPath()])
+
+	err = f.Rollback(1)
+	assert.EqualError(err, "invalid revision for rollback. Aborting")
+
+	deployed, err = f.Deploy(helperArchive(t, "v2"))
+	assert.Nil(err)
+	assert.True(deployed)
+
+	err = f.Update(helperArchive(t, "v2"))
+	assert.Nil(err)
+	assert.Equal("v2", fake.content["production"])
+	assert.Equal("v1", fake.content["staging"])
+
+	err = f.Rollback(2)
+	assert.EqualError(err, "rollback with deployment slots only supports a single step. Aborting")
+	err = f.Rollback(1)
+	assert.Nil(err)
+	assert.Equal("v1", fake.content["production"])
+
+	err = f.Invoke("migrate")
+	assert.Nil(err)
+	assert.Equal(`{"manage":"migrate"}`, fake.invoked)
+
+	err = f.Undeploy()
+	assert.Nil(err)
+	assert.False(fake.resources[f.sitePath("")])
+	assert.False(fake.resources[f.storageAccountPath()])
+	assert.False(fake.resources[f.resourceGroupPath()])
+}
+
+func TestFunctionsUndeployKeepsResourceGroup(t *testing.T) {
+	assert := assert.New(t)
+	fake, server := newFakeAzure(t)
+	f := helperFunctions(t, server.URL)
+	fake.resources[f.resourceGroupPath()] = true
+
+	_, err := f.Deploy(helperArchive(t, "v1"))
+	assert.Nil(err)
+	assert.Equal("Y1", fake.plan)
+
+	err = f.Undeploy()
+	assert.Nil(err)
+	assert.False(fake.resources[f.sitePath("")])
+	assert.True(fake.resources[f.resourceGroupPath()])
+}
+
+func TestFunctionsCreateHostFiles(t *testing.T) {
+	assert := assert.New(t)
+	f := helperFunctions(t, "http://localhost")
+
+	readJson := func(file string) map[string]interface{} {
+		content := map[string]interface{}{}
+		b, err := os.ReadFile(file)
+		assert.Nil(err)
+		assert.Nil(json.Unmarshal(b, &content))
+		return content
+	}
+
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "handler.py"), []byte(handlers.AzureFunctionsHandlerDjango), 0644)
+	assert.Nil(err)
+	err = f.createHostFiles(dir, "handler.handler")
+	assert.Nil(err)
+	host := readJson(filepath.Join(dir, "host.json"))
+	assert.Equal("2.0", host["version"])
+	assert.Nil(host["customHandler"])
+	fn := readJson(filepath.Join(dir, "handler", "function.json"))
+	assert.Equal("__init__.py", fn["scriptFile"])
+	assert.Equal("handler", fn["entryPoint"])
+	assert.Contains(fn["bindings"], map[string]interface{}{
+		"type": "httpTrigger", "direction": "in", "name": "req", "authLevel": "anonymous", "route": "{*path}",
+	})
+	manage := readJson(filepath.Join(dir, manageFunction, "function.json"))
+	assert.Equal("../handler/__init__.py", manage["scriptFile"])
+	assert.Equal("manage", manage["entryPoint"])
+	b, err := os.ReadFile(filepath.Join(dir, "handler", "__init__.py"))
+	assert.Nil(err)
+	assert.Contains(string(b), "func.WsgiMiddleware(application)")
+
+	f.config.Platform.Runtime = "node|18"
+	dir = t.TempDir()
+	err = f.createHostFiles(dir, "index.handler")
+	assert.Nil(err)
+	fn = readJson(filepath.Join(dir, "handler", "function.json"))
+	assert.Equal("../index.js", fn["scriptFile"])
+	assert.NoDirExists(filepath.Join(dir, manageFunction))
+
+	f.config.Platform.Runtime = "custom"
+	dir = t.TempDir()
+	err = f.createHostFiles(dir, "main")
+	assert.Nil(err)
+	host = readJson(filepath.Join(dir, "host.json"))
+	assert.Equal(map[string]interface{}{
+		"description":                 map[string]interface{}{"defaultExecutablePath": "main"},
+		"enableForwardingHttpRequest": true,
+	}, host["customHandler"])
+	assert.FileExists(filepath.Join(dir, "handler", "function.json"))
+
+	err = f.createHostFiles(dir, "")
+	assert.EqualError(err, "cannot find a function handler. Please specify handler in your jerm.json file")
+}
@@ -0,0 +1,70 @@
+package azure
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/spatocode/jerm/internal/log"
+	"github.com/spatocode/jerm/internal/rest"
+)
+
+// LogStream is the Azure Functions log streaming operations
+type LogStream struct {
+	config *config.Config
+	client *rest.Client
+}
+
+// NewLogStream creates a new Azure Functions log stream monitor
+func NewLogStream(cfg *config.Config, client *rest.Client) *LogStream {
+	return &LogStream{
+		config: cfg,
+		client: client,
+	}
+}
+
+// scmUrl is the URL of the Function App Kudu service
+func (l *LogStream) scmUrl() string {
+	return hostUrl(l.config, fmt.Sprintf("%s.scm.azurewebsites.net", l.config.GetFunctionName()))
+}
+
+// Watch streams the Function App logs until the stream is closed
+func (l *LogStream) Watch() {
+	req, err := l.client.NewRequest(http.MethodGet, l.scmUrl()+"/api/logstream", nil)
+	if err != nil {
+		log.Debug(err.Error())
+		return
+	}
+
+	body, err := l.client.Stream(req)
+	if err != nil {
+		log.Debug(err.Error())
+		return
+	}
+	defer body.Close()
+
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.Contains(line, "Welcome, you are now connected to log-streaming service") {
+			continue
+		}
+		log.PrintInfo(line)
+	}
+	if err := scanner.Err(); err != nil {
+		log.Debug(err.Error())
+	}
+}
+
+// Clear deletes the log files in the named log directory
+func (l *LogStream) Clear(name string) error {
+	path := fmt.Sprintf("%s/api/vfs/LogFiles/%s/?recursive=true", l.scmUrl(), strings.Trim(name, "/"))
+	req, err := l.client.NewRequest(http.MethodDelete, path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("If-Match", "*")
+	return l.client.Send(req, nil)
+}
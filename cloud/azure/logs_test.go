@@ -0,0 +1,34 @@
+package azure
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/spatocode/jerm/internal/rest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLogStream(t *testing.T) {
+	assert := assert.New(t)
+	cfg := &config.Config{Name: "test", Stage: "dev"}
+	l := NewLogStream(cfg, rest.NewClient("", nil))
+	assert.Equal(cfg, l.config)
+	assert.Equal("https://test-dev.scm.azurewebsites.net", l.scmUrl())
+}
+
+func TestLogStreamWatch(t *testing.T) {
+	_, server := newFakeAzure(t)
+	cfg := &config.Config{Name: "test", Stage: "dev", Platform: config.Platform{Endpoint: server.URL}}
+	l := NewLogStream(cfg, rest.NewClient("", func(r *http.Request) error { return nil }))
+	l.Watch()
+}
+
+func TestLogStreamClear(t *testing.T) {
+	assert := assert.New(t)
+	_, server := newFakeAzure(t)
+	cfg := &config.Config{Name: "test", Stage: "dev", Platform: config.Platform{Endpoint: server.URL}}
+	l := NewLogStream(cfg, rest.NewClient("", nil))
+	err := l.Clear("Application")
+	assert.True(rest.IsNotFound(err))
+}
@@ -0,0 +1,100 @@
+package cloudflare
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/spatocode/jerm/internal/rest"
+)
+
+const (
+	cloudflareTokenDocsUrl   = "https://developers.cloudflare.com/fundamentals/api/get-started/create-token/"
+	apiEndpoint              = "https://api.cloudflare.com/client/v4"
+	apiTokenEnv              = "CLOUDFLARE_API_TOKEN"
+	accountIdEnv             = "CLOUDFLARE_ACCOUNT_ID"
+	DefaultCompatibilityDate = "2023-08-01"
+)
+
+// client is a Cloudflare API client which unwraps the API response envelope
+type client struct {
+	*rest.Client
+	account string
+}
+
+type envelope struct {
+	Success bool            `json:"success"`
+	Result  json.RawMessage `json:"result"`
+	Errors  []struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// newClient creates a Cloudflare API client for the configured account
+func newClient(cfg *config.Config) (*client, error) {
+	token := os.Getenv(apiTokenEnv)
+	if token == "" {
+		msg := fmt.Sprintf("Unable to find a Cloudflare API token. Ensure you set %s before using Jerm. See here for more info %s", apiTokenEnv, cloudflareTokenDocsUrl)
+		return nil, errors.New(msg)
+	}
+
+	account := cfg.Platform.Account
+	if account == "" {
+		account = os.Getenv(accountIdEnv)
+	}
+	if account == "" {
+		return nil, errors.New("cannot find a Cloudflare account. Please specify account in your jerm.json file")
+	}
+
+	endpoint := apiEndpoint
+	if cfg.Platform.Endpoint != "" {
+		endpoint = cfg.Platform.Endpoint
+	}
+	authorize := func(req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	}
+
+	return &client{Client: rest.NewClient(endpoint, authorize), account: account}, nil
+}
+
+// accountPath returns path relative to the account
+func (c *client) accountPath(format string, a ...interface{}) string {
+	return fmt.Sprintf("/accounts/%s%s", c.account, fmt.Sprintf(format, a...))
+}
+
+// Do sends a JSON request and decodes the response result into out
+func (c *client) Do(method, path string, in, out interface{}) error {
+	req, err := c.NewJSONRequest(method, path, in)
+	if err != nil {
+		return err
+	}
+	return c.send(req, out)
+}
+
+// send sends req and decodes the response result into out
+func (c *client) send(req *http.Request, out interface{}) error {
+	resp := &envelope{}
+	err := c.Send(req, resp)
+	if err != nil {
+		return err
+	}
+
+	if !resp.Success && len(resp.Errors) > 0 {
+		var messages []string
+		for _, e := range resp.Errors {
+			messages = append(messages, fmt.Sprintf("%d: %s", e.Code, e.Message))
+		}
+		return errors.New(strings.Join(messages, ", "))
+	}
+
+	if out == nil || len(resp.Result) == 0 {
+		return nil
+	}
+	return json.Unmarshal(resp.Result, out)
+}
@@ -0,0 +1,109 @@
+package cloudflare
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/spatocode/jerm/internal/log"
+)
+
+// Tail is the Cloudflare Workers tail operations
+type Tail struct {
+	config *config.Config
+	client *client
+	dialer *websocket.Dialer
+}
+
+type tailSession struct {
+	Id  string `json:"id"`
+	Url string `json:"url"`
+}
+
+type tailEvent struct {
+	Outcome        string `json:"outcome"`
+	EventTimestamp int64  `json:"eventTimestamp"`
+	Event          struct {
+		Request struct {
+			Url    string `json:"url"`
+			Method string `json:"method"`
+		} `json:"request"`
+	} `json:"event"`
+	Logs []struct {
+		Message   []interface{} `json:"message"`
+		Level     string        `json:"level"`
+		Timestamp int64         `json:"timestamp"`
+	} `json:"logs"`
+	Exceptions []struct {
+		Name    string `json:"name"`
+		Message string `json:"message"`
+	} `json:"exceptions"`
+}
+
+// NewTail creates a new Cloudflare Workers tail monitor
+func NewTail(cfg *config.Config, client *client) *Tail {
+	return &Tail{
+		config: cfg,
+		client: client,
+		dialer: &websocket.Dialer{
+			Proxy:            http.ProxyFromEnvironment,
+			HandshakeTimeout: 45 * time.Second,
+			Subprotocols:     []string{"trace-v1"},
+		},
+	}
+}
+
+// Watch creates a tail session and prints the Worker events until it's closed
+func (t *Tail) Watch() {
+	session := &tailSession{}
+	err := t.client.Do(http.MethodPost, t.client.accountPath("/workers/scripts/%s/tails", t.config.GetFunctionName()), nil, session)
+	if err != nil {
+		log.Debug(err.Error())
+		return
+	}
+	defer t.Clear(session.Id)
+
+	conn, _, err := t.dialer.Dial(session.Url, nil)
+	if err != nil {
+		log.Debug(err.Error())
+		return
+	}
+	defer conn.Close()
+
+	for {
+		event := &tailEvent{}
+		err := conn.ReadJSON(event)
+		if err != nil {
+			log.Debug(err.Error())
+			return
+		}
+		t.printLogs(event)
+	}
+}
+
+// printLogs prints a tail event to stdout
+func (t *Tail) printLogs(event *tailEvent) {
+	timestamp := time.UnixMilli(event.EventTimestamp)
+	if event.Event.Request.Url != "" {
+		log.PrintfInfo("[%s] %s %s (%s)\n", timestamp, event.Event.Request.Method, event.Event.Request.Url, event.Outcome)
+	}
+	for _, l := range event.Logs {
+		var messages []string
+		for _, m := range l.Message {
+			messages = append(messages, fmt.Sprint(m))
+		}
+		log.PrintfInfo("[%s] %s\n", time.UnixMilli(l.Timestamp), strings.Join(messages, " "))
+	}
+	for _, e := range event.Exceptions {
+		log.PrintfError("[%s] %s: %s\n", timestamp, e.Name, e.Message)
+	}
+}
+
+// Clear deletes a tail session
+func (t *Tail) Clear(id string) error {
+	return t.client.Do(http.MethodDelete, t.client.accountPath("/workers/scripts/%s/tails/%s", t.config.GetFunctionName(), id), nil, nil)
+}
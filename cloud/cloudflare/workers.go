@@ -0,0 +1,611 @@
+package cloudflare
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spatocode/jerm"
+	"github.com/spatocode/jerm/config"
+	"github.com/spatocode/jerm/config/handlers"
+	"github.com/spatocode/jerm/internal/log"
+	"github.com/spatocode/jerm/internal/rest"
+	"github.com/spatocode/jerm/internal/utils"
+)
+
+const (
+	// assetsDir is the directory of static assets in Node projects
+	assetsDir = "public"
+)
+
+var (
+	moduleExtensions = []string{".js", ".mjs", ".cjs"}
+	ignoredFiles     = []string{jerm.DefaultConfigFile, "package.json", "package-lock.json", ".jermignore"}
+
+	// importPattern matches the specifiers of the static and dynamic imports and requires of a module
+	importPattern = regexp.MustCompile(`(?:\bfrom\s*|\bimport\s*\(?\s*)["']([^"']+)["']|\brequire\s*\(\s*["']([^"']+)["']`)
+)
+
+func init() {
+	jerm.RegisterPlatform(config.Workers, func(cfg *config.Config) (jerm.CloudPlatform, error) {
+		w, err := NewWorkers(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return w, nil
+	})
+}
+
+// Workers is the Cloudflare Workers operations
+type Workers struct {
+	monitor    jerm.CloudMonitor
+	config     *config.Config
+	client     *client
+	command    utils.ShellCommand
+	mainModule string
+	assetsDir  string
+}
+
+type version struct {
+	Id     string `json:"id"`
+	Number int    `json:"number"`
+}
+
+type deployment struct {
+	Id       string `json:"id"`
+	Versions []struct {
+		VersionId  string `json:"version_id"`
+		Percentage int    `json:"percentage"`
+	} `json:"versions"`
+}
+
+// workerFile is a file of the worker package
+type workerFile struct {
+	name    string
+	content []byte
+}
+
+// NewWorkers instantiates a new Cloudflare Workers service
+func NewWorkers(cfg *config.Config) (*Workers, error) {
+	w := &Workers{
+		config:     cfg,
+		command:    utils.Command(),
+		mainModule: "index.js",
+		assetsDir:  assetsDir,
+	}
+
+	if w.config.Platform.Name == "" {
+		w.config.Platform.Name = config.Workers
+	}
+
+	c, err := newClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	w.client = c
+	w.monitor = NewTail(cfg, c)
+
+	return w, nil
+}
+
+func (w *Workers) WithMonitor(monitor jerm.CloudMonitor) {
+	w.monitor = monitor
+}
+
+// Build builds the deployment package for Workers.
+// Only static and Node projects can be deployed to Workers.
+func (w *Workers) Build() (string, error) {
+	log.Debug("building Jerm project for Workers...")
+
+	r := config.NewRuntime()
+
+	go func() {
+		err := w.config.ToJson(jerm.DefaultConfigFile)
+		if err != nil {
+			log.PrintWarn(err)
+		}
+	}()
+
+	static := false
+	switch runtime := r.(type) {
+	case *config.Node:
+	case *config.Runtime:
+		static = runtime.Name == config.RuntimeStatic
+		if !static {
+			return "", errors.New("cannot detect runtime. Workers supports Node and static projects only")
+		}
+	default:
+		return "", errors.New("Workers supports Node and static projects only")
+	}
+
+	packageDir, function, err := r.Build(w.config)
+	if err != nil {
+		return "", err
+	}
+
+	if static {
+		w.assetsDir = ""
+		if w.config.Platform.Handler == "" {
+			handler := filepath.Join(packageDir, w.mainModule)
+			err = os.WriteFile(handler, []byte(handlers.CloudflareWorkerHandlerStaticPage), 0644)
+			if err != nil {
+				return "", err
+			}
+			return packageDir, nil
+		}
+	}
+
+	w.mainModule = w.getMainModule(function)
+	err = w.bundle(packageDir)
+	if err != nil {
+		return "", err
+	}
+
+	return packageDir, nil
+}
+
+// bundle bundles the main module and its imports, including the npm packages
+// of node_modules, into the main module of the package with esbuild
+func (w *Workers) bundle(packageDir string) error {
+	log.Debug(fmt.Sprintf("bundling worker module %s...", w.mainModule))
+	_, err := w.command.RunCommand("npx", "--yes", "esbuild",
+		filepath.Join(w.config.Dir, w.mainModule),
+		"--bundle",
+		"--format=esm",
+		"--platform=neutral",
+		"--main-fields=module,main",
+		"--conditions=workerd,worker,browser",
+		"--external:cloudflare:*",
+		"--external:node:*",
+		"--allow-overwrite",
+		"--outfile="+filepath.Join(packageDir, w.mainModule),
+	)
+	if err != nil {
+		return fmt.Errorf("cannot bundle worker module %s. %s", w.mainModule, err)
+	}
+	return nil
+}
+
+// getMainModule converts a handler such as `src/index.handler` to a module `src/index.js`
+func (w *Workers) getMainModule(handler string) string {
+	if handler == "" {
+		return w.mainModule
+	}
+	for _, ext := range moduleExtensions {
+		if strings.HasSuffix(handler, ext) {
+			return handler
+		}
+	}
+	if i := strings.LastIndex(handler, "."); i > 0 {
+		handler = handler[:i]
+	}
+	return handler + ".js"
+}
+
+// Deploy deploys a Worker script and its static assets
+func (w *Workers) Deploy(zipPath string) (bool, error) {
+	deployed, err := w.isAlreadyDeployed()
+	if err != nil {
+		return false, err
+	}
+
+	if deployed {
+		return true, nil
+	}
+
+	err = w.upload(zipPath)
+	if err != nil {
+		return false, err
+	}
+
+	log.Debug("enabling workers.dev route...")
+	err = w.client.Do(http.MethodPost, w.client.accountPath("/workers/scripts/%s/subdomain", w.config.GetFunctionName()),
+		map[string]bool{"enabled": true}, nil)
+	if err != nil {
+		return false, err
+	}
+
+	err = w.printUrl()
+	if err != nil {
+		return false, err
+	}
+
+	return false, utils.RemoveLocalFile(zipPath)
+}
+
+// Update uploads a new version of the Worker script and its static assets
+func (w *Workers) Update(zipPath string) error {
+	err := w.upload(zipPath)
+	if err != nil {
+		return err
+	}
+
+	err = w.printUrl()
+	if err != nil {
+		return err
+	}
+
+	return utils.RemoveLocalFile(zipPath)
+}
+
+// Undeploy deletes a Worker script
+func (w *Workers) Undeploy() error {
+	deployed, err := w.isAlreadyDeployed()
+	if err != nil {
+		return err
+	}
+	if !deployed {
+		msg := "can't find a deployed project. Run 'jerm deploy' to deploy instead"
+		return errors.New(msg)
+	}
+
+	log.Debug("undeploying...")
+	return w.client.Do(http.MethodDelete, w.client.accountPath("/workers/scripts/%s?force=true", w.config.GetFunctionName()), nil, nil)
+}
+
+// Rollback deploys a previous version of the Worker
+func (w *Workers) Rollback(steps int) error {
+	deployed, err := w.isAlreadyDeployed()
+	if err != nil {
+		return err
+	}
+	if !deployed {
+		msg := "can't find a deployed project. Run 'jerm deploy' to deploy instead"
+		return errors.New(msg)
+	}
+
+	versions, err := w.listVersions()
+	if err != nil {
+		return err
+	}
+
+	deployments := struct {
+		Deployments []deployment `json:"deployments"`
+	}{}
+	err = w.client.Do(http.MethodGet, w.client.accountPath("/workers/scripts/%s/deployments", w.config.GetFunctionName()), nil, &deployments)
+	if err != nil {
+		return err
+	}
+
+	current := 0
+	if len(deployments.Deployments) > 0 && len(deployments.Deployments[0].Versions) > 0 {
+		for i, v := range versions {
+			if v.Id == deployments.Deployments[0].Versions[0].VersionId {
+				current = i
+				break
+			}
+		}
+	}
+
+	if len(versions) <= current+steps || steps < 1 {
+		msg := "invalid revision for rollback. Aborting"
+		return errors.New(msg)
+	}
+
+	target := versions[current+steps]
+	log.Debug(fmt.Sprintf("deploying worker version %d...", target.Number))
+	rollback := map[string]interface{}{
+		"strategy": "percentage",
+		"versions": []map[string]interface{}{
+			{"version_id": target.Id, "percentage": 100},
+		},
+	}
+	return w.client.Do(http.MethodPost, w.client.accountPath("/workers/scripts/%s/deployments", w.config.GetFunctionName()), rollback, nil)
+}
+
+// Logs tails the Worker logs
+func (w *Workers) Logs() {
+	w.monitor.Watch()
+}
+
+// Invoke sends a management command to the Worker
+func (w *Workers) Invoke(command string) error {
+	url, err := w.getUrl()
+	if err != nil {
+		return err
+	}
+
+	req, err := w.client.NewJSONRequest(http.MethodPost, url, map[string]string{"manage": command})
+	if err != nil {
+		return err
+	}
+	req.Header.Del("Authorization")
+
+	res, err := w.client.Response(req)
+	if err != nil {
+		return fmt.Errorf("%s - encountered an error while invoking function", err)
+	}
+	defer res.Body.Close()
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	log.PrintInfo(string(b))
+	return nil
+}
+
+func (w *Workers) isAlreadyDeployed() (bool, error) {
+	err := w.client.Do(http.MethodGet, w.client.accountPath("/workers/scripts/%s/settings", w.config.GetFunctionName()), nil, nil)
+	if err != nil {
+		if rest.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// listVersions lists the Worker versions sorted from the newest
+func (w *Workers) listVersions() ([]version, error) {
+	versions := struct {
+		Items []version `json:"items"`
+	}{}
+	err := w.client.Do(http.MethodGet, w.client.accountPath("/workers/scripts/%s/versions", w.config.GetFunctionName()), nil, &versions)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(versions.Items, func(i int, j int) bool {
+		return versions.Items[i].Number > versions.Items[j].Number
+	})
+	return versions.Items, nil
+}
+
+// upload uploads the Worker modules and static assets from the package archive
+func (w *Workers) upload(zipPath string) error {
+	modules, assets, err := w.readPackage(zipPath)
+	if err != nil {
+		return err
+	}
+
+	metadata := map[string]interface{}{
+		"main_module":        w.mainModule,
+		"compatibility_date": DefaultCompatibilityDate,
+	}
+	if len(assets) > 0 {
+		jwt, err := w.uploadAssets(assets)
+		if err != nil {
+			return err
+		}
+		metadata["assets"] = map[string]string{"jwt": jwt}
+		metadata["bindings"] = []map[string]string{{"type": "assets", "name": "ASSETS"}}
+	}
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	m, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	err = writer.WriteField("metadata", string(m))
+	if err != nil {
+		return err
+	}
+	for _, module := range modules {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, module.name, module.name))
+		header.Set("Content-Type", "application/javascript+module")
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return err
+		}
+		part.Write(module.content)
+	}
+	err = writer.Close()
+	if err != nil {
+		return err
+	}
+
+	log.Debug(fmt.Sprintf("uploading worker script with %d modules...", len(modules)))
+	req, err := w.client.NewRequest(http.MethodPut, w.client.accountPath("/workers/scripts/%s", w.config.GetFunctionName()), body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return w.client.send(req, nil)
+}
+
+// uploadAssets uploads the static assets which aren't already uploaded
+// and returns the completion token of the assets upload
+func (w *Workers) uploadAssets(assets []workerFile) (string, error) {
+	manifest := make(map[string]map[string]interface{})
+	contents := make(map[string]workerFile)
+	for _, asset := range assets {
+		encoded := base64.StdEncoding.EncodeToString(asset.content)
+		sum := sha256.Sum256([]byte(encoded + path.Ext(asset.name)))
+		hash := hex.EncodeToString(sum[:])[:32]
+		manifest["/"+asset.name] = map[string]interface{}{"hash": hash, "size": len(asset.content)}
+		contents[hash] = asset
+	}
+
+	log.Debug(fmt.Sprintf("creating assets upload session for %d assets...", len(assets)))
+	session := struct {
+		Jwt     string     `json:"jwt"`
+		Buckets [][]string `json:"buckets"`
+	}{}
+	err := w.client.Do(http.MethodPost, w.client.accountPath("/workers/scripts/%s/assets-upload-session", w.config.GetFunctionName()),
+		map[string]interface{}{"manifest": manifest}, &session)
+	if err != nil {
+		return "", err
+	}
+
+	jwt := session.Jwt
+	for _, bucket := range session.Buckets {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		for _, hash := range bucket {
+			asset := contents[hash]
+			header := make(textproto.MIMEHeader)
+			header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, hash, hash))
+			header.Set("Content-Type", "application/null")
+			part, err := writer.CreatePart(header)
+			if err != nil {
+				return "", err
+			}
+			part.Write([]byte(base64.StdEncoding.EncodeToString(asset.content)))
+		}
+		err = writer.Close()
+		if err != nil {
+			return "", err
+		}
+
+		req, err := w.client.NewRequest(http.MethodPost, w.client.accountPath("/workers/assets/upload?base64=true"), body)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer "+session.Jwt)
+
+		result := struct {
+			Jwt string `json:"jwt"`
+		}{}
+		err = w.client.send(req, &result)
+		if err != nil {
+			return "", err
+		}
+		if result.Jwt != "" {
+			jwt = result.Jwt
+		}
+	}
+
+	return jwt, nil
+}
+
+// readPackage reads the Worker modules and static assets from the package archive
+func (w *Workers) readPackage(zipPath string) ([]workerFile, []workerFile, error) {
+	var files []workerFile
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer reader.Close()
+
+	for _, file := range reader.File {
+		name := strings.TrimPrefix(filepath.ToSlash(file.Name), "/")
+		if file.FileInfo().IsDir() || strings.HasPrefix(name, "node_modules/") || w.isIgnored(name) {
+			continue
+		}
+
+		f, err := file.Open()
+		if err != nil {
+			return nil, nil, err
+		}
+		content, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+		files = append(files, workerFile{name, content})
+	}
+
+	// only the main module and its imports are uploaded as modules, which is
+	// the bundle of Node projects. Static projects serve every other file as an asset.
+	imported := w.importedModules(files)
+
+	var modules, assets []workerFile
+	for _, file := range files {
+		switch {
+		case imported[file.name]:
+			modules = append(modules, file)
+		case w.assetsDir == "":
+			assets = append(assets, file)
+		case strings.HasPrefix(file.name, w.assetsDir+"/"):
+			assets = append(assets, workerFile{strings.TrimPrefix(file.name, w.assetsDir+"/"), file.content})
+		}
+	}
+
+	if len(modules) == 0 {
+		return nil, nil, fmt.Errorf("cannot find worker module %s in package", w.mainModule)
+	}
+	return modules, assets, nil
+}
+
+// importedModules returns the main module and the modules it imports, directly
+// or through other modules, with relative specifiers
+func (w *Workers) importedModules(files []workerFile) map[string]bool {
+	contents := map[string][]byte{}
+	for _, file := range files {
+		contents[file.name] = file.content
+	}
+
+	modules := map[string]bool{}
+	pending := []string{w.mainModule}
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+		content, ok := contents[name]
+		if !ok || modules[name] || !w.isModule(name) {
+			continue
+		}
+		modules[name] = true
+
+		for _, match := range importPattern.FindAllSubmatch(content, -1) {
+			specifier := string(bytes.Join(match[1:], nil))
+			if strings.HasPrefix(specifier, "./") || strings.HasPrefix(specifier, "../") {
+				pending = append(pending, path.Join(path.Dir(name), specifier))
+			}
+		}
+	}
+	return modules
+}
+
+func (w *Workers) isModule(name string) bool {
+	if w.assetsDir != "" && strings.HasPrefix(name, w.assetsDir+"/") {
+		return false
+	}
+	for _, ext := range moduleExtensions {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Workers) isIgnored(name string) bool {
+	for _, ignored := range ignoredFiles {
+		if name == ignored {
+			return true
+		}
+	}
+	return false
+}
+
+// getUrl returns the workers.dev URL of the Worker
+func (w *Workers) getUrl() (string, error) {
+	subdomain := struct {
+		Subdomain string `json:"subdomain"`
+	}{}
+	err := w.client.Do(http.MethodGet, w.client.accountPath("/workers/subdomain"), nil, &subdomain)
+	if err != nil {
+		return "", err
+	}
+	url := fmt.Sprintf("https://%s.%s.workers.dev", w.config.GetFunctionName(), subdomain.Subdomain)
+	if w.config.Platform.Endpoint != "" {
+		url = fmt.Sprintf("%s/%s.%s.workers.dev", strings.TrimSuffix(w.config.Platform.Endpoint, "/"), w.config.GetFunctionName(), subdomain.Subdomain)
+	}
+	return url, nil
+}
+
+func (w *Workers) printUrl() error {
+	url, err := w.getUrl()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s %s\n", log.Magenta("url:"), log.Green(url))
+	return nil
+}
@@ -0,0 +1,302 @@
+package cloudflare
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/spatocode/jerm/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeCloudflare is a local stand-in for the Cloudflare Workers API
+type fakeCloudflare struct {
+	mu         sync.Mutex
+	script     bool
+	modules    []string
+	metadata   map[string]interface{}
+	assets     int
+	versions   []version
+	deployed   string
+	tailClosed bool
+	invoked    string
+}
+
+func newFakeCloudflare(t *testing.T) (*fakeCloudflare, *httptest.Server) {
+	fake := &fakeCloudflare{}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func (c *fakeCloudflare) result(w http.ResponseWriter, result interface{}) {
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "result": result})
+}
+
+func (c *fakeCloudflare) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/tail" {
+		upgrader := websocket.Upgrader{Subprotocols: []string{"trace-v1"}}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"outcome": "ok", "logs": [{"message": ["hello"]}]}`))
+		conn.Close()
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	base := "/accounts/acc/workers/scripts/test-dev"
+	path := r.URL.Path
+	switch {
+	case strings.HasSuffix(path, ".workers.dev"):
+		b, _ := io.ReadAll(r.Body)
+		c.invoked = string(b)
+		w.Write([]byte("ok"))
+	case path == "/accounts/acc/workers/subdomain":
+		c.result(w, map[string]string{"subdomain": "jerm"})
+	case path == base+"/settings":
+		if !c.script {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"success": false, "errors": [{"code": 10007, "message": "not found"}]}`))
+			return
+		}
+		c.result(w, map[string]string{})
+	case path == base+"/subdomain":
+		c.result(w, map[string]bool{"enabled": true})
+	case path == base+"/assets-upload-session":
+		body := map[string]map[string]map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&body)
+		var bucket []string
+		for _, asset := range body["manifest"] {
+			bucket = append(bucket, asset["hash"].(string))
+		}
+		c.result(w, map[string]interface{}{"jwt": "session", "buckets": [][]string{bucket}})
+	case path == "/accounts/acc/workers/assets/upload":
+		r.ParseMultipartForm(1 << 20)
+		c.assets = len(r.MultipartForm.File) + len(r.MultipartForm.Value)
+		c.result(w, map[string]string{"jwt": "completion"})
+	case path == base+"/versions":
+		c.result(w, map[string]interface{}{"items": c.versions})
+	case path == base+"/deployments" && r.Method == http.MethodGet:
+		c.result(w, map[string]interface{}{"deployments": []interface{}{
+			map[string]interface{}{"versions": []interface{}{map[string]interface{}{"version_id": c.deployed, "percentage": 100}}},
+		}})
+	case path == base+"/deployments" && r.Method == http.MethodPost:
+		body := struct {
+			Versions []struct {
+				VersionId string `json:"version_id"`
+			} `json:"versions"`
+		}{}
+		json.NewDecoder(r.Body).Decode(&body)
+		c.deployed = body.Versions[0].VersionId
+		c.result(w, nil)
+	case path == base+"/tails" && r.Method == http.MethodPost:
+		c.result(w, map[string]string{"id": "tail", "url": "ws://" + r.Host + "/tail"})
+	case path == base+"/tails/tail":
+		c.tailClosed = true
+		c.result(w, nil)
+	case path == base && r.Method == http.MethodPut:
+		r.ParseMultipartForm(1 << 20)
+		json.Unmarshal([]byte(r.FormValue("metadata")), &c.metadata)
+		c.modules = nil
+		for name := range r.MultipartForm.File {
+			c.modules = append(c.modules, name)
+		}
+		c.script = true
+		id := fmt.Sprintf("v%d", len(c.versions)+1)
+		c.versions = append(c.versions, version{Id: id, Number: len(c.versions) + 1})
+		c.deployed = id
+		c.result(w, map[string]string{"id": "test-dev"})
+	case path == base && r.Method == http.MethodDelete:
+		c.script = false
+		c.result(w, nil)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func helperWorkers(t *testing.T, endpoint string) *Workers {
+	t.Setenv(apiTokenEnv, "token")
+	cfg := &config.Config{
+		Name:     "test",
+		Stage:    "dev",
+		Platform: config.Platform{Name: config.Workers, Account: "acc", Endpoint: endpoint},
+	}
+	w, err := NewWorkers(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func helperArchive(t *testing.T, files map[string]string) string {
+	archive := filepath.Join(t.TempDir(), "jerm.zip")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	writer := zip.NewWriter(f)
+	for name, content := range files {
+		w, _ := writer.Create(name)
+		w.Write([]byte(content))
+	}
+	writer.Close()
+	return archive
+}
+
+func TestNewWorkersWithoutToken(t *testing.T) {
+	assert := assert.New(t)
+	t.Setenv(apiTokenEnv, "")
+	_, err := NewWorkers(&config.Config{})
+	assert.ErrorContains(err, "Unable to find a Cloudflare API token")
+}
+
+func TestWorkersGetMainModule(t *testing.T) {
+	assert := assert.New(t)
+	w := &Workers{mainModule: "index.js"}
+	assert.Equal("index.js", w.getMainModule(""))
+	assert.Equal("src/worker.js", w.getMainModule("src/worker.handler"))
+	assert.Equal("worker.mjs", w.getMainModule("worker.mjs"))
+}
+
+func TestWorkersReadPackage(t *testing.T) {
+	assert := assert.New(t)
+	w := helperWorkers(t, "http://localhost")
+	archive := helperArchive(t, map[string]string{
+		"/index.js":            "export default {}",
+		"/src/app.js":          `import x from "x"`,
+		"/jerm.json":           "{}",
+		"/node_modules/x/x.js": "",
+		"/public/index.html":   "<html></html>",
+		"/README.md":           "readme",
+	})
+	modules, assets, err := w.readPackage(archive)
+	assert.Nil(err)
+	assert.Len(modules, 1)
+	assert.Equal("index.js", modules[0].name)
+	assert.Len(assets, 1)
+	assert.Equal("index.html", assets[0].name)
+
+	w.assetsDir = ""
+	_, assets, err = w.readPackage(archive)
+	assert.Nil(err)
+	assert.Len(assets, 3)
+
+	_, _, err = w.readPackage(helperArchive(t, map[string]string{"/index.html": ""}))
+	assert.EqualError(err, "cannot find worker module index.js in package")
+}
+
+// fakeEsbuild records the esbuild commands
+type fakeEsbuild struct {
+	commands []string
+	err      error
+}
+
+func (e *fakeEsbuild) RunCommand(command string, args ...string) (string, error) {
+	e.commands = append(e.commands, command+" "+strings.Join(args, " "))
+	return "", e.err
+}
+
+func (e *fakeEsbuild) RunCommandWithEnv(env []string, command string, args ...string) (string, error) {
+	return e.RunCommand(command, args...)
+}
+
+func TestWorkersBundle(t *testing.T) {
+	assert := assert.New(t)
+	w := helperWorkers(t, "http://localhost")
+	esbuild := &fakeEsbuild{}
+	w.command = esbuild
+	w.config.Dir = "/project"
+	w.mainModule = "src/worker.js"
+
+	err := w.bundle("/package")
+	assert.Nil(err)
+	assert.Len(esbuild.commands, 1)
+	assert.True(strings.HasPrefix(esbuild.commands[0], "npx --yes esbuild /project/src/worker.js --bundle --format=esm"))
+	assert.True(strings.HasSuffix(esbuild.commands[0], "--outfile=/package/src/worker.js"))
+
+	esbuild.err = fmt.Errorf("Could not resolve \"x\"")
+	err = w.bundle("/package")
+	assert.EqualError(err, `cannot bundle worker module src/worker.js. Could not resolve "x"`)
+}
+
+func TestWorkersReadStaticPackage(t *testing.T) {
+	assert := assert.New(t)
+	w := helperWorkers(t, "http://localhost")
+	w.assetsDir = ""
+	archive := helperArchive(t, map[string]string{
+		"/index.js":       `import { route } from "./lib/router.mjs"; export default {}`,
+		"/lib/router.mjs": `export const cache = require("../cache.cjs")`,
+		"/cache.cjs":      "module.exports = {}",
+		"/index.html":     "<html></html>",
+		"/js/app.js":      "console.log('app')",
+		"/js/vendor.mjs":  "export {}",
+	})
+	modules, assets, err := w.readPackage(archive)
+	assert.Nil(err)
+	var names []string
+	for _, module := range modules {
+		names = append(names, module.name)
+	}
+	assert.ElementsMatch([]string{"index.js", "lib/router.mjs", "cache.cjs"}, names)
+	names = nil
+	for _, asset := range assets {
+		names = append(names, asset.name)
+	}
+	assert.ElementsMatch([]string{"index.html", "js/app.js", "js/vendor.mjs"}, names)
+}
+
+func TestWorkersDeployLifecycle(t *testing.T) {
+	assert := assert.New(t)
+	fake, server := newFakeCloudflare(t)
+	w := helperWorkers(t, server.URL)
+	files := map[string]string{"/index.js": "export default {}", "/public/index.html": "<html></html>"}
+
+	deployed, err := w.Deploy(helperArchive(t, files))
+	assert.Nil(err)
+	assert.False(deployed)
+	assert.Equal([]string{"index.js"}, fake.modules)
+	assert.Equal(1, fake.assets)
+	assert.Equal("index.js", fake.metadata["main_module"])
+	assert.Equal(map[string]interface{}{"jwt": "completion"}, fake.metadata["assets"])
+
+	deployed, err = w.Deploy(helperArchive(t, files))
+	assert.Nil(err)
+	assert.True(deployed)
+
+	err = w.Update(helperArchive(t, files))
+	assert.Nil(err)
+	assert.Equal("v2", fake.deployed)
+
+	err = w.Rollback(2)
+	assert.EqualError(err, "invalid revision for rollback. Aborting")
+	err = w.Rollback(1)
+	assert.Nil(err)
+	assert.Equal("v1", fake.deployed)
+
+	err = w.Invoke("migrate")
+	assert.Nil(err)
+	assert.Equal(`{"manage":"migrate"}`, fake.invoked)
+
+	w.Logs()
+	assert.True(fake.tailClosed)
+
+	err = w.Undeploy()
+	assert.Nil(err)
+	assert.False(fake.script)
+	err = w.Undeploy()
+	assert.EqualError(err, "can't find a deployed project. Run 'jerm deploy' to deploy instead")
+}
@@ -0,0 +1,499 @@
+package gcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spatocode/jerm"
+	"github.com/spatocode/jerm/config"
+	"github.com/spatocode/jerm/config/handlers"
+	"github.com/spatocode/jerm/internal/log"
+	"github.com/spatocode/jerm/internal/rest"
+	"github.com/spatocode/jerm/internal/utils"
+)
+
+func init() {
+	jerm.RegisterPlatform(config.CloudFunctions, func(cfg *config.Config) (jerm.CloudPlatform, error) {
+		f, err := NewCloudFunctions(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return f, nil
+	})
+}
+
+// CloudFunctions is the Google Cloud Functions (2nd gen) operations
+type CloudFunctions struct {
+	credentials       *credentials
+	storage           *Storage
+	monitor           jerm.CloudMonitor
+	config            *config.Config
+	project           string
+	location          string
+	entryPoint        string
+	maxWaiterDuration time.Duration
+	pollInterval      time.Duration
+	client            *rest.Client
+}
+
+type function struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	State       string `json:"state,omitempty"`
+	BuildConfig struct {
+		Runtime    string `json:"runtime,omitempty"`
+		EntryPoint string `json:"entryPoint,omitempty"`
+		Source     struct {
+			StorageSource struct {
+				Bucket string `json:"bucket,omitempty"`
+				Object string `json:"object,omitempty"`
+			} `json:"storageSource"`
+		} `json:"source"`
+	} `json:"buildConfig"`
+	ServiceConfig struct {
+		AvailableMemory string `json:"availableMemory,omitempty"`
+		TimeoutSeconds  int    `json:"timeoutSeconds,omitempty"`
+		Uri             string `json:"uri,omitempty"`
+	} `json:"serviceConfig"`
+}
+
+type operation struct {
+	Name  string `json:"name"`
+	Done  bool   `json:"done"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// NewCloudFunctions instantiates a new Google Cloud Functions service
+func NewCloudFunctions(cfg *config.Config) (*CloudFunctions, error) {
+	f := &CloudFunctions{
+		credentials:       newCredentials(utils.Command()),
+		config:            cfg,
+		location:          cfg.Region,
+		maxWaiterDuration: DefaultWaitDuration,
+		pollInterval:      time.Second * 3,
+	}
+
+	if f.config.Platform.Name == "" {
+		f.config.Platform.Name = config.CloudFunctions
+	}
+	err := f.config.Platform.Defaults()
+	if err != nil {
+		return nil, err
+	}
+
+	if f.location == "" {
+		f.location = DefaultLocation
+	}
+
+	f.project, err = f.credentials.project(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	f.client = newClient(cfg, functionsEndpoint, f.credentials.authorize)
+	f.storage = NewStorage(cfg, f.project, f.credentials.authorize)
+	f.monitor = NewCloudLogging(cfg, f.project, f.credentials.authorize)
+
+	return f, nil
+}
+
+func (f *CloudFunctions) WithMonitor(monitor jerm.CloudMonitor) {
+	f.monitor = monitor
+}
+
+// Build builds the deployment package for Cloud Functions
+func (f *CloudFunctions) Build() (string, error) {
+	log.Debug("building Jerm project for Cloud Functions...")
+
+	r := config.NewRuntime()
+
+	go func() {
+		err := f.config.ToJson(jerm.DefaultConfigFile)
+		if err != nil {
+			log.PrintWarn(err)
+		}
+	}()
+
+	var (
+		packageDir, function string
+		err                  error
+	)
+	switch runtime := r.(type) {
+	case *config.Go:
+		// Cloud Functions builds Go functions from source
+		packageDir, function, err = runtime.Runtime.Build(f.config)
+	case *config.Python:
+		runtime.WithHandlerTemplate(handlers.GoogleCloudFunctionsHandlerDjango)
+		packageDir, function, err = r.Build(f.config)
+	case *config.Runtime:
+		runtime.WithHandlerTemplate(handlers.GoogleCloudFunctionsHandlerStaticPage)
+		packageDir, function, err = r.Build(f.config)
+	default:
+		packageDir, function, err = r.Build(f.config)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if _, ok := r.(*config.Python); ok {
+		err = f.createPythonMain(packageDir, function)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	f.entryPoint = f.getEntryPoint(function)
+
+	return packageDir, nil
+}
+
+// createPythonMain makes the handler importable from main.py, which is
+// the source file Cloud Functions loads for Python functions
+func (f *CloudFunctions) createPythonMain(packageDir, function string) error {
+	module := strings.TrimSuffix(function, "."+f.getEntryPoint(function))
+	if module == "main" || module == function {
+		return nil
+	}
+
+	mainFile := filepath.Join(packageDir, "main.py")
+	if utils.FileExists(mainFile) {
+		return fmt.Errorf("cannot use handler %s. Cloud Functions loads Python functions from main.py", function)
+	}
+
+	if f.config.Platform.Handler == "" {
+		// the generated handler is written to handler.py
+		return os.Rename(filepath.Join(packageDir, module+".py"), mainFile)
+	}
+
+	entryPoint := f.getEntryPoint(function)
+	content := fmt.Sprintf("from %s import %s\n", module, entryPoint)
+	return os.WriteFile(mainFile, []byte(content), 0644)
+}
+
+// getEntryPoint converts a handler to a Cloud Functions entry point.
+// A handler such as `handler.handler` has an entry point `handler`.
+func (f *CloudFunctions) getEntryPoint(handler string) string {
+	if handler == "" {
+		return config.DefaultServerlessFunction
+	}
+	s := strings.Split(handler, ".")
+	return s[len(s)-1]
+}
+
+// Deploy deploys a Cloud Function
+func (f *CloudFunctions) Deploy(zipPath string) (bool, error) {
+	deployed, err := f.isAlreadyDeployed()
+	if err != nil {
+		return false, err
+	}
+
+	if deployed {
+		return true, nil
+	}
+
+	err = f.ensureBucket()
+	if err != nil {
+		return false, err
+	}
+
+	object := f.archiveName()
+	err = f.storage.uploadObject(object, zipPath)
+	if err != nil {
+		return false, err
+	}
+
+	log.Debug("creating cloud function...")
+	fn := f.newFunction(object)
+	op := &operation{}
+	path := fmt.Sprintf("/v2/projects/%s/locations/%s/functions?functionId=%s", f.project, f.location, f.config.GetFunctionName())
+	err = f.client.Do(http.MethodPost, path, fn, op)
+	if err != nil {
+		return false, err
+	}
+
+	err = f.waitForOperation(op)
+	if err != nil {
+		return false, err
+	}
+
+	err = f.printUrl()
+	if err != nil {
+		return false, err
+	}
+
+	err = utils.RemoveLocalFile(zipPath)
+	if err != nil {
+		return false, err
+	}
+
+	return false, nil
+}
+
+// Update updates a deployed Cloud Function with a new package
+func (f *CloudFunctions) Update(zipPath string) error {
+	err := f.ensureBucket()
+	if err != nil {
+		return err
+	}
+
+	object := f.archiveName()
+	err = f.storage.uploadObject(object, zipPath)
+	if err != nil {
+		return err
+	}
+
+	err = f.updateFunction(object)
+	if err != nil {
+		return err
+	}
+
+	err = f.printUrl()
+	if err != nil {
+		return err
+	}
+
+	err = utils.RemoveLocalFile(zipPath)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Undeploy deletes a Cloud Functions deployment and its archives
+func (f *CloudFunctions) Undeploy() error {
+	deployed, err := f.isAlreadyDeployed()
+	if err != nil {
+		return err
+	}
+	if !deployed {
+		msg := "can't find a deployed project. Run 'jerm deploy' to deploy instead"
+		return errors.New(msg)
+	}
+
+	log.Debug("undeploying...")
+	op := &operation{}
+	err = f.client.Do(http.MethodDelete, f.functionPath(), nil, op)
+	if err != nil {
+		return err
+	}
+
+	err = f.waitForOperation(op)
+	if err != nil {
+		return err
+	}
+
+	archives, err := f.storage.listObjects(f.archivePrefix())
+	if err != nil {
+		return err
+	}
+	for _, archive := range archives {
+		err = f.storage.Delete(archive.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Rollback rolls back a Cloud Function to a previously deployed archive
+func (f *CloudFunctions) Rollback(steps int) error {
+	fn, err := f.getFunction()
+	if err != nil {
+		if rest.IsNotFound(err) {
+			msg := "can't find a deployed project. Run 'jerm deploy' to deploy instead"
+			return errors.New(msg)
+		}
+		return err
+	}
+
+	archives, err := f.storage.listObjects(f.archivePrefix())
+	if err != nil {
+		return err
+	}
+
+	current := 0
+	for i, archive := range archives {
+		if archive.Name == fn.BuildConfig.Source.StorageSource.Object {
+			current = i
+			break
+		}
+	}
+
+	if len(archives) <= current+steps || steps < 1 {
+		msg := "invalid revision for rollback. Aborting"
+		return errors.New(msg)
+	}
+
+	f.entryPoint = fn.BuildConfig.EntryPoint
+	return f.updateFunction(archives[current+steps].Name)
+}
+
+// Logs shows Cloud Functions logs
+func (f *CloudFunctions) Logs() {
+	f.monitor.Watch()
+}
+
+// Invoke invokes the function with a management command
+func (f *CloudFunctions) Invoke(command string) error {
+	fn, err := f.getFunction()
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(map[string]string{"manage": command})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fn.ServiceConfig.Uri, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	err = f.credentials.authorizeInvoke(req)
+	if err != nil {
+		return err
+	}
+
+	res, err := f.client.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	log.PrintInfo(string(b))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("%s - encountered an error while invoking function", res.Status)
+	}
+	return nil
+}
+
+// ensureBucket creates the storage bucket if it doesn't exist
+func (f *CloudFunctions) ensureBucket() error {
+	err := f.storage.Accessible()
+	if err == nil {
+		return nil
+	}
+	if !rest.IsNotFound(err) {
+		return err
+	}
+
+	err = f.storage.CreateBucket(true)
+	if err != nil {
+		log.Debug(fmt.Sprintf("error on creating storage bucket with config %t", true))
+		return f.storage.CreateBucket(false)
+	}
+	return nil
+}
+
+// updateFunction updates the function to the source archive object
+func (f *CloudFunctions) updateFunction(object string) error {
+	log.Debug("updating cloud function...")
+	fn := f.newFunction(object)
+	mask := "buildConfig.source,buildConfig.runtime,buildConfig.entryPoint,serviceConfig.availableMemory,serviceConfig.timeoutSeconds"
+	op := &operation{}
+	err := f.client.Do(http.MethodPatch, fmt.Sprintf("%s?updateMask=%s", f.functionPath(), mask), fn, op)
+	if err != nil {
+		return err
+	}
+	return f.waitForOperation(op)
+}
+
+// newFunction creates the function definition for the source archive object
+func (f *CloudFunctions) newFunction(object string) *function {
+	fn := &function{Description: "Jerm Deployment"}
+	fn.BuildConfig.Runtime = f.config.Platform.Runtime
+	fn.BuildConfig.EntryPoint = f.entryPoint
+	if fn.BuildConfig.EntryPoint == "" {
+		fn.BuildConfig.EntryPoint = f.getEntryPoint(f.config.Platform.Handler)
+	}
+	fn.BuildConfig.Source.StorageSource.Bucket = f.config.Bucket
+	fn.BuildConfig.Source.StorageSource.Object = object
+	fn.ServiceConfig.AvailableMemory = fmt.Sprintf("%dM", f.config.Platform.Memory)
+	fn.ServiceConfig.TimeoutSeconds = f.config.Platform.Timeout
+	return fn
+}
+
+// waitForOperation polls a long running operation until it's done
+func (f *CloudFunctions) waitForOperation(op *operation) error {
+	deadline := time.Now().Add(time.Second * f.maxWaiterDuration)
+	for !op.Done {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for operation %s", op.Name)
+		}
+		time.Sleep(f.pollInterval)
+		log.Debug(fmt.Sprintf("waiting for operation %s...", op.Name))
+		err := f.client.Do(http.MethodGet, fmt.Sprintf("/v2/%s", op.Name), nil, op)
+		if err != nil {
+			return err
+		}
+	}
+
+	if op.Error != nil {
+		return errors.New(op.Error.Message)
+	}
+	return nil
+}
+
+func (f *CloudFunctions) isAlreadyDeployed() (bool, error) {
+	_, err := f.getFunction()
+	if err != nil {
+		if rest.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (f *CloudFunctions) getFunction() (*function, error) {
+	log.Debug(fmt.Sprintf("getting cloud function %s...", f.config.GetFunctionName()))
+	fn := &function{}
+	err := f.client.Do(http.MethodGet, f.functionPath(), nil, fn)
+	if err != nil {
+		return nil, err
+	}
+	return fn, nil
+}
+
+func (f *CloudFunctions) printUrl() error {
+	fn, err := f.getFunction()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s %s\n", log.Magenta("url:"), log.Green(fn.ServiceConfig.Uri))
+	return nil
+}
+
+func (f *CloudFunctions) functionPath() string {
+	return fmt.Sprintf("/v2/projects/%s/locations/%s/functions/%s", f.project, f.location, f.config.GetFunctionName())
+}
+
+// archivePrefix is the storage prefix of the function archives
+func (f *CloudFunctions) archivePrefix() string {
+	return fmt.Sprintf("%s/", f.config.GetFunctionName())
+}
+
+// archiveName is a unique storage name for a function archive.
+// Archives are kept in storage to support rollbacks.
+func (f *CloudFunctions) archiveName() string {
+	return fmt.Sprintf("%s%d.zip", f.archivePrefix(), time.Now().UnixNano())
+}
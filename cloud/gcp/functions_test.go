@@ -0,0 +1,240 @@
+package gcp
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/spatocode/jerm/config/handlers"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeGoogleCloud is a local stand-in for the Cloud Functions,
+// Cloud Storage and Cloud Logging REST APIs
+type fakeGoogleCloud struct {
+	mu       sync.Mutex
+	bucket   bool
+	objects  map[string]bool
+	function *function
+	invoked  string
+}
+
+func newFakeGoogleCloud(t *testing.T) (*fakeGoogleCloud, *httptest.Server) {
+	fake := &fakeGoogleCloud{objects: make(map[string]bool)}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func (g *fakeGoogleCloud) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	path := r.URL.Path
+	done := `{"name": "operations/op1", "done": true}`
+	switch {
+	case path == "/invoke":
+		b, _ := io.ReadAll(r.Body)
+		g.invoked = string(b)
+		w.Write([]byte("invoked"))
+	case path == "/v2/entries:list":
+		w.Write([]byte(`{"entries": [{"timestamp": "2023-01-01T00:00:00Z", "textPayload": "hello"}]}`))
+	case strings.HasPrefix(path, "/v2/operations/"):
+		w.Write([]byte(done))
+	case strings.HasPrefix(path, "/v2/projects/test/locations/us-central1/functions"):
+		switch r.Method {
+		case http.MethodGet:
+			if g.function == nil {
+				http.NotFound(w, r)
+				return
+			}
+			json.NewEncoder(w).Encode(g.function)
+		case http.MethodPost, http.MethodPatch:
+			fn := &function{}
+			json.NewDecoder(r.Body).Decode(fn)
+			fn.ServiceConfig.Uri = "http://" + r.Host + "/invoke"
+			g.function = fn
+			w.Write([]byte(`{"name": "operations/op1", "done": false}`))
+		case http.MethodDelete:
+			g.function = nil
+			w.Write([]byte(done))
+		}
+	case path == "/storage/v1/b" && r.Method == http.MethodPost:
+		g.bucket = true
+		w.Write([]byte(`{}`))
+	case path == "/storage/v1/b/testbucket":
+		if !g.bucket {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{}`))
+	case path == "/upload/storage/v1/b/testbucket/o":
+		g.objects[r.URL.Query().Get("name")] = true
+		w.Write([]byte(`{}`))
+	case path == "/storage/v1/b/testbucket/o":
+		var items []object
+		for name := range g.objects {
+			if strings.HasPrefix(name, r.URL.Query().Get("prefix")) {
+				items = append(items, object{Name: name})
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+	case strings.HasPrefix(path, "/storage/v1/b/testbucket/o/"):
+		delete(g.objects, strings.TrimPrefix(path, "/storage/v1/b/testbucket/o/"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func helperCloudFunctions(t *testing.T, endpoint string) *CloudFunctions {
+	t.Setenv(accessTokenEnv, "token")
+	t.Setenv(identityTokenEnv, "token")
+	cfg := &config.Config{
+		Name:   "test",
+		Stage:  "dev",
+		Bucket: "testbucket",
+		Platform: config.Platform{
+			Name:     config.CloudFunctions,
+			Runtime:  "python311",
+			Handler:  "main.handler",
+			Project:  "test",
+			Endpoint: endpoint,
+		},
+	}
+	f, err := NewCloudFunctions(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.pollInterval = time.Millisecond
+	return f
+}
+
+func helperArchive(t *testing.T) string {
+	archive := filepath.Join(t.TempDir(), "jerm.zip")
+	err := os.WriteFile(archive, []byte("archive"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return archive
+}
+
+func TestNewCloudFunctions(t *testing.T) {
+	assert := assert.New(t)
+	f := helperCloudFunctions(t, "http://localhost")
+	assert.Equal("test", f.project)
+	assert.Equal(DefaultLocation, f.location)
+	assert.Equal(config.DefaultMemory, f.config.Platform.Memory)
+	assert.Equal(config.DefaultTimeout, f.config.Platform.Timeout)
+	assert.NotNil(f.storage)
+	assert.NotNil(f.monitor)
+}
+
+func TestCloudFunctionsGetEntryPoint(t *testing.T) {
+	assert := assert.New(t)
+	f := &CloudFunctions{}
+	assert.Equal("handler", f.getEntryPoint(""))
+	assert.Equal("app", f.getEntryPoint("main.app"))
+	assert.Equal("Handle", f.getEntryPoint("Handle"))
+}
+
+func TestCloudFunctionsStaticHandler(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "index.html"), []byte("<html></html>"), 0644)
+	assert.Nil(err)
+
+	r := &config.Runtime{Name: config.RuntimeStatic}
+	r.WithHandlerTemplate(handlers.GoogleCloudFunctionsHandlerStaticPage)
+	packageDir, function, err := r.Build(&config.Config{Dir: dir})
+	assert.Nil(err)
+	defer os.RemoveAll(packageDir)
+
+	f := &CloudFunctions{}
+	assert.Equal("handler", f.getEntryPoint(function))
+	b, err := os.ReadFile(filepath.Join(packageDir, "index.js"))
+	assert.Nil(err)
+	assert.Contains(string(b), "exports.handler = (req, res) =>")
+	assert.NotContains(string(b), "event")
+}
+
+func TestCloudFunctionsCreatePythonMain(t *testing.T) {
+	assert := assert.New(t)
+	f := &CloudFunctions{config: &config.Config{}}
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "handler.py"), []byte(handlers.GoogleCloudFunctionsHandlerDjango), 0644)
+	assert.Nil(err)
+
+	err = f.createPythonMain(dir, "handler.handler")
+	assert.Nil(err)
+	assert.NoFileExists(filepath.Join(dir, "handler.py"))
+	b, err := os.ReadFile(filepath.Join(dir, "main.py"))
+	assert.Nil(err)
+	assert.Contains(string(b), "def handler(request):")
+	assert.Contains(string(b), "Response.from_app(application, request.environ)")
+
+	err = f.createPythonMain(dir, "main.handler")
+	assert.Nil(err)
+
+	f.config.Platform.Handler = "app.views.index"
+	err = f.createPythonMain(dir, "app.views.index")
+	assert.EqualError(err, "cannot use handler app.views.index. Cloud Functions loads Python functions from main.py")
+
+	dir = t.TempDir()
+	err = f.createPythonMain(dir, "app.views.index")
+	assert.Nil(err)
+	b, err = os.ReadFile(filepath.Join(dir, "main.py"))
+	assert.Nil(err)
+	assert.Equal("from app.views import index\n", string(b))
+}
+
+func TestCloudFunctionsDeployLifecycle(t *testing.T) {
+	assert := assert.New(t)
+	fake, server := newFakeGoogleCloud(t)
+	f := helperCloudFunctions(t, server.URL)
+
+	deployed, err := f.Deploy(helperArchive(t))
+	assert.Nil(err)
+	assert.False(deployed)
+	assert.True(fake.bucket)
+	assert.Len(fake.objects, 1)
+	assert.Equal("python311", fake.function.BuildConfig.Runtime)
+	assert.Equal("handler", fake.function.BuildConfig.EntryPoint)
+	assert.Equal("512M", fake.function.ServiceConfig.AvailableMemory)
+	assert.Equal(30, fake.function.ServiceConfig.TimeoutSeconds)
+
+	deployed, err = f.Deploy(helperArchive(t))
+	assert.Nil(err)
+	assert.True(deployed)
+
+	first := fake.function.BuildConfig.Source.StorageSource.Object
+	err = f.Update(helperArchive(t))
+	assert.Nil(err)
+	assert.Len(fake.objects, 2)
+	assert.NotEqual(first, fake.function.BuildConfig.Source.StorageSource.Object)
+
+	err = f.Rollback(2)
+	assert.EqualError(err, "invalid revision for rollback. Aborting")
+	err = f.Rollback(1)
+	assert.Nil(err)
+	assert.Equal(first, fake.function.BuildConfig.Source.StorageSource.Object)
+
+	err = f.Invoke("migrate")
+	assert.Nil(err)
+	assert.Equal(`{"manage":"migrate"}`, fake.invoked)
+
+	err = f.Undeploy()
+	assert.Nil(err)
+	assert.Nil(fake.function)
+	assert.Len(fake.objects, 0)
+
+	err = f.Undeploy()
+	assert.EqualError(err, "can't find a deployed project. Run 'jerm deploy' to deploy instead")
+}
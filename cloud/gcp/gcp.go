@@ -0,0 +1,105 @@
+package gcp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/spatocode/jerm/internal/log"
+	"github.com/spatocode/jerm/internal/rest"
+	"github.com/spatocode/jerm/internal/utils"
+)
+
+const (
+	gcpAuthDocsUrl      = "https://cloud.google.com/sdk/gcloud/reference/auth/login"
+	functionsEndpoint   = "https://cloudfunctions.googleapis.com"
+	storageEndpoint     = "https://storage.googleapis.com"
+	loggingEndpoint     = "https://logging.googleapis.com"
+	accessTokenEnv      = "GOOGLE_OAUTH_ACCESS_TOKEN"
+	identityTokenEnv    = "GOOGLE_OAUTH_IDENTITY_TOKEN"
+	DefaultLocation     = "us-central1"
+	DefaultWaitDuration = 600
+)
+
+// credentials provides OAuth tokens for Google Cloud APIs.
+// Tokens are read from the environment or from the gcloud CLI.
+type credentials struct {
+	utils.ShellCommand
+	mu            sync.Mutex
+	accessToken   string
+	identityToken string
+}
+
+func newCredentials(cmd utils.ShellCommand) *credentials {
+	return &credentials{ShellCommand: cmd}
+}
+
+// token fetches and caches a token from env or the gcloud command
+func (c *credentials) token(cache *string, env string, args ...string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if *cache != "" {
+		return *cache, nil
+	}
+	if t := os.Getenv(env); t != "" {
+		*cache = t
+		return t, nil
+	}
+
+	log.Debug(fmt.Sprintf("fetching token with gcloud %s...", strings.Join(args, " ")))
+	out, err := c.RunCommand("gcloud", args...)
+	if err != nil || strings.TrimSpace(out) == "" {
+		msg := fmt.Sprintf("Unable to get Google Cloud credentials. Ensure you're logged in with gcloud before using Jerm. See here for more info %s", gcpAuthDocsUrl)
+		return "", errors.New(msg)
+	}
+	*cache = strings.TrimSpace(out)
+	return *cache, nil
+}
+
+// authorize sets an OAuth access token on a request
+func (c *credentials) authorize(req *http.Request) error {
+	token, err := c.token(&c.accessToken, accessTokenEnv, "auth", "print-access-token")
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// authorizeInvoke sets an identity token used for invoking functions
+func (c *credentials) authorizeInvoke(req *http.Request) error {
+	token, err := c.token(&c.identityToken, identityTokenEnv, "auth", "print-identity-token")
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// project returns the configured Google Cloud project
+// or the default project of the gcloud CLI
+func (c *credentials) project(cfg *config.Config) (string, error) {
+	if cfg.Platform.Project != "" {
+		return cfg.Platform.Project, nil
+	}
+
+	out, err := c.RunCommand("gcloud", "config", "get-value", "project")
+	if err != nil || strings.TrimSpace(out) == "" {
+		return "", errors.New("cannot find a Google Cloud project. Please specify project in your jerm.json file")
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// newClient creates a REST client for endpoint.
+// The endpoint can be overridden with the platform endpoint configuration.
+func newClient(cfg *config.Config, endpoint string, authorize func(*http.Request) error) *rest.Client {
+	if cfg.Platform.Endpoint != "" {
+		endpoint = cfg.Platform.Endpoint
+	}
+	return rest.NewClient(endpoint, authorize)
+}
@@ -0,0 +1,107 @@
+package gcp
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/spatocode/jerm/internal/log"
+	"github.com/spatocode/jerm/internal/rest"
+)
+
+// CloudLogging is the Google Cloud Logging operations
+type CloudLogging struct {
+	config   *config.Config
+	project  string
+	client   *rest.Client
+	interval time.Duration
+}
+
+type logEntry struct {
+	Timestamp   string                 `json:"timestamp"`
+	TextPayload string                 `json:"textPayload"`
+	JsonPayload map[string]interface{} `json:"jsonPayload"`
+}
+
+// NewCloudLogging creates a new Google Cloud Logging monitor
+func NewCloudLogging(cfg *config.Config, project string, authorize func(*http.Request) error) *CloudLogging {
+	return &CloudLogging{
+		config:   cfg,
+		project:  project,
+		client:   newClient(cfg, loggingEndpoint, authorize),
+		interval: time.Second,
+	}
+}
+
+// Watch is an infinite loop that continously fetches the function log entries
+func (c *CloudLogging) Watch() {
+	since := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339Nano)
+	for {
+		entries, err := c.getLogs(since)
+		if err != nil {
+			log.Debug(err.Error())
+			return
+		}
+		c.printLogs(entries)
+		if len(entries) > 0 {
+			since = entries[len(entries)-1].Timestamp
+		}
+		time.Sleep(c.interval)
+	}
+}
+
+// printLogs prints the log entries to stdout
+func (c *CloudLogging) printLogs(entries []logEntry) {
+	for _, entry := range entries {
+		message := entry.TextPayload
+		if message == "" && entry.JsonPayload != nil {
+			message = fmt.Sprint(entry.JsonPayload["message"])
+		}
+		if strings.TrimSpace(message) == "" {
+			continue
+		}
+		log.PrintfInfo("[%s] %s\n", entry.Timestamp, strings.TrimSpace(message))
+	}
+}
+
+// getLogs fetches all the function log entries newer than since
+func (c *CloudLogging) getLogs(since string) ([]logEntry, error) {
+	var entries []logEntry
+	filter := fmt.Sprintf(`resource.type="cloud_run_revision" AND resource.labels.service_name="%s" AND timestamp>"%s"`,
+		c.config.GetFunctionName(), since)
+	pageToken := ""
+	for {
+		req := map[string]interface{}{
+			"resourceNames": []string{fmt.Sprintf("projects/%s", c.project)},
+			"filter":        filter,
+			"orderBy":       "timestamp asc",
+			"pageSize":      1000,
+		}
+		if pageToken != "" {
+			req["pageToken"] = pageToken
+		}
+		resp := struct {
+			Entries       []logEntry `json:"entries"`
+			NextPageToken string     `json:"nextPageToken"`
+		}{}
+		err := c.client.Do(http.MethodPost, "/v2/entries:list", req, &resp)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, resp.Entries...)
+		if resp.NextPageToken == "" {
+			break
+		}
+		pageToken = resp.NextPageToken
+	}
+	return entries, nil
+}
+
+// Clear deletes a log and all its entries
+func (c *CloudLogging) Clear(name string) error {
+	path := fmt.Sprintf("/v2/projects/%s/logs/%s", c.project, url.PathEscape(name))
+	return c.client.Do(http.MethodDelete, path, nil, nil)
+}
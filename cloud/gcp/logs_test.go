@@ -0,0 +1,28 @@
+package gcp
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCloudLogging(t *testing.T) {
+	assert := assert.New(t)
+	cfg := &config.Config{}
+	c := NewCloudLogging(cfg, "test", nil)
+	assert.Equal(cfg, c.config)
+	assert.Equal(loggingEndpoint, c.client.BaseURL)
+}
+
+func TestCloudLoggingGetLogs(t *testing.T) {
+	assert := assert.New(t)
+	_, server := newFakeGoogleCloud(t)
+	cfg := &config.Config{Name: "test", Stage: "dev", Platform: config.Platform{Endpoint: server.URL}}
+	c := NewCloudLogging(cfg, "test", func(r *http.Request) error { return nil })
+	entries, err := c.getLogs("2022-01-01T00:00:00Z")
+	assert.Nil(err)
+	assert.Len(entries, 1)
+	assert.Equal("hello", entries[0].TextPayload)
+}
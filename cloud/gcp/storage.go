@@ -0,0 +1,128 @@
+package gcp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/spatocode/jerm/internal/log"
+	"github.com/spatocode/jerm/internal/rest"
+)
+
+// Storage is the Google Cloud Storage operations
+type Storage struct {
+	config  *config.Config
+	project string
+	client  *rest.Client
+}
+
+type object struct {
+	Name        string `json:"name"`
+	Size        string `json:"size"`
+	TimeCreated string `json:"timeCreated"`
+}
+
+// NewStorage creates a new Google Cloud Storage object
+func NewStorage(cfg *config.Config, project string, authorize func(*http.Request) error) *Storage {
+	return &Storage{
+		config:  cfg,
+		project: project,
+		client:  newClient(cfg, storageEndpoint, authorize),
+	}
+}
+
+// Upload uploads a file to the storage bucket
+func (s *Storage) Upload(filePath string) error {
+	return s.uploadObject(filepath.Base(filePath), filePath)
+}
+
+// uploadObject uploads a file to the storage bucket as name
+func (s *Storage) uploadObject(name, filePath string) error {
+	f, err := os.Stat(filePath)
+	if err != nil || f.Size() == 0 {
+		msg := "encountered issue with packaged file"
+		return errors.New(msg)
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	log.Debug(fmt.Sprintf("uploading file %s...", name))
+	path := fmt.Sprintf("/upload/storage/v1/b/%s/o?uploadType=media&name=%s", s.config.Bucket, url.QueryEscape(name))
+	req, err := s.client.NewRequest(http.MethodPost, path, file)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/zip")
+	req.ContentLength = f.Size()
+
+	err = s.client.Send(req, nil)
+	if err != nil {
+		msg := "encountered error while uploading package. Aborting"
+		return fmt.Errorf("%s : %s", err.Error(), msg)
+	}
+	return nil
+}
+
+// Accessible checks if the storage bucket exists and is accessible
+func (s *Storage) Accessible() error {
+	log.Debug(fmt.Sprintf("checking storage bucket %s...", s.config.Bucket))
+	return s.client.Do(http.MethodGet, fmt.Sprintf("/storage/v1/b/%s", s.config.Bucket), nil, nil)
+}
+
+// Delete deletes an object from the storage bucket
+func (s *Storage) Delete(name string) error {
+	log.Debug(fmt.Sprintf("deleting storage bucket object %s...", name))
+	path := fmt.Sprintf("/storage/v1/b/%s/o/%s", s.config.Bucket, url.PathEscape(name))
+	return s.client.Do(http.MethodDelete, path, nil, nil)
+}
+
+// CreateBucket creates the storage bucket.
+// If isConfig is true, the bucket is created in the configured region.
+func (s *Storage) CreateBucket(isConfig bool) error {
+	log.Debug(fmt.Sprintf("creating storage bucket with config %t...", isConfig))
+	bucket := map[string]string{"name": s.config.Bucket}
+	if isConfig && s.config.Region != "" {
+		bucket["location"] = s.config.Region
+	}
+	path := fmt.Sprintf("/storage/v1/b?project=%s", url.QueryEscape(s.project))
+	return s.client.Do(http.MethodPost, path, bucket, nil)
+}
+
+// listObjects lists the objects with prefix sorted from the newest
+func (s *Storage) listObjects(prefix string) ([]object, error) {
+	var objects []object
+	pageToken := ""
+	for {
+		resp := struct {
+			Items         []object `json:"items"`
+			NextPageToken string   `json:"nextPageToken"`
+		}{}
+		path := fmt.Sprintf("/storage/v1/b/%s/o?prefix=%s", s.config.Bucket, url.QueryEscape(prefix))
+		if pageToken != "" {
+			path = fmt.Sprintf("%s&pageToken=%s", path, url.QueryEscape(pageToken))
+		}
+		err := s.client.Do(http.MethodGet, path, nil, &resp)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, resp.Items...)
+		if resp.NextPageToken == "" {
+			break
+		}
+		pageToken = resp.NextPageToken
+	}
+
+	sort.Slice(objects, func(i int, j int) bool {
+		return objects[i].Name > objects[j].Name
+	})
+	return objects, nil
+}
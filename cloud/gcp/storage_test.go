@@ -0,0 +1,50 @@
+package gcp
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/spatocode/jerm/internal/rest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewStorage(t *testing.T) {
+	assert := assert.New(t)
+	cfg := &config.Config{Platform: config.Platform{Endpoint: "http://localhost"}}
+	s := NewStorage(cfg, "test", nil)
+	assert.Equal(cfg, s.config)
+	assert.Equal("http://localhost", s.client.BaseURL)
+}
+
+func TestStorageUploadAndDelete(t *testing.T) {
+	assert := assert.New(t)
+	fake, server := newFakeGoogleCloud(t)
+	cfg := &config.Config{Bucket: "testbucket", Platform: config.Platform{Endpoint: server.URL}}
+	s := NewStorage(cfg, "test", func(r *http.Request) error { return nil })
+
+	err := s.Accessible()
+	assert.True(rest.IsNotFound(err))
+	err = s.CreateBucket(true)
+	assert.Nil(err)
+	assert.Nil(s.Accessible())
+
+	err = s.Upload(helperArchive(t))
+	assert.Nil(err)
+	assert.True(fake.objects["jerm.zip"])
+
+	objects, err := s.listObjects("jerm")
+	assert.Nil(err)
+	assert.Len(objects, 1)
+
+	err = s.Delete("jerm.zip")
+	assert.Nil(err)
+	assert.False(fake.objects["jerm.zip"])
+}
+
+func TestStorageUploadEmptyFile(t *testing.T) {
+	assert := assert.New(t)
+	s := NewStorage(&config.Config{}, "test", nil)
+	err := s.Upload("unknown.zip")
+	assert.EqualError(err, "encountered issue with packaged file")
+}
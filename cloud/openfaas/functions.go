@@ -0,0 +1,408 @@
+package openfaas
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spatocode/jerm"
+	"github.com/spatocode/jerm/config"
+	"github.com/spatocode/jerm/config/handlers"
+	"github.com/spatocode/jerm/internal/log"
+	"github.com/spatocode/jerm/internal/rest"
+	"github.com/spatocode/jerm/internal/utils"
+)
+
+func init() {
+	jerm.RegisterPlatform(config.OpenFaaS, func(cfg *config.Config) (jerm.CloudPlatform, error) {
+		f, err := NewFunctions(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return f, nil
+	})
+}
+
+// Functions is the OpenFaaS operations
+type Functions struct {
+	command           utils.ShellCommand
+	monitor           jerm.CloudMonitor
+	config            *config.Config
+	client            *rest.Client
+	maxWaiterDuration time.Duration
+	pollInterval      time.Duration
+}
+
+// functionDeployment is an OpenFaaS function deployment request
+type functionDeployment struct {
+	Service     string            `json:"service"`
+	Image       string            `json:"image"`
+	EnvVars     map[string]string `json:"envVars,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Limits      *resources        `json:"limits,omitempty"`
+}
+
+type resources struct {
+	Memory string `json:"memory,omitempty"`
+}
+
+// functionStatus is the status of a deployed OpenFaaS function
+type functionStatus struct {
+	Name              string            `json:"name"`
+	Image             string            `json:"image"`
+	Annotations       map[string]string `json:"annotations"`
+	Replicas          int               `json:"replicas"`
+	AvailableReplicas int               `json:"availableReplicas"`
+}
+
+// NewFunctions instantiates a new OpenFaaS service
+func NewFunctions(cfg *config.Config) (*Functions, error) {
+	f := &Functions{
+		command:           utils.Command(),
+		config:            cfg,
+		client:            newClient(cfg),
+		maxWaiterDuration: DefaultWaitDuration,
+		pollInterval:      time.Second * 2,
+	}
+
+	if f.config.Platform.Name == "" {
+		f.config.Platform.Name = config.OpenFaaS
+	}
+	err := f.config.Platform.Defaults()
+	if err != nil {
+		return nil, err
+	}
+
+	f.monitor = NewLogs(cfg, f.client)
+
+	return f, nil
+}
+
+func (f *Functions) WithMonitor(monitor jerm.CloudMonitor) {
+	f.monitor = monitor
+}
+
+// Build builds the deployment package for OpenFaaS.
+// The package includes a Dockerfile which serves the function handler
+// behind the OpenFaaS watchdog.
+func (f *Functions) Build() (string, error) {
+	log.Debug("building Jerm project for OpenFaaS...")
+
+	_, err := f.registry()
+	if err != nil {
+		return "", err
+	}
+
+	r := config.NewRuntime()
+
+	go func() {
+		err := f.config.ToJson(jerm.DefaultConfigFile)
+		if err != nil {
+			log.PrintWarn(err)
+		}
+	}()
+
+	var dockerfile, handler, handlerFile, version string
+	switch runtime := r.(type) {
+	case *config.Python:
+		dockerfile, handler, handlerFile = handlers.OpenFaaSDockerfilePython, handlers.OpenFaaSHandlerPython, "jerm_openfaas.py"
+		version = f.imageVersion(runtime.Version, 2)
+	case *config.Node:
+		dockerfile, handler, handlerFile = handlers.OpenFaaSDockerfileNode, handlers.OpenFaaSHandlerNode, "jerm_openfaas.js"
+		version = f.imageVersion(runtime.Version, 1)
+	case *config.Runtime:
+		if runtime.Name != config.RuntimeStatic {
+			return "", errors.New("cannot detect runtime. OpenFaaS supports Python, Node and static projects only")
+		}
+		dockerfile, handler, handlerFile = handlers.OpenFaaSDockerfileNode, handlers.OpenFaaSHandlerNode, "jerm_openfaas.js"
+		version = f.imageVersion(config.DefaultNodeVersion, 1)
+	default:
+		return "", errors.New("OpenFaaS supports Python, Node and static projects only")
+	}
+
+	packageDir, function, err := r.Build(f.config)
+	if err != nil {
+		return "", err
+	}
+	if function == "" {
+		function = f.config.Platform.Handler
+	}
+	if function == "" {
+		return "", errors.New("cannot find a function handler. Please specify handler in your jerm.json file")
+	}
+
+	err = os.WriteFile(filepath.Join(packageDir, handlerFile), []byte(handler), 0644)
+	if err != nil {
+		return "", err
+	}
+
+	err = os.WriteFile(filepath.Join(packageDir, "Dockerfile"), []byte(fmt.Sprintf(dockerfile, version, function)), 0644)
+	if err != nil {
+		return "", err
+	}
+
+	return packageDir, nil
+}
+
+// imageVersion returns the first parts of a runtime version used as image tag
+func (f *Functions) imageVersion(version string, parts int) string {
+	v := strings.Split(version, ".")
+	if len(v) < parts {
+		return version
+	}
+	return strings.Join(v[:parts], ".")
+}
+
+// Deploy builds the function image and deploys it to the OpenFaaS gateway
+func (f *Functions) Deploy(zipPath string) (bool, error) {
+	_, err := f.getFunction()
+	if err == nil {
+		return true, nil
+	}
+	if !rest.IsNotFound(err) {
+		return false, err
+	}
+
+	image, err := f.buildImage(zipPath)
+	if err != nil {
+		return false, err
+	}
+
+	log.Debug("deploying function...")
+	err = f.deployFunction(http.MethodPost, image, []string{image})
+	if err != nil {
+		return false, err
+	}
+
+	err = f.waitForReady()
+	if err != nil {
+		return false, err
+	}
+
+	f.printUrl()
+
+	return false, utils.RemoveLocalFile(zipPath)
+}
+
+// Update builds a new function image and updates the deployed function
+func (f *Functions) Update(zipPath string) error {
+	fn, err := f.getFunction()
+	if err != nil {
+		return err
+	}
+
+	image, err := f.buildImage(zipPath)
+	if err != nil {
+		return err
+	}
+
+	log.Debug("updating function...")
+	err = f.deployFunction(http.MethodPut, image, append([]string{image}, f.imageHistory(fn)...))
+	if err != nil {
+		return err
+	}
+
+	err = f.waitForReady()
+	if err != nil {
+		return err
+	}
+
+	f.printUrl()
+
+	return utils.RemoveLocalFile(zipPath)
+}
+
+// Undeploy deletes the function from the OpenFaaS gateway
+func (f *Functions) Undeploy() error {
+	_, err := f.getFunction()
+	if err != nil {
+		if rest.IsNotFound(err) {
+			msg := "can't find a deployed project. Run 'jerm deploy' to deploy instead"
+			return errors.New(msg)
+		}
+		return err
+	}
+
+	log.Debug("undeploying...")
+	return f.client.Do(http.MethodDelete, "/system/functions", map[string]string{"functionName": f.config.GetFunctionName()}, nil)
+}
+
+// Rollback redeploys a previously deployed function image
+func (f *Functions) Rollback(steps int) error {
+	fn, err := f.getFunction()
+	if err != nil {
+		if rest.IsNotFound(err) {
+			msg := "can't find a deployed project. Run 'jerm deploy' to deploy instead"
+			return errors.New(msg)
+		}
+		return err
+	}
+
+	history := f.imageHistory(fn)
+	current := 0
+	for i, image := range history {
+		if image == fn.Image {
+			current = i
+			break
+		}
+	}
+
+	if len(history) <= current+steps || steps < 1 {
+		msg := "invalid revision for rollback. Aborting"
+		return errors.New(msg)
+	}
+
+	image := history[current+steps]
+	log.Debug(fmt.Sprintf("deploying function image %s...", image))
+	err = f.deployFunction(http.MethodPut, image, history)
+	if err != nil {
+		return err
+	}
+	return f.waitForReady()
+}
+
+// Logs shows the function logs
+func (f *Functions) Logs() {
+	f.monitor.Watch()
+}
+
+// Invoke invokes the function through the gateway with a management command
+func (f *Functions) Invoke(command string) error {
+	req, err := f.client.NewJSONRequest(http.MethodPost, f.functionPath(), map[string]string{"manage": command})
+	if err != nil {
+		return err
+	}
+
+	res, err := f.client.Response(req)
+	if err != nil {
+		return fmt.Errorf("%s - encountered an error while invoking function", err)
+	}
+	defer res.Body.Close()
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	log.PrintInfo(string(b))
+	return nil
+}
+
+// getFunction gets the status of the deployed function
+func (f *Functions) getFunction() (*functionStatus, error) {
+	fn := &functionStatus{}
+	err := f.client.Do(http.MethodGet, fmt.Sprintf("/system/function/%s", f.config.GetFunctionName()), nil, fn)
+	if err != nil {
+		return nil, err
+	}
+	return fn, nil
+}
+
+// imageHistory returns the images deployed for the function sorted from the newest
+func (f *Functions) imageHistory(fn *functionStatus) []string {
+	history := fn.Annotations[imagesAnnotation]
+	if history == "" {
+		return []string{fn.Image}
+	}
+	return strings.Split(history, ",")
+}
+
+// deployFunction creates or updates the function with image.
+// history is stored in the function annotations for rollbacks.
+func (f *Functions) deployFunction(method, image string, history []string) error {
+	timeout := fmt.Sprintf("%ds", f.config.Platform.Timeout)
+	deployment := &functionDeployment{
+		Service: f.config.GetFunctionName(),
+		Image:   image,
+		EnvVars: map[string]string{
+			"read_timeout":  timeout,
+			"write_timeout": timeout,
+			"exec_timeout":  timeout,
+		},
+		Labels: map[string]string{
+			"com.jerm.stage": f.config.Stage,
+		},
+		Annotations: map[string]string{
+			imagesAnnotation: strings.Join(history, ","),
+		},
+		Limits: &resources{Memory: fmt.Sprintf("%dMi", f.config.Platform.Memory)},
+	}
+	return f.client.Do(method, "/system/functions", deployment, nil)
+}
+
+// registry returns the registry the function images are pushed to. The cluster
+// pulls the images from it, so it's required.
+func (f *Functions) registry() (string, error) {
+	if f.config.Platform.Registry == "" {
+		return "", errors.New("no registry configured. Set platform.registry in your jerm.json file to a registry the cluster can pull from")
+	}
+	return strings.TrimSuffix(f.config.Platform.Registry, "/"), nil
+}
+
+// buildImage builds the function image from the package archive
+// and pushes it to the configured registry
+func (f *Functions) buildImage(zipPath string) (string, error) {
+	registry, err := f.registry()
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := os.MkdirTemp(os.TempDir(), "jerm-image")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+
+	err = utils.Unzip(zipPath, dir)
+	if err != nil {
+		return "", err
+	}
+
+	image := fmt.Sprintf("%s/%s:%d", registry, f.config.GetFunctionName(), time.Now().UnixNano())
+
+	log.Debug(fmt.Sprintf("building image %s...", image))
+	_, err = f.command.RunCommand("docker", "build", "-t", image, dir)
+	if err != nil {
+		return "", err
+	}
+
+	log.Debug(fmt.Sprintf("pushing image %s...", image))
+	_, err = f.command.RunCommand("docker", "push", image)
+	if err != nil {
+		return "", err
+	}
+	return image, nil
+}
+
+// waitForReady polls the function until a replica is available
+func (f *Functions) waitForReady() error {
+	deadline := time.Now().Add(time.Second * f.maxWaiterDuration)
+	for {
+		fn, err := f.getFunction()
+		if err != nil {
+			return err
+		}
+		if fn.AvailableReplicas > 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for function %s to be ready", f.config.GetFunctionName())
+		}
+		log.Debug("waiting for function to be ready...")
+		time.Sleep(f.pollInterval)
+	}
+}
+
+func (f *Functions) functionPath() string {
+	return fmt.Sprintf("/function/%s", f.config.GetFunctionName())
+}
+
+func (f *Functions) printUrl() {
+	url := gateway(f.config) + f.functionPath()
+	fmt.Printf("%s %s\n", log.Magenta("url:"), log.Green(url))
+}
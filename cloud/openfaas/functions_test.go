@@ -0,0 +1,207 @@
+package openfaas
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeGateway is a local stand-in for the OpenFaaS gateway REST API
+type fakeGateway struct {
+	mu       sync.Mutex
+	function *functionDeployment
+	username string
+	invoked  string
+}
+
+func newFakeGateway(t *testing.T) (*fakeGateway, *httptest.Server) {
+	fake := &fakeGateway{}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func (g *fakeGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.username, _, _ = r.BasicAuth()
+	switch {
+	case r.URL.Path == "/system/logs":
+		w.Write([]byte(`{"name": "test-dev", "instance": "test-dev-1", "timestamp": "2023-01-01T00:00:00Z", "text": "hello"}` + "\n"))
+	case r.URL.Path == "/function/test-dev":
+		if g.function == nil {
+			http.NotFound(w, r)
+			return
+		}
+		b, _ := io.ReadAll(r.Body)
+		g.invoked = string(b)
+		w.Write([]byte("invoked"))
+	case r.URL.Path == "/system/function/test-dev":
+		if g.function == nil {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(&functionStatus{
+			Name:              g.function.Service,
+			Image:             g.function.Image,
+			Annotations:       g.function.Annotations,
+			AvailableReplicas: 1,
+		})
+	case r.URL.Path == "/system/functions":
+		switch r.Method {
+		case http.MethodPost, http.MethodPut:
+			fn := &functionDeployment{}
+			json.NewDecoder(r.Body).Decode(fn)
+			g.function = fn
+		case http.MethodDelete:
+			g.function = nil
+		}
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// fakeDocker records the docker commands
+type fakeDocker struct {
+	commands []string
+	err      error
+}
+
+func (d *fakeDocker) RunCommand(command string, args ...string) (string, error) {
+	d.commands = append(d.commands, command+" "+strings.Join(args, " "))
+	return "", d.err
+}
+
+func (d *fakeDocker) RunCommandWithEnv(env []string, command string, args ...string) (string, error) {
+	return d.RunCommand(command, args...)
+}
+
+func helperFunctions(t *testing.T, endpoint string) (*Functions, *fakeDocker) {
+	cfg := &config.Config{
+		Name:     "test",
+		Stage:    "dev",
+		Platform: config.Platform{Name: config.OpenFaaS, Endpoint: endpoint, Registry: "registry.local/jerm"},
+	}
+	f, err := NewFunctions(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	docker := &fakeDocker{}
+	f.command = docker
+	f.pollInterval = 0
+	return f, docker
+}
+
+func helperArchive(t *testing.T) string {
+	archive := filepath.Join(t.TempDir(), "jerm.zip")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	writer := zip.NewWriter(f)
+	w, _ := writer.Create("/Dockerfile")
+	w.Write([]byte("FROM scratch"))
+	writer.Close()
+	return archive
+}
+
+func TestNewFunctions(t *testing.T) {
+	assert := assert.New(t)
+	cfg := &config.Config{Name: "test", Stage: "dev"}
+	f, err := NewFunctions(cfg)
+	assert.Nil(err)
+	assert.Equal(config.OpenFaaS, cfg.Platform.Name)
+	assert.Equal(config.DefaultMemory, cfg.Platform.Memory)
+	assert.Equal(defaultGateway+"/function/test-dev", gateway(f.config)+f.functionPath())
+}
+
+func TestFunctionsImageVersion(t *testing.T) {
+	assert := assert.New(t)
+	f := &Functions{}
+	assert.Equal("3.11", f.imageVersion("3.11.4", 2))
+	assert.Equal("18", f.imageVersion("18.13.0", 1))
+	assert.Equal("18", f.imageVersion("18", 2))
+}
+
+func TestFunctionsDeployWithoutRegistry(t *testing.T) {
+	assert := assert.New(t)
+	f, docker := helperFunctions(t, "http://localhost")
+	f.config.Platform.Registry = ""
+
+	_, err := f.Build()
+	assert.EqualError(err, "no registry configured. Set platform.registry in your jerm.json file to a registry the cluster can pull from")
+	_, err = f.buildImage(helperArchive(t))
+	assert.EqualError(err, "no registry configured. Set platform.registry in your jerm.json file to a registry the cluster can pull from")
+	assert.Empty(docker.commands)
+}
+
+func TestFunctionsDeployLifecycle(t *testing.T) {
+	assert := assert.New(t)
+	t.Setenv(passwordEnv, "secret")
+	fake, server := newFakeGateway(t)
+	f, docker := helperFunctions(t, server.URL)
+
+	deployed, err := f.Deploy(helperArchive(t))
+	assert.Nil(err)
+	assert.False(deployed)
+	assert.Equal(defaultUsername, fake.username)
+	assert.Len(docker.commands, 2)
+	assert.True(strings.HasPrefix(docker.commands[0], "docker build -t registry.local/jerm/test-dev:"))
+	assert.True(strings.HasPrefix(docker.commands[1], "docker push registry.local/jerm/test-dev:"))
+	first := fake.function.Image
+	assert.Equal(fmt.Sprintf("%dMi", config.DefaultMemory), fake.function.Limits.Memory)
+	assert.Equal(fmt.Sprintf("%ds", config.DefaultTimeout), fake.function.EnvVars["exec_timeout"])
+
+	deployed, err = f.Deploy(helperArchive(t))
+	assert.Nil(err)
+	assert.True(deployed)
+
+	err = f.Update(helperArchive(t))
+	assert.Nil(err)
+	assert.NotEqual(first, fake.function.Image)
+	assert.Equal(fake.function.Image+","+first, fake.function.Annotations[imagesAnnotation])
+
+	err = f.Rollback(2)
+	assert.EqualError(err, "invalid revision for rollback. Aborting")
+	err = f.Rollback(1)
+	assert.Nil(err)
+	assert.Equal(first, fake.function.Image)
+
+	err = f.Invoke("migrate")
+	assert.Nil(err)
+	assert.Equal(`{"manage":"migrate"}`, fake.invoked)
+
+	err = f.Undeploy()
+	assert.Nil(err)
+	assert.Nil(fake.function)
+	err = f.Undeploy()
+	assert.EqualError(err, "can't find a deployed project. Run 'jerm deploy' to deploy instead")
+	err = f.Rollback(1)
+	assert.EqualError(err, "can't find a deployed project. Run 'jerm deploy' to deploy instead")
+}
+
+func TestFunctionsDeployImageError(t *testing.T) {
+	assert := assert.New(t)
+	fake, server := newFakeGateway(t)
+	f, docker := helperFunctions(t, server.URL)
+	docker.err = errors.New("docker: command not found")
+
+	_, err := f.Deploy(helperArchive(t))
+	assert.EqualError(err, "docker: command not found")
+	assert.Nil(fake.function)
+}
@@ -0,0 +1,75 @@
+package openfaas
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/spatocode/jerm/internal/log"
+	"github.com/spatocode/jerm/internal/rest"
+)
+
+// Logs is the OpenFaaS gateway log operations
+type Logs struct {
+	config *config.Config
+	client *rest.Client
+}
+
+type logMessage struct {
+	Name      string `json:"name"`
+	Instance  string `json:"instance"`
+	Timestamp string `json:"timestamp"`
+	Text      string `json:"text"`
+}
+
+// NewLogs creates a new OpenFaaS log monitor
+func NewLogs(cfg *config.Config, client *rest.Client) *Logs {
+	return &Logs{
+		config: cfg,
+		client: client,
+	}
+}
+
+// Watch follows the function logs until the stream is closed
+func (l *Logs) Watch() {
+	query := url.Values{}
+	query.Set("name", l.config.GetFunctionName())
+	query.Set("follow", "true")
+	query.Set("tail", "100")
+
+	req, err := l.client.NewRequest(http.MethodGet, "/system/logs?"+query.Encode(), nil)
+	if err != nil {
+		log.Debug(err.Error())
+		return
+	}
+
+	body, err := l.client.Stream(req)
+	if err != nil {
+		log.Debug(err.Error())
+		return
+	}
+	defer body.Close()
+
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		message := &logMessage{}
+		err := json.Unmarshal(scanner.Bytes(), message)
+		if err != nil {
+			log.Debug(err.Error())
+			continue
+		}
+		log.PrintInfo(fmt.Sprintf("[%s] %s %s", message.Timestamp, message.Instance, message.Text))
+	}
+	if err := scanner.Err(); err != nil {
+		log.Debug(err.Error())
+	}
+}
+
+// Clear is not supported as OpenFaaS reads logs from the cluster
+func (l *Logs) Clear(name string) error {
+	return errors.New("clearing logs isn't supported by OpenFaaS")
+}
@@ -0,0 +1,31 @@
+package openfaas
+
+import (
+	"testing"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLogs(t *testing.T) {
+	assert := assert.New(t)
+	cfg := &config.Config{Name: "test", Stage: "dev"}
+	client := newClient(cfg)
+	l := NewLogs(cfg, client)
+	assert.Equal(cfg, l.config)
+	assert.Equal(client, l.client)
+}
+
+func TestLogsWatch(t *testing.T) {
+	_, server := newFakeGateway(t)
+	cfg := &config.Config{Name: "test", Stage: "dev", Platform: config.Platform{Endpoint: server.URL}}
+	l := NewLogs(cfg, newClient(cfg))
+	l.Watch()
+}
+
+func TestLogsClear(t *testing.T) {
+	assert := assert.New(t)
+	cfg := &config.Config{Name: "test", Stage: "dev"}
+	l := NewLogs(cfg, newClient(cfg))
+	assert.Error(l.Clear("test-dev"))
+}
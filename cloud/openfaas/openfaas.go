@@ -0,0 +1,45 @@
+package openfaas
+
+import (
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/spatocode/jerm/internal/rest"
+)
+
+const (
+	defaultGateway      = "http://127.0.0.1:8080"
+	defaultUsername     = "admin"
+	usernameEnv         = "OPENFAAS_USERNAME"
+	passwordEnv         = "OPENFAAS_PASSWORD"
+	imagesAnnotation    = "com.jerm.images"
+	DefaultWaitDuration = 300
+)
+
+// gateway returns the configured OpenFaaS gateway URL
+func gateway(cfg *config.Config) string {
+	if cfg.Platform.Endpoint != "" {
+		return strings.TrimSuffix(cfg.Platform.Endpoint, "/")
+	}
+	return defaultGateway
+}
+
+// newClient creates an OpenFaaS gateway client.
+// Requests are authorized with basic auth when OPENFAAS_PASSWORD is set.
+func newClient(cfg *config.Config) *rest.Client {
+	authorize := func(req *http.Request) error {
+		password := os.Getenv(passwordEnv)
+		if password == "" {
+			return nil
+		}
+		username := os.Getenv(usernameEnv)
+		if username == "" {
+			username = defaultUsername
+		}
+		req.SetBasicAuth(username, password)
+		return nil
+	}
+	return rest.NewClient(gateway(cfg), authorize)
+}
@@ -0,0 +1,123 @@
+/*
+Copyright © 2023 Ekene Izukanne <ekeneizukanne@gmail.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spatocode/jerm"
+	"github.com/spatocode/jerm/internal/log"
+)
+
+// apikeysCmd represents the apikeys command
+var apikeysCmd = &cobra.Command{
+	Use:   "apikeys",
+	Short: "Manage the API keys of the deployment stage",
+	Long:  "Manage the API keys required by the requests to the deployment stage",
+}
+
+// apikeysCreateCmd represents the apikeys create command
+var apikeysCreateCmd = &cobra.Command{
+	Use:   "create NAME",
+	Short: "Create an API key",
+	Long:  "Create an API key and show its value. The value can't be shown again",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		apiKeys, err := projectApiKeys(cmd)
+		if err != nil {
+			log.PrintError(err)
+			return
+		}
+
+		key, err := apiKeys.CreateApiKey(args[0])
+		if err != nil {
+			log.PrintError(err)
+			return
+		}
+		fmt.Printf("%s %s\n", log.Magenta("id:"), key.Id)
+		fmt.Printf("%s %s\n", log.Magenta("key:"), log.Green(key.Value))
+	},
+}
+
+// apikeysListCmd represents the apikeys list command
+var apikeysListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the API keys",
+	Long:  "List the API keys",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		apiKeys, err := projectApiKeys(cmd)
+		if err != nil {
+			log.PrintError(err)
+			return
+		}
+
+		keys, err := apiKeys.ListApiKeys()
+		if err != nil {
+			log.PrintError(err)
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tENABLED\tCREATED")
+		for _, key := range keys {
+			fmt.Fprintf(w, "%s\t%s\t%t\t%s\n", key.Id, key.Name, key.Enabled, key.Created.Local().Format(time.DateTime))
+		}
+		w.Flush()
+	},
+}
+
+// apikeysRevokeCmd represents the apikeys revoke command
+var apikeysRevokeCmd = &cobra.Command{
+	Use:   "revoke KEY",
+	Short: "Revoke an API key",
+	Long:  "Revoke an API key by its ID or name",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		apiKeys, err := projectApiKeys(cmd)
+		if err != nil {
+			log.PrintError(err)
+			return
+		}
+
+		err = apiKeys.RevokeApiKey(args[0])
+		if err != nil {
+			log.PrintError(err)
+		}
+	},
+}
+
+// projectApiKeys returns the API keys of the configured platform
+func projectApiKeys(cmd *cobra.Command) (jerm.CloudApiKeys, error) {
+	jerm.Verbose(cmd)
+
+	cfg, err := jerm.Configure(jerm.DefaultConfigFile)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := jerm.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	platform, err := jerm.NewPlatform(cfg)
+	if err != nil {
+		return nil, err
+	}
+	p.SetPlatform(platform)
+	return p.ApiKeys()
+}
+
+func init() {
+	rootCmd.AddCommand(apikeysCmd)
+	apikeysCmd.AddCommand(apikeysCreateCmd)
+	apikeysCmd.AddCommand(apikeysListCmd)
+	apikeysCmd.AddCommand(apikeysRevokeCmd)
+}
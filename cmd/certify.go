@@ -0,0 +1,49 @@
+/*
+Copyright © 2023 Ekene Izukanne <ekeneizukanne@gmail.com>
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/spatocode/jerm"
+	"github.com/spatocode/jerm/internal/log"
+)
+
+// certifyCmd represents the certify command
+var certifyCmd = &cobra.Command{
+	Use:   "certify",
+	Short: "Serves the deployment at its custom domain",
+	Long:  "Creates the certificate, the custom domain, the base path mapping and the DNS record of the domain in jerm.json, without redeploying the project",
+	Run: func(cmd *cobra.Command, args []string) {
+		jerm.Verbose(cmd)
+
+		cfg, err := jerm.Configure(jerm.DefaultConfigFile)
+		if err != nil {
+			log.PrintError(err)
+			return
+		}
+
+		p, err := jerm.New(cfg)
+		if err != nil {
+			log.PrintError(err)
+			return
+		}
+
+		platform, err := jerm.NewPlatform(cfg)
+		if err != nil {
+			log.PrintError(err)
+			return
+		}
+		p.SetPlatform(platform)
+
+		err = p.Certify()
+		if err != nil {
+			log.PrintError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(certifyCmd)
+}
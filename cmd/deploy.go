@@ -4,10 +4,14 @@ Copyright © 2023 Ekene Izukanne <ekeneizukanne@gmail.com>
 package cmd
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/spf13/cobra"
 
 	"github.com/spatocode/jerm"
-	"github.com/spatocode/jerm/cloud/aws"
 	"github.com/spatocode/jerm/internal/log"
 )
 
@@ -30,13 +34,28 @@ var deployCmd = &cobra.Command{
 			return
 		}
 
-		platform, err := aws.NewLambda(cfg)
+		platform, err := jerm.NewPlatform(cfg)
 		if err != nil {
 			log.PrintError(err)
 			return
 		}
 		p.SetPlatform(platform)
 
+		canary, _ := cmd.Flags().GetString("canary")
+		if canary != "" {
+			weight, err := strconv.Atoi(strings.TrimSuffix(canary, "%"))
+			if err != nil {
+				log.PrintError(fmt.Errorf("invalid canary weight %s", canary))
+				return
+			}
+			window, _ := cmd.Flags().GetDuration("canary-window")
+			err = p.SetCanary(weight, window)
+			if err != nil {
+				log.PrintError(err)
+				return
+			}
+		}
+
 		err = p.Deploy()
 		if err != nil {
 			log.PrintError(err)
@@ -47,6 +66,8 @@ var deployCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(deployCmd)
 
+	deployCmd.Flags().String("canary", "", "Percentage of traffic shifted to the new version before promoting it, e.g. 10%")
+	deployCmd.Flags().Duration("canary-window", 10*time.Minute, "How long the canary version is watched for errors")
 	// deployCmd.Flags().BoolP("production", "p", false, "Sets production stage")
 	// Here you will define your flags and configuration settings.
 
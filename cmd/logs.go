@@ -7,7 +7,6 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/spatocode/jerm"
-	"github.com/spatocode/jerm/cloud/aws"
 	"github.com/spatocode/jerm/internal/log"
 )
 
@@ -31,7 +30,7 @@ var logsCmd = &cobra.Command{
 			return
 		}
 
-		platform, err := aws.NewLambda(cfg)
+		platform, err := jerm.NewPlatform(cfg)
 		if err != nil {
 			log.PrintError(err)
 			return
@@ -0,0 +1,74 @@
+/*
+Copyright © 2023 Ekene Izukanne <ekeneizukanne@gmail.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spatocode/jerm"
+	"github.com/spatocode/jerm/internal/log"
+)
+
+// lsCmd represents the ls command
+var lsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "Lists the deployments of the cloud account",
+	Long:  "Lists the jerm deployments of the cloud account with the resources left behind by deleted ones",
+	Run: func(cmd *cobra.Command, args []string) {
+		jerm.Verbose(cmd)
+
+		cfg, err := jerm.Configure(jerm.DefaultConfigFile)
+		if err != nil {
+			log.PrintError(err)
+			return
+		}
+
+		p, err := jerm.New(cfg)
+		if err != nil {
+			log.PrintError(err)
+			return
+		}
+
+		platform, err := jerm.NewPlatform(cfg)
+		if err != nil {
+			log.PrintError(err)
+			return
+		}
+		p.SetPlatform(platform)
+
+		deployments, err := p.List()
+		if err != nil {
+			log.PrintError(err)
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PROJECT\tSTAGE\tRUNTIME\tLAST DEPLOY\tURL")
+		var orphaned []jerm.Deployment
+		for _, d := range deployments {
+			if len(d.Orphaned) > 0 {
+				orphaned = append(orphaned, d)
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", d.Project, d.Stage, d.Runtime, d.LastDeployed.Local().Format(time.DateTime), d.URL)
+		}
+		w.Flush()
+
+		for _, d := range orphaned {
+			log.PrintfWarn("\nOrphaned resources of %s %s:\n", d.Project, d.Stage)
+			for _, resource := range d.Orphaned {
+				fmt.Printf("  %s\n", resource)
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lsCmd)
+}
@@ -9,7 +9,6 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/spatocode/jerm"
-	"github.com/spatocode/jerm/cloud/aws"
 	"github.com/spatocode/jerm/config"
 	"github.com/spatocode/jerm/internal/log"
 	"github.com/spatocode/jerm/internal/utils"
@@ -32,7 +31,7 @@ var manageCmd = &cobra.Command{
 			return
 		}
 
-		platform, err := aws.NewLambda(cfg)
+		platform, err := jerm.NewPlatform(cfg)
 		if err != nil {
 			log.PrintError(err)
 			return
@@ -0,0 +1,52 @@
+/*
+Copyright © 2023 Ekene Izukanne <ekeneizukanne@gmail.com>
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/spatocode/jerm"
+	"github.com/spatocode/jerm/internal/log"
+)
+
+// pruneCmd represents the prune command
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Deletes old deployments beyond the retention",
+	Long:  "Deletes the function versions and deployment archives beyond the retention of the project. Versions referenced by aliases are kept",
+	Run: func(cmd *cobra.Command, args []string) {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		jerm.Verbose(cmd)
+
+		cfg, err := jerm.Configure(jerm.DefaultConfigFile)
+		if err != nil {
+			log.PrintError(err)
+			return
+		}
+
+		p, err := jerm.New(cfg)
+		if err != nil {
+			log.PrintError(err)
+			return
+		}
+
+		platform, err := jerm.NewPlatform(cfg)
+		if err != nil {
+			log.PrintError(err)
+			return
+		}
+		p.SetPlatform(platform)
+
+		err = p.Prune(dryRun)
+		if err != nil {
+			log.PrintError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pruneCmd)
+
+	pruneCmd.Flags().Bool("dry-run", false, "Lists the deployments that would be deleted without deleting them")
+}
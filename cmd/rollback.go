@@ -7,7 +7,6 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/spatocode/jerm"
-	"github.com/spatocode/jerm/cloud/aws"
 	"github.com/spatocode/jerm/internal/log"
 )
 
@@ -18,6 +17,7 @@ var rollbackCmd = &cobra.Command{
 	Long:  "Rolls back to the previous versions of the deployment",
 	Run: func(cmd *cobra.Command, args []string) {
 		steps, _ := cmd.Flags().GetInt("steps")
+		to, _ := cmd.Flags().GetString("to")
 		jerm.Verbose(cmd)
 
 		cfg, err := jerm.Configure(jerm.DefaultConfigFile)
@@ -32,13 +32,17 @@ var rollbackCmd = &cobra.Command{
 			return
 		}
 
-		platform, err := aws.NewLambda(cfg)
+		platform, err := jerm.NewPlatform(cfg)
 		if err != nil {
 			log.PrintError(err)
 			return
 		}
 		p.SetPlatform(platform)
-		err = p.Rollback(steps)
+		if to != "" {
+			err = p.RollbackTo(to)
+		} else {
+			err = p.Rollback(steps)
+		}
 		if err != nil {
 			log.PrintError(err)
 		}
@@ -49,6 +53,8 @@ func init() {
 	rootCmd.AddCommand(rollbackCmd)
 
 	rollbackCmd.Flags().IntP("steps", "s", 1, "Number of previous versions")
+	rollbackCmd.Flags().String("to", "", "Version to roll back to. See 'jerm versions'")
+	rollbackCmd.MarkFlagsMutuallyExclusive("steps", "to")
 	// Here you will define your flags and configuration settings.
 
 	// Cobra supports Persistent Flags which will work for this command
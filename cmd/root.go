@@ -8,6 +8,13 @@ import (
 
 	"github.com/spatocode/jerm"
 	"github.com/spf13/cobra"
+
+	// Registers the supported cloud platforms
+	_ "github.com/spatocode/jerm/cloud/aws"
+	_ "github.com/spatocode/jerm/cloud/azure"
+	_ "github.com/spatocode/jerm/cloud/cloudflare"
+	_ "github.com/spatocode/jerm/cloud/gcp"
+	_ "github.com/spatocode/jerm/cloud/openfaas"
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -0,0 +1,115 @@
+/*
+Copyright © 2023 Ekene Izukanne <ekeneizukanne@gmail.com>
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spatocode/jerm"
+	"github.com/spatocode/jerm/internal/log"
+)
+
+// secretsCmd represents the secrets command
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Manage the secrets of the deployment stage",
+	Long:  "Manage the secrets of the deployment stage",
+}
+
+// secretsSetCmd represents the secrets set command
+var secretsSetCmd = &cobra.Command{
+	Use:   "set KEY VALUE",
+	Short: "Store a secret",
+	Long:  "Store a secret",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		secrets, err := projectSecrets(cmd)
+		if err != nil {
+			log.PrintError(err)
+			return
+		}
+
+		err = secrets.SetSecret(args[0], args[1])
+		if err != nil {
+			log.PrintError(err)
+		}
+	},
+}
+
+// secretsGetCmd represents the secrets get command
+var secretsGetCmd = &cobra.Command{
+	Use:   "get KEY",
+	Short: "Show the value of a secret",
+	Long:  "Show the value of a secret",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		secrets, err := projectSecrets(cmd)
+		if err != nil {
+			log.PrintError(err)
+			return
+		}
+
+		value, err := secrets.GetSecret(args[0])
+		if err != nil {
+			log.PrintError(err)
+			return
+		}
+		fmt.Println(value)
+	},
+}
+
+// secretsListCmd represents the secrets list command
+var secretsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the secret keys",
+	Long:  "List the secret keys",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		secrets, err := projectSecrets(cmd)
+		if err != nil {
+			log.PrintError(err)
+			return
+		}
+
+		keys, err := secrets.ListSecrets()
+		if err != nil {
+			log.PrintError(err)
+			return
+		}
+		for _, key := range keys {
+			fmt.Println(key)
+		}
+	},
+}
+
+// projectSecrets returns the secret store of the configured platform
+func projectSecrets(cmd *cobra.Command) (jerm.CloudSecrets, error) {
+	jerm.Verbose(cmd)
+
+	cfg, err := jerm.Configure(jerm.DefaultConfigFile)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := jerm.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	platform, err := jerm.NewPlatform(cfg)
+	if err != nil {
+		return nil, err
+	}
+	p.SetPlatform(platform)
+	return p.Secrets()
+}
+
+func init() {
+	rootCmd.AddCommand(secretsCmd)
+	secretsCmd.AddCommand(secretsSetCmd)
+	secretsCmd.AddCommand(secretsGetCmd)
+	secretsCmd.AddCommand(secretsListCmd)
+}
@@ -4,12 +4,12 @@ Copyright © 2023 Ekene Izukanne <ekeneizukanne@gmail.com>
 package cmd
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
 
 	"github.com/spatocode/jerm"
-	"github.com/spatocode/jerm/cloud/aws"
 	"github.com/spatocode/jerm/internal/log"
 	"github.com/spatocode/jerm/internal/utils"
 )
@@ -20,6 +20,10 @@ var undeployCmd = &cobra.Command{
 	Short: "Undeploy a deployed application",
 	Long:  "Undeploy a deployed application",
 	Run: func(cmd *cobra.Command, args []string) {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		keepBucket, _ := cmd.Flags().GetBool("keep-bucket")
+		keepRole, _ := cmd.Flags().GetBool("keep-role")
+		opts := jerm.UndeployOptions{KeepBucket: keepBucket, KeepRole: keepRole}
 		jerm.Verbose(cmd)
 
 		cfg, err := jerm.Configure(jerm.DefaultConfigFile)
@@ -34,12 +38,28 @@ var undeployCmd = &cobra.Command{
 			return
 		}
 
-		platform, err := aws.NewLambda(cfg)
+		platform, err := jerm.NewPlatform(cfg)
 		if err != nil {
 			log.PrintError(err.Error())
 			return
 		}
 		p.SetPlatform(platform)
+
+		plan, err := p.UndeployPlan(opts)
+		if err != nil {
+			log.PrintError(err.Error())
+			return
+		}
+		if len(plan) > 0 {
+			log.PrintInfo("The following resources will be deleted:")
+			for _, resource := range plan {
+				fmt.Printf("  %s\n", resource)
+			}
+		}
+		if dryRun {
+			return
+		}
+
 		log.PrintWarn("Are you sure you want to undeploy? [y/n]")
 		ans, err := utils.ReadPromptInput("", os.Stdin)
 		if err != nil {
@@ -49,7 +69,7 @@ var undeployCmd = &cobra.Command{
 		if ans != "y" {
 			return
 		}
-		err = p.Undeploy()
+		err = p.Undeploy(opts)
 		if err != nil {
 			log.PrintError(err.Error())
 		}
@@ -59,6 +79,10 @@ var undeployCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(undeployCmd)
 
+	undeployCmd.Flags().Bool("dry-run", false, "Lists the resources that would be deleted without deleting them")
+	undeployCmd.Flags().Bool("keep-bucket", false, "Keeps the deployment bucket")
+	undeployCmd.Flags().Bool("keep-role", false, "Keeps the IAM role of the function")
+
 	// Here you will define your flags and configuration settings.
 
 	// Cobra supports Persistent Flags which will work for this command
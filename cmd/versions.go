@@ -0,0 +1,66 @@
+/*
+Copyright © 2023 Ekene Izukanne <ekeneizukanne@gmail.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/spatocode/jerm"
+	"github.com/spatocode/jerm/internal/log"
+)
+
+// versionsCmd represents the versions command
+var versionsCmd = &cobra.Command{
+	Use:   "versions",
+	Short: "Lists the published versions of the deployment",
+	Long:  "Lists the published versions of the deployment. The version serving the stage is marked with *",
+	Run: func(cmd *cobra.Command, args []string) {
+		jerm.Verbose(cmd)
+
+		cfg, err := jerm.Configure(jerm.DefaultConfigFile)
+		if err != nil {
+			log.PrintError(err)
+			return
+		}
+
+		p, err := jerm.New(cfg)
+		if err != nil {
+			log.PrintError(err)
+			return
+		}
+
+		platform, err := jerm.NewPlatform(cfg)
+		if err != nil {
+			log.PrintError(err)
+			return
+		}
+		p.SetPlatform(platform)
+
+		versions, err := p.Versions()
+		if err != nil {
+			log.PrintError(err)
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tCREATED\tSIZE\tCODE HASH\tDESCRIPTION")
+		for _, v := range versions {
+			version := v.Version
+			if v.Current {
+				version += " *"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%.1f MB\t%s\t%s\n", version, v.Created.Local().Format(time.DateTime), float64(v.Size)/1000000, v.CodeHash, v.Description)
+		}
+		w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(versionsCmd)
+}
@@ -0,0 +1,185 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	DefaultCacheClusterSize = "0.5"
+	DefaultCacheTtl         = 300
+	MaxCacheTtl             = 3600
+	LogLevelOff             = "OFF"
+	LogLevelError           = "ERROR"
+	LogLevelInfo            = "INFO"
+	MaxCompressionSize      = 10485760
+
+	// DefaultAccessLogFormat logs a JSON line per request
+	DefaultAccessLogFormat = `{"requestId":"$context.requestId","ip":"$context.identity.sourceIp","requestTime":"$context.requestTime","httpMethod":"$context.httpMethod","resourcePath":"$context.resourcePath","status":"$context.status","protocol":"$context.protocol","responseLength":"$context.responseLength"}`
+)
+
+// CacheClusterSizes are the sizes in GB of the cache cluster of a REST API stage
+var CacheClusterSizes = []string{"0.5", "1.6", "6.1", "13.5", "28.4", "58.2", "118", "237"}
+
+// DefaultCorsHeaders are the request headers allowed by CORS by default
+var DefaultCorsHeaders = []string{"Content-Type", "X-Amz-Date", "Authorization", "X-Api-Key", "X-Amz-Security-Token"}
+
+// CorsMethods are the HTTP methods CORS can allow
+var CorsMethods = []string{"DELETE", "GET", "HEAD", "OPTIONS", "PATCH", "POST", "PUT"}
+
+// ApiGateway is the configuration of the REST API of the function and its stage.
+// Settings left out keep the API Gateway defaults.
+type ApiGateway struct {
+	Cors                   *Cors    `json:"cors,omitempty"`
+	BinaryMediaTypes       []string `json:"binary_media_types,omitempty"`
+	MinimumCompressionSize *int     `json:"minimum_compression_size,omitempty"`
+
+	CacheCluster         bool       `json:"cache_cluster,omitempty"`
+	CacheClusterSize     string     `json:"cache_cluster_size,omitempty"`
+	CacheTtl             *int       `json:"cache_ttl,omitempty"`
+	LogLevel             string     `json:"log_level,omitempty"`
+	DataTrace            bool       `json:"data_trace,omitempty"`
+	DetailedMetrics      bool       `json:"detailed_metrics,omitempty"`
+	ThrottlingBurstLimit int        `json:"throttling_burst_limit,omitempty"`
+	ThrottlingRateLimit  float64    `json:"throttling_rate_limit,omitempty"`
+	AccessLog            *AccessLog `json:"access_log,omitempty"`
+}
+
+// AccessLog sends a line per request to a CloudWatch log group or Firehose stream
+type AccessLog struct {
+	Destination string `json:"destination"`
+	Format      string `json:"format,omitempty"`
+}
+
+// Cors answers the preflight requests of browsers to the REST API
+type Cors struct {
+	Origins     []string `json:"origins,omitempty"`
+	Headers     []string `json:"headers,omitempty"`
+	Methods     []string `json:"methods,omitempty"`
+	Credentials bool     `json:"credentials,omitempty"`
+}
+
+// GetApiGateway returns the validated REST API configuration with its defaults
+func (c *Config) GetApiGateway() (ApiGateway, error) {
+	settings := ApiGateway{}
+	if c.ApiGateway != nil {
+		settings = *c.ApiGateway
+	}
+
+	if settings.CacheClusterSize == "" {
+		settings.CacheClusterSize = DefaultCacheClusterSize
+	}
+	validSize := false
+	for _, size := range CacheClusterSizes {
+		validSize = validSize || size == settings.CacheClusterSize
+	}
+	if !validSize {
+		msg := fmt.Sprintf("invalid cache_cluster_size %s. Supported sizes are %s", settings.CacheClusterSize, strings.Join(CacheClusterSizes, ", "))
+		return ApiGateway{}, errors.New(msg)
+	}
+
+	ttl := DefaultCacheTtl
+	if settings.CacheTtl != nil {
+		ttl = *settings.CacheTtl
+	}
+	if ttl < 0 || ttl > MaxCacheTtl {
+		msg := fmt.Sprintf("invalid cache_ttl %d. Cache TTL must be between 0 and %d seconds", ttl, MaxCacheTtl)
+		return ApiGateway{}, errors.New(msg)
+	}
+	settings.CacheTtl = &ttl
+
+	settings.LogLevel = strings.ToUpper(settings.LogLevel)
+	switch settings.LogLevel {
+	case "":
+		settings.LogLevel = LogLevelOff
+	case LogLevelOff, LogLevelError, LogLevelInfo:
+	default:
+		msg := fmt.Sprintf("invalid log_level %s. Supported log levels are %s, %s and %s", settings.LogLevel, LogLevelOff, LogLevelError, LogLevelInfo)
+		return ApiGateway{}, errors.New(msg)
+	}
+
+	if settings.ThrottlingBurstLimit < 0 || settings.ThrottlingRateLimit < 0 {
+		return ApiGateway{}, errors.New("invalid throttling. Throttling limits must be positive")
+	}
+
+	if settings.AccessLog != nil {
+		accessLog := *settings.AccessLog
+		if !strings.HasPrefix(accessLog.Destination, "arn:") {
+			msg := fmt.Sprintf("invalid access_log destination %q. Destination must be the ARN of a log group or Firehose stream", accessLog.Destination)
+			return ApiGateway{}, errors.New(msg)
+		}
+		if accessLog.Format == "" {
+			accessLog.Format = DefaultAccessLogFormat
+		}
+		settings.AccessLog = &accessLog
+	}
+
+	for _, mediaType := range settings.BinaryMediaTypes {
+		if !strings.Contains(mediaType, "/") {
+			msg := fmt.Sprintf("invalid binary media type %q. Binary media types must be like image/png or */*", mediaType)
+			return ApiGateway{}, errors.New(msg)
+		}
+	}
+
+	if settings.MinimumCompressionSize != nil {
+		size := *settings.MinimumCompressionSize
+		if size < 0 || size > MaxCompressionSize {
+			msg := fmt.Sprintf("invalid minimum_compression_size %d. Minimum compression size must be between 0 and %d bytes", size, MaxCompressionSize)
+			return ApiGateway{}, errors.New(msg)
+		}
+	}
+
+	if settings.Cors != nil {
+		cors, err := getCors(*settings.Cors)
+		if err != nil {
+			return ApiGateway{}, err
+		}
+		settings.Cors = &cors
+	}
+	return settings, nil
+}
+
+// getCors returns the validated CORS configuration with its defaults
+func getCors(cors Cors) (Cors, error) {
+	if len(cors.Origins) == 0 {
+		cors.Origins = []string{"*"}
+	}
+	for _, origin := range cors.Origins {
+		if origin == "*" {
+			if len(cors.Origins) > 1 {
+				return Cors{}, errors.New("invalid cors origins. Origin * can't be allowed along with other origins")
+			}
+			if cors.Credentials {
+				return Cors{}, errors.New("invalid cors credentials. Credentials can't be allowed for origin *")
+			}
+			continue
+		}
+		if !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			msg := fmt.Sprintf("invalid cors origin %q. Origins must be * or like https://example.com", origin)
+			return Cors{}, errors.New(msg)
+		}
+	}
+
+	if len(cors.Headers) == 0 {
+		cors.Headers = DefaultCorsHeaders
+	}
+
+	if len(cors.Methods) == 0 {
+		cors.Methods = CorsMethods
+	}
+	methods := make([]string, len(cors.Methods))
+	for i, method := range cors.Methods {
+		methods[i] = strings.ToUpper(method)
+		validMethod := false
+		for _, m := range CorsMethods {
+			validMethod = validMethod || m == methods[i]
+		}
+		if !validMethod {
+			msg := fmt.Sprintf("invalid cors method %s. Supported methods are %s", method, strings.Join(CorsMethods, ", "))
+			return Cors{}, errors.New(msg)
+		}
+	}
+	cors.Methods = methods
+	return cors, nil
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigGetApiGateway(t *testing.T) {
+	assert := assert.New(t)
+	cfg := &Config{}
+	settings, err := cfg.GetApiGateway()
+	assert.Nil(err)
+	assert.False(settings.CacheCluster)
+	assert.Equal(DefaultCacheClusterSize, settings.CacheClusterSize)
+	assert.Equal(DefaultCacheTtl, *settings.CacheTtl)
+	assert.Equal(LogLevelOff, settings.LogLevel)
+	assert.Nil(settings.AccessLog)
+
+	ttl := 0
+	cfg.ApiGateway = &ApiGateway{
+		CacheCluster:     true,
+		CacheClusterSize: "6.1",
+		CacheTtl:         &ttl,
+		LogLevel:         "info",
+		AccessLog:        &AccessLog{Destination: "arn:aws:logs:us-west-1:123456789012:log-group:access"},
+	}
+	settings, err = cfg.GetApiGateway()
+	assert.Nil(err)
+	assert.Equal("6.1", settings.CacheClusterSize)
+	assert.Equal(0, *settings.CacheTtl)
+	assert.Equal(LogLevelInfo, settings.LogLevel)
+	assert.Equal(DefaultAccessLogFormat, settings.AccessLog.Format)
+	assert.Equal("", cfg.ApiGateway.AccessLog.Format)
+
+	cfg.ApiGateway = &ApiGateway{CacheClusterSize: "2"}
+	_, err = cfg.GetApiGateway()
+	assert.EqualError(err, "invalid cache_cluster_size 2. Supported sizes are 0.5, 1.6, 6.1, 13.5, 28.4, 58.2, 118, 237")
+
+	ttl = 3601
+	cfg.ApiGateway = &ApiGateway{CacheTtl: &ttl}
+	_, err = cfg.GetApiGateway()
+	assert.EqualError(err, "invalid cache_ttl 3601. Cache TTL must be between 0 and 3600 seconds")
+
+	cfg.ApiGateway = &ApiGateway{LogLevel: "debug"}
+	_, err = cfg.GetApiGateway()
+	assert.EqualError(err, "invalid log_level DEBUG. Supported log levels are OFF, ERROR and INFO")
+
+	cfg.ApiGateway = &ApiGateway{ThrottlingRateLimit: -1}
+	_, err = cfg.GetApiGateway()
+	assert.EqualError(err, "invalid throttling. Throttling limits must be positive")
+
+	cfg.ApiGateway = &ApiGateway{AccessLog: &AccessLog{Destination: "access"}}
+	_, err = cfg.GetApiGateway()
+	assert.EqualError(err, `invalid access_log destination "access". Destination must be the ARN of a log group or Firehose stream`)
+}
+
+func TestConfigGetApiGatewayCors(t *testing.T) {
+	assert := assert.New(t)
+	size := 1024
+	cfg := &Config{ApiGateway: &ApiGateway{
+		Cors:                   &Cors{},
+		BinaryMediaTypes:       []string{"image/png", "*/*"},
+		MinimumCompressionSize: &size,
+	}}
+	settings, err := cfg.GetApiGateway()
+	assert.Nil(err)
+	assert.Equal([]string{"*"}, settings.Cors.Origins)
+	assert.Equal(DefaultCorsHeaders, settings.Cors.Headers)
+	assert.Equal(CorsMethods, settings.Cors.Methods)
+	assert.Nil(cfg.ApiGateway.Cors.Origins)
+
+	cfg.ApiGateway.Cors = &Cors{Origins: []string{"https://example.com"}, Methods: []string{"get", "post"}, Credentials: true}
+	settings, err = cfg.GetApiGateway()
+	assert.Nil(err)
+	assert.Equal([]string{"GET", "POST"}, settings.Cors.Methods)
+	assert.Equal([]string{"get", "post"}, cfg.ApiGateway.Cors.Methods)
+
+	size = MaxCompressionSize + 1
+	cases := []struct {
+		settings *ApiGateway
+		err      string
+	}{
+		{&ApiGateway{BinaryMediaTypes: []string{"png"}}, `invalid binary media type "png". Binary media types must be like image/png or */*`},
+		{&ApiGateway{MinimumCompressionSize: &size}, "invalid minimum_compression_size 10485761. Minimum compression size must be between 0 and 10485760 bytes"},
+		{&ApiGateway{Cors: &Cors{Origins: []string{"*", "https://example.com"}}}, "invalid cors origins. Origin * can't be allowed along with other origins"},
+		{&ApiGateway{Cors: &Cors{Credentials: true}}, "invalid cors credentials. Credentials can't be allowed for origin *"},
+		{&ApiGateway{Cors: &Cors{Origins: []string{"example.com"}}}, `invalid cors origin "example.com". Origins must be * or like https://example.com`},
+		{&ApiGateway{Cors: &Cors{Methods: []string{"TRACE"}}}, "invalid cors method TRACE. Supported methods are DELETE, GET, HEAD, OPTIONS, PATCH, POST, PUT"},
+	}
+	for _, tt := range cases {
+		cfg.ApiGateway = tt.settings
+		_, err = cfg.GetApiGateway()
+		assert.EqualError(err, tt.err)
+	}
+}
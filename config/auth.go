@@ -0,0 +1,143 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	AuthIam     = "iam"
+	AuthCognito = "cognito"
+	AuthLambda  = "lambda"
+
+	AuthorizerToken   = "token"
+	AuthorizerRequest = "request"
+
+	DefaultIdentitySource      = "method.request.header.Authorization"
+	DefaultAuthorizerResultTtl = 300
+	MaxAuthorizerResultTtl     = 3600
+)
+
+// QuotaPeriods are the periods of the request quota of API keys
+var QuotaPeriods = []string{"DAY", "WEEK", "MONTH"}
+
+// Auth is the authorization of the requests to the REST API of the function.
+// API keys can be required along with an authorizer.
+type Auth struct {
+	// Type is iam, cognito or lambda. Requests aren't authorized when it's empty.
+	Type    string             `json:"type,omitempty"`
+	ApiKeys *ApiKeys           `json:"api_keys,omitempty"`
+	Cognito *CognitoAuthorizer `json:"cognito,omitempty"`
+	Lambda  *LambdaAuthorizer  `json:"lambda,omitempty"`
+}
+
+// ApiKeys requires an API key on every request and limits the requests of each key
+type ApiKeys struct {
+	Quota      *Quota  `json:"quota,omitempty"`
+	BurstLimit int     `json:"burst_limit,omitempty"`
+	RateLimit  float64 `json:"rate_limit,omitempty"`
+}
+
+// Quota is the maximum number of requests of an API key in a period
+type Quota struct {
+	Limit  int    `json:"limit"`
+	Period string `json:"period"`
+}
+
+// CognitoAuthorizer authorizes requests with the tokens of Cognito user pools
+type CognitoAuthorizer struct {
+	UserPools      []string `json:"user_pools"`
+	IdentitySource string   `json:"identity_source,omitempty"`
+}
+
+// LambdaAuthorizer authorizes requests with a function. Function is the ARN of
+// another function and Handler is a handler in the package of the function.
+type LambdaAuthorizer struct {
+	Type           string `json:"type,omitempty"`
+	Function       string `json:"function,omitempty"`
+	Handler        string `json:"handler,omitempty"`
+	IdentitySource string `json:"identity_source,omitempty"`
+	ResultTtl      *int   `json:"result_ttl,omitempty"`
+}
+
+// GetAuth returns the validated authorization of the REST API with its defaults
+func (c *Config) GetAuth() (Auth, error) {
+	if c.Auth == nil {
+		return Auth{}, nil
+	}
+	auth := *c.Auth
+
+	if auth.ApiKeys != nil {
+		apiKeys := *auth.ApiKeys
+		if apiKeys.BurstLimit < 0 || apiKeys.RateLimit < 0 {
+			return Auth{}, errors.New("invalid api_keys throttling. Throttling limits must be positive")
+		}
+		if apiKeys.Quota != nil {
+			quota := *apiKeys.Quota
+			quota.Period = strings.ToUpper(quota.Period)
+			validPeriod := false
+			for _, period := range QuotaPeriods {
+				validPeriod = validPeriod || period == quota.Period
+			}
+			if !validPeriod {
+				msg := fmt.Sprintf("invalid api_keys quota period %s. Supported periods are %s", apiKeys.Quota.Period, strings.Join(QuotaPeriods, ", "))
+				return Auth{}, errors.New(msg)
+			}
+			if quota.Limit <= 0 {
+				msg := fmt.Sprintf("invalid api_keys quota limit %d. Quota limit must be positive", quota.Limit)
+				return Auth{}, errors.New(msg)
+			}
+			apiKeys.Quota = &quota
+		}
+		auth.ApiKeys = &apiKeys
+	}
+
+	switch auth.Type {
+	case "", AuthIam:
+	case AuthCognito:
+		if auth.Cognito == nil || len(auth.Cognito.UserPools) == 0 {
+			return Auth{}, errors.New("cognito auth requires the ARNs of its user_pools")
+		}
+		cognito := *auth.Cognito
+		if cognito.IdentitySource == "" {
+			cognito.IdentitySource = DefaultIdentitySource
+		}
+		auth.Cognito = &cognito
+	case AuthLambda:
+		if auth.Lambda == nil || (auth.Lambda.Function == "") == (auth.Lambda.Handler == "") {
+			return Auth{}, errors.New("lambda auth requires either a function or a handler")
+		}
+		authorizer := *auth.Lambda
+		if authorizer.Function != "" && !strings.HasPrefix(authorizer.Function, "arn:") {
+			msg := fmt.Sprintf("invalid lambda authorizer function %q. Function must be the ARN of a Lambda function", authorizer.Function)
+			return Auth{}, errors.New(msg)
+		}
+		authorizer.Type = strings.ToLower(authorizer.Type)
+		switch authorizer.Type {
+		case "":
+			authorizer.Type = AuthorizerToken
+		case AuthorizerToken, AuthorizerRequest:
+		default:
+			msg := fmt.Sprintf("invalid lambda authorizer type %s. Supported types are %s and %s", authorizer.Type, AuthorizerToken, AuthorizerRequest)
+			return Auth{}, errors.New(msg)
+		}
+		if authorizer.IdentitySource == "" {
+			authorizer.IdentitySource = DefaultIdentitySource
+		}
+		ttl := DefaultAuthorizerResultTtl
+		if authorizer.ResultTtl != nil {
+			ttl = *authorizer.ResultTtl
+		}
+		if ttl < 0 || ttl > MaxAuthorizerResultTtl {
+			msg := fmt.Sprintf("invalid lambda authorizer result_ttl %d. Result TTL must be between 0 and %d seconds", ttl, MaxAuthorizerResultTtl)
+			return Auth{}, errors.New(msg)
+		}
+		authorizer.ResultTtl = &ttl
+		auth.Lambda = &authorizer
+	default:
+		msg := fmt.Sprintf("invalid auth type %s. Supported auth types are %s, %s and %s", auth.Type, AuthIam, AuthCognito, AuthLambda)
+		return Auth{}, errors.New(msg)
+	}
+	return auth, nil
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigGetAuth(t *testing.T) {
+	assert := assert.New(t)
+	cfg := &Config{}
+	auth, err := cfg.GetAuth()
+	assert.Nil(err)
+	assert.Equal(Auth{}, auth)
+
+	cfg.Auth = &Auth{
+		Type:    AuthLambda,
+		ApiKeys: &ApiKeys{Quota: &Quota{Limit: 1000, Period: "month"}, RateLimit: 10},
+		Lambda:  &LambdaAuthorizer{Handler: "auth.handler"},
+	}
+	auth, err = cfg.GetAuth()
+	assert.Nil(err)
+	assert.Equal("MONTH", auth.ApiKeys.Quota.Period)
+	assert.Equal("month", cfg.Auth.ApiKeys.Quota.Period)
+	assert.Equal(AuthorizerToken, auth.Lambda.Type)
+	assert.Equal(DefaultIdentitySource, auth.Lambda.IdentitySource)
+	assert.Equal(DefaultAuthorizerResultTtl, *auth.Lambda.ResultTtl)
+
+	cfg.Auth = &Auth{Type: AuthCognito, Cognito: &CognitoAuthorizer{UserPools: []string{"arn:aws:cognito-idp:us-west-1:123456789012:userpool/pool"}}}
+	auth, err = cfg.GetAuth()
+	assert.Nil(err)
+	assert.Equal(DefaultIdentitySource, auth.Cognito.IdentitySource)
+
+	cases := []struct {
+		auth *Auth
+		err  string
+	}{
+		{&Auth{Type: "jwt"}, "invalid auth type jwt. Supported auth types are iam, cognito and lambda"},
+		{&Auth{Type: AuthCognito}, "cognito auth requires the ARNs of its user_pools"},
+		{&Auth{Type: AuthLambda, Lambda: &LambdaAuthorizer{}}, "lambda auth requires either a function or a handler"},
+		{&Auth{Type: AuthLambda, Lambda: &LambdaAuthorizer{Function: "auth"}}, `invalid lambda authorizer function "auth". Function must be the ARN of a Lambda function`},
+		{&Auth{Type: AuthLambda, Lambda: &LambdaAuthorizer{Handler: "auth.handler", Type: "jwt"}}, "invalid lambda authorizer type jwt. Supported types are token and request"},
+		{&Auth{ApiKeys: &ApiKeys{Quota: &Quota{Limit: 10, Period: "year"}}}, "invalid api_keys quota period year. Supported periods are DAY, WEEK, MONTH"},
+		{&Auth{ApiKeys: &ApiKeys{Quota: &Quota{Period: "DAY"}}}, "invalid api_keys quota limit 0. Quota limit must be positive"},
+		{&Auth{ApiKeys: &ApiKeys{BurstLimit: -1}}, "invalid api_keys throttling. Throttling limits must be positive"},
+	}
+	for _, tt := range cases {
+		cfg.Auth = tt.auth
+		_, err = cfg.GetAuth()
+		assert.EqualError(err, tt.err)
+	}
+}
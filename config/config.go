@@ -35,6 +35,21 @@ type Config struct {
 	Region   string   `json:"region"`
 	Platform Platform `json:"platform"`
 	Dir      string   `json:"dir"`
+
+	Environment  map[string]string      `json:"environment,omitempty"`
+	EnvFile      string                 `json:"env_file,omitempty"`
+	Stages       map[string]StageConfig `json:"stages,omitempty"`
+	SecretsMode  string                 `json:"secrets_mode,omitempty"`
+	Events       []Event                `json:"events,omitempty"`
+	EventSources []EventSource          `json:"event_sources,omitempty"`
+	Triggers     *Triggers              `json:"triggers,omitempty"`
+	Retention    *Retention             `json:"retention,omitempty"`
+	Tags         map[string]string      `json:"tags,omitempty"`
+	ApiGateway   *ApiGateway            `json:"api_gateway,omitempty"`
+	Domain       *Domain                `json:"domain,omitempty"`
+	Auth         *Auth                  `json:"auth,omitempty"`
+	Routes       map[string]Route       `json:"routes,omitempty"`
+	WebSocket    *WebSocket             `json:"websocket,omitempty"`
 }
 
 func (c *Config) GetFunctionName() string {
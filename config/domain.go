@@ -0,0 +1,44 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Domain is the custom domain of the API of the function
+type Domain struct {
+	// Name is the domain name, such as api.example.com
+	Name string `json:"name"`
+
+	// Certificate is the ARN of the ACM certificate of the domain.
+	// A certificate is requested with DNS validation when it's empty.
+	Certificate string `json:"certificate,omitempty"`
+
+	// HostedZone is the ID of the Route 53 hosted zone of the domain.
+	// It's looked up from the domain name when it's empty.
+	HostedZone string `json:"hosted_zone,omitempty"`
+
+	// BasePath is the path the API is served at. It defaults to the root.
+	BasePath string `json:"base_path,omitempty"`
+}
+
+// GetDomain returns the validated custom domain of the function
+func (c *Config) GetDomain() (Domain, error) {
+	if c.Domain == nil {
+		return Domain{}, errors.New("no domain configured. Set domain in your jerm.json file")
+	}
+	domain := *c.Domain
+	domain.Name = strings.TrimSuffix(strings.ToLower(domain.Name), ".")
+	if domain.Name == "" || !strings.Contains(domain.Name, ".") {
+		msg := fmt.Sprintf("invalid domain name %q", c.Domain.Name)
+		return Domain{}, errors.New(msg)
+	}
+	if domain.Certificate != "" && !strings.HasPrefix(domain.Certificate, "arn:") {
+		msg := fmt.Sprintf("invalid domain certificate %q. Certificate must be the ARN of an ACM certificate", domain.Certificate)
+		return Domain{}, errors.New(msg)
+	}
+	domain.HostedZone = strings.TrimPrefix(domain.HostedZone, "/hostedzone/")
+	domain.BasePath = strings.Trim(domain.BasePath, "/")
+	return domain, nil
+}
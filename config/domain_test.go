@@ -0,0 +1,27 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigGetDomain(t *testing.T) {
+	assert := assert.New(t)
+	cfg := &Config{}
+	_, err := cfg.GetDomain()
+	assert.EqualError(err, "no domain configured. Set domain in your jerm.json file")
+
+	cfg.Domain = &Domain{Name: "API.example.com.", HostedZone: "/hostedzone/Z123", BasePath: "/v1/"}
+	domain, err := cfg.GetDomain()
+	assert.Nil(err)
+	assert.Equal(Domain{Name: "api.example.com", HostedZone: "Z123", BasePath: "v1"}, domain)
+
+	cfg.Domain = &Domain{Name: "localhost"}
+	_, err = cfg.GetDomain()
+	assert.EqualError(err, `invalid domain name "localhost"`)
+
+	cfg.Domain = &Domain{Name: "api.example.com", Certificate: "certificate"}
+	_, err = cfg.GetDomain()
+	assert.EqualError(err, `invalid domain certificate "certificate". Certificate must be the ARN of an ACM certificate`)
+}
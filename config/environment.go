@@ -0,0 +1,118 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var envReference = regexp.MustCompile(`\$\{env:([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// StageConfig is the configuration overrides of a deployment stage
+type StageConfig struct {
+	Environment map[string]string `json:"environment,omitempty"`
+}
+
+// GetEnvironment returns the environment variables of the function.
+// Variables of the env file are overridden by the environment of the config,
+// which in turn is overridden by the environment of the current stage.
+func (c *Config) GetEnvironment() (map[string]string, error) {
+	env := map[string]string{}
+
+	if c.EnvFile != "" {
+		vars, err := readEnvFile(c.envFilePath())
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range vars {
+			env[k] = v
+		}
+	}
+
+	for k, v := range c.Environment {
+		env[k] = v
+	}
+
+	if stage, ok := c.Stages[c.Stage]; ok {
+		for k, v := range stage.Environment {
+			env[k] = v
+		}
+	}
+
+	for k, v := range env {
+		value, err := interpolateEnv(v)
+		if err != nil {
+			return nil, fmt.Errorf("environment variable %s: %s", k, err)
+		}
+		env[k] = value
+	}
+
+	return env, nil
+}
+
+// envFilePath resolves the env file relative to the project directory
+func (c *Config) envFilePath() string {
+	if filepath.IsAbs(c.EnvFile) || c.Dir == "" {
+		return c.EnvFile
+	}
+	return filepath.Join(c.Dir, c.EnvFile)
+}
+
+// interpolateEnv replaces ${env:VAR} references with the value of VAR
+// in the environment of the deploying shell
+func interpolateEnv(value string) (string, error) {
+	var err error
+	result := envReference.ReplaceAllStringFunc(value, func(ref string) string {
+		name := envReference.FindStringSubmatch(ref)[1]
+		v, ok := os.LookupEnv(name)
+		if !ok && err == nil {
+			err = fmt.Errorf("%s is not set in the environment", name)
+		}
+		return v
+	})
+	if err != nil {
+		return "", err
+	}
+	return result, nil
+}
+
+// readEnvFile reads KEY=VALUE pairs from a dotenv file
+func readEnvFile(file string) (map[string]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	env := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, found := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid line %d in %s", lineNumber, file)
+		}
+
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		env[key] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigGetEnvironment(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	dotenv := "# comment\nexport DEBUG=true\nDB_HOST=\"localhost\"\n\nLOG_LEVEL='info'\n"
+	err := os.WriteFile(filepath.Join(dir, ".env"), []byte(dotenv), 0644)
+	assert.Nil(err)
+	t.Setenv("JERM_TEST_SECRET", "s3cr3t")
+
+	cfg := &Config{
+		Stage:   "production",
+		Dir:     dir,
+		EnvFile: ".env",
+		Environment: map[string]string{
+			"DEBUG":  "false",
+			"SECRET": "${env:JERM_TEST_SECRET}",
+		},
+		Stages: map[string]StageConfig{
+			"production": {Environment: map[string]string{"LOG_LEVEL": "error"}},
+			"dev":        {Environment: map[string]string{"LOG_LEVEL": "debug"}},
+		},
+	}
+	env, err := cfg.GetEnvironment()
+	assert.Nil(err)
+	assert.Equal(map[string]string{
+		"DEBUG":     "false",
+		"DB_HOST":   "localhost",
+		"LOG_LEVEL": "error",
+		"SECRET":    "s3cr3t",
+	}, env)
+
+	cfg.Environment["MISSING"] = "${env:JERM_TEST_MISSING}"
+	_, err = cfg.GetEnvironment()
+	assert.EqualError(err, "environment variable MISSING: JERM_TEST_MISSING is not set in the environment")
+
+	cfg = &Config{Dir: dir, EnvFile: "missing.env"}
+	_, err = cfg.GetEnvironment()
+	assert.NotNil(err)
+}
+
+func TestReadEnvFile(t *testing.T) {
+	assert := assert.New(t)
+	file := filepath.Join(t.TempDir(), ".env")
+	err := os.WriteFile(file, []byte("INVALID\n"), 0644)
+	assert.Nil(err)
+
+	_, err = readEnvFile(file)
+	assert.EqualError(err, "invalid line 1 in "+file)
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	KeepWarmEvent    = "keep-warm"
+	KeepWarmSchedule = "rate(5 minutes)"
+	KeepWarmPayload  = `{"jerm_keep_warm": true}`
+)
+
+var eventName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Event is a scheduled event that invokes the function
+type Event struct {
+	Name     string          `json:"name"`
+	Schedule string          `json:"schedule"`
+	Payload  json.RawMessage `json:"payload,omitempty"`
+}
+
+// GetEvents returns the validated scheduled events of the function,
+// including the keep warm event if it's enabled.
+func (c *Config) GetEvents() ([]Event, error) {
+	seen := map[string]bool{}
+	var events []Event
+	for _, event := range c.Events {
+		if !eventName.MatchString(event.Name) {
+			return nil, fmt.Errorf("invalid event name %q. Event names may contain letters, numbers, - and _", event.Name)
+		}
+		if event.Name == KeepWarmEvent {
+			return nil, fmt.Errorf("event name %s is reserved. Use platform.keep_warm instead", KeepWarmEvent)
+		}
+		if seen[event.Name] {
+			return nil, fmt.Errorf("duplicate event %s", event.Name)
+		}
+		if !strings.HasPrefix(event.Schedule, "cron(") && !strings.HasPrefix(event.Schedule, "rate(") {
+			return nil, fmt.Errorf("invalid schedule %q of event %s. Use a cron(...) or rate(...) expression", event.Schedule, event.Name)
+		}
+		seen[event.Name] = true
+		events = append(events, event)
+	}
+
+	if c.Platform.KeepWarm {
+		events = append(events, Event{
+			Name:     KeepWarmEvent,
+			Schedule: KeepWarmSchedule,
+			Payload:  json.RawMessage(KeepWarmPayload),
+		})
+	}
+	return events, nil
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigGetEvents(t *testing.T) {
+	assert := assert.New(t)
+	cfg := &Config{Events: []Event{
+		{Name: "report", Schedule: "cron(0 8 * * ? *)", Payload: json.RawMessage(`{"task": "report"}`)},
+		{Name: "cleanup", Schedule: "rate(1 hour)"},
+	}}
+
+	events, err := cfg.GetEvents()
+	assert.Nil(err)
+	assert.Equal(cfg.Events, events)
+
+	cfg.Platform.KeepWarm = true
+	events, err = cfg.GetEvents()
+	assert.Nil(err)
+	assert.Len(events, 3)
+	assert.Equal(KeepWarmEvent, events[2].Name)
+	assert.Equal(KeepWarmSchedule, events[2].Schedule)
+	assert.JSONEq(KeepWarmPayload, string(events[2].Payload))
+
+	cfg.Events = []Event{{Name: "report", Schedule: "every day"}}
+	_, err = cfg.GetEvents()
+	assert.EqualError(err, `invalid schedule "every day" of event report. Use a cron(...) or rate(...) expression`)
+
+	cfg.Events = []Event{{Name: "report", Schedule: "rate(1 day)"}, {Name: "report", Schedule: "rate(2 days)"}}
+	_, err = cfg.GetEvents()
+	assert.EqualError(err, "duplicate event report")
+
+	cfg.Events = []Event{{Name: "daily report", Schedule: "rate(1 day)"}}
+	_, err = cfg.GetEvents()
+	assert.EqualError(err, `invalid event name "daily report". Event names may contain letters, numbers, - and _`)
+
+	cfg.Events = []Event{{Name: KeepWarmEvent, Schedule: "rate(1 day)"}}
+	_, err = cfg.GetEvents()
+	assert.EqualError(err, "event name keep-warm is reserved. Use platform.keep_warm instead")
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	StartingPositionLatest      = "LATEST"
+	StartingPositionTrimHorizon = "TRIM_HORIZON"
+)
+
+// EventSource is a queue or stream whose records invoke the function
+type EventSource struct {
+	Arn              string            `json:"arn"`
+	Function         string            `json:"function,omitempty"`
+	BatchSize        int               `json:"batch_size,omitempty"`
+	StartingPosition string            `json:"starting_position,omitempty"`
+	Enabled          *bool             `json:"enabled,omitempty"`
+	Filters          []json.RawMessage `json:"filters,omitempty"`
+}
+
+// IsEnabled checks if the event source invokes the function. It defaults to true.
+func (e EventSource) IsEnabled() bool {
+	return e.Enabled == nil || *e.Enabled
+}
+
+// IsStream checks if the event source is a Kinesis or DynamoDB stream
+func (e EventSource) IsStream() bool {
+	// ARNs are in the form arn:<partition>:<service>:<region>:<account>:<resource>
+	parts := strings.SplitN(e.Arn, ":", 6)
+	if len(parts) < 6 {
+		return false
+	}
+	return parts[2] == "kinesis" || parts[2] == "dynamodb"
+}
+
+// GetEventSources returns the validated event sources of the function
+func (c *Config) GetEventSources() ([]EventSource, error) {
+	seen := map[string]bool{}
+	for _, source := range c.EventSources {
+		if !strings.HasPrefix(source.Arn, "arn:") {
+			return nil, fmt.Errorf("invalid event source arn %q", source.Arn)
+		}
+		if seen[source.Arn] {
+			return nil, fmt.Errorf("duplicate event source %s", source.Arn)
+		}
+		seen[source.Arn] = true
+
+		switch source.StartingPosition {
+		case "", StartingPositionLatest, StartingPositionTrimHorizon:
+		default:
+			return nil, fmt.Errorf("invalid starting_position %s. Supported starting positions are %s and %s", source.StartingPosition, StartingPositionLatest, StartingPositionTrimHorizon)
+		}
+	}
+	return c.EventSources, nil
+}
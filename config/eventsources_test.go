@@ -0,0 +1,40 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigGetEventSources(t *testing.T) {
+	assert := assert.New(t)
+	disabled := false
+	cfg := &Config{EventSources: []EventSource{
+		{Arn: "arn:aws:sqs:us-west-2:123456789012:orders", BatchSize: 10},
+		{Arn: "arn:aws:kinesis:us-west-2:123456789012:stream/clicks", StartingPosition: StartingPositionTrimHorizon, Enabled: &disabled},
+	}}
+
+	sources, err := cfg.GetEventSources()
+	assert.Nil(err)
+	assert.Len(sources, 2)
+	assert.True(sources[0].IsEnabled())
+	assert.False(sources[0].IsStream())
+	assert.False(sources[1].IsEnabled())
+	assert.True(sources[1].IsStream())
+	assert.True(EventSource{Arn: "arn:aws:dynamodb:us-west-2:123456789012:table/users/stream/2023-01-01T00:00:00.000"}.IsStream())
+	assert.True(EventSource{Arn: "arn:aws-cn:kinesis:cn-north-1:123456789012:stream/clicks"}.IsStream())
+	assert.True(EventSource{Arn: "arn:aws-us-gov:kinesis:us-gov-west-1:123456789012:stream/clicks"}.IsStream())
+	assert.False(EventSource{Arn: "arn:aws:sqs:us-west-2:123456789012:kinesis"}.IsStream())
+
+	cfg.EventSources = append(cfg.EventSources, EventSource{Arn: "arn:aws:sqs:us-west-2:123456789012:orders"})
+	_, err = cfg.GetEventSources()
+	assert.EqualError(err, "duplicate event source arn:aws:sqs:us-west-2:123456789012:orders")
+
+	cfg.EventSources = []EventSource{{Arn: "orders"}}
+	_, err = cfg.GetEventSources()
+	assert.EqualError(err, `invalid event source arn "orders"`)
+
+	cfg.EventSources = []EventSource{{Arn: "arn:aws:kinesis:us-west-2:123456789012:stream/clicks", StartingPosition: "AT_TIMESTAMP"}}
+	_, err = cfg.GetEventSources()
+	assert.EqualError(err, "invalid starting_position AT_TIMESTAMP. Supported starting positions are LATEST and TRIM_HORIZON")
+}
@@ -0,0 +1,33 @@
+package handlers
+
+const (
+	AzureFunctionsHandlerStaticPage = `
+const fs = require('fs');
+const path = require('path');
+const html = fs.readFileSync(path.join(__dirname, 'index.html'), { encoding:'utf8' });
+
+exports.handler = async (context, req) => ({
+	headers: { 'Content-Type': 'text/html' },
+	body: html,
+});
+	`
+
+	AzureFunctionsHandlerDjango = `
+import io
+
+import azure.functions as func
+from django.core import management
+
+from .wsgi import application
+
+
+def handler(req: func.HttpRequest, context: func.Context) -> func.HttpResponse:
+    return func.WsgiMiddleware(application).handle(req, context)
+
+
+def manage(req: func.HttpRequest) -> func.HttpResponse:
+    output = io.StringIO()
+    management.call_command(*req.get_json()["manage"].split(" "), stdout=output)
+    return func.HttpResponse(output.getvalue())
+	`
+)
@@ -0,0 +1,32 @@
+package handlers
+
+const (
+	GoogleCloudFunctionsHandlerStaticPage = `
+const fs = require('fs');
+const path = require('path');
+const html = fs.readFileSync(path.join(__dirname, 'index.html'), { encoding:'utf8' });
+
+exports.handler = (req, res) => {
+	res.set('Content-Type', 'text/html').send(html);
+};
+	`
+
+	GoogleCloudFunctionsHandlerDjango = `
+import io
+
+from flask import Response
+from django.core import management
+
+from .wsgi import application
+
+
+def handler(request):
+    payload = request.get_json(silent=True) if request.is_json else None
+    if isinstance(payload, dict) and payload.get("manage"):
+        output = io.StringIO()
+        management.call_command(*payload["manage"].split(" "), stdout=output)
+        return {"output": output.getvalue()}
+
+    return Response.from_app(application, request.environ)
+	`
+)
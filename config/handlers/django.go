@@ -2,28 +2,190 @@ package handlers
 
 const (
 	AwsLambdaHandlerDjango = `
+import os
 import sys
 import json
 import io
 import logging
+import importlib
 import traceback
+from urllib.parse import parse_qs
 
 from wsgi_adapter import LambdaWSGIHandler
 from django.core import management
 from django.conf import settings
 
-from .wsgi import application
-
 
 logging.basicConfig()
 logger = logging.getLogger()
 logger.setLevel(logging.INFO)
 
 
+def resolve_secrets():
+    """Replaces the ssm: and secretsmanager: references of the environment with the values of the secrets"""
+    clients = {}
+    for name, value in list(os.environ.items()):
+        store, _, secret = value.partition(":")
+        if not secret or store not in ("ssm", "secretsmanager"):
+            continue
+        if store not in clients:
+            import boto3
+            clients[store] = boto3.client(store)
+        if store == "ssm":
+            os.environ[name] = clients[store].get_parameter(Name=secret, WithDecryption=True)["Parameter"]["Value"]
+        else:
+            os.environ[name] = clients[store].get_secret_value(SecretId=secret)["SecretString"]
+
+
+# secrets are resolved at cold start before the settings read the environment
+resolve_secrets()
+
+from .wsgi import application
+
+
+def import_function(path):
+    module_name, function_name = path.rsplit(".", 1)
+    module = importlib.import_module(module_name)
+    return getattr(module, function_name)
+
+
+def route_authorizer(event):
+    authorizer = os.environ.get("JERM_AUTHORIZER")
+    if not authorizer or not event.get("methodArn") or event.get("type") not in ("TOKEN", "REQUEST"):
+        return None
+    return import_function(authorizer)
+
+
+def route_event_source(event):
+    records = event.get("Records") or []
+    if not records:
+        return None
+
+    record = records[0]
+    source_arn = (
+        record.get("eventSourceARN")
+        or record.get("Sns", {}).get("TopicArn")
+        or record.get("s3", {}).get("bucket", {}).get("arn")
+    )
+    routes = json.loads(os.environ.get("JERM_EVENT_SOURCES", "{}"))
+    function = routes.get(source_arn)
+    if not function:
+        return None
+    return import_function(function)
+
+
+def accept_websocket(event, context):
+    return {"statusCode": 200}
+
+
+def route_websocket(event):
+    request_context = event.get("requestContext") or {}
+    if request_context.get("eventType") not in ("CONNECT", "MESSAGE", "DISCONNECT"):
+        return None
+    handler = os.environ.get("JERM_WEBSOCKET")
+    if not handler:
+        return accept_websocket
+    return import_function(handler)
+
+
+def post_to_connection(event, data, connection_id=None):
+    """Sends data to a connection of the WebSocket API of the event. It defaults to the connection of the event"""
+    import boto3
+
+    request_context = event["requestContext"]
+    client = boto3.client(
+        "apigatewaymanagementapi",
+        endpoint_url="https://{}/{}".format(request_context["domainName"], request_context["stage"]),
+    )
+    if not isinstance(data, (str, bytes)):
+        data = json.dumps(data)
+    client.post_to_connection(ConnectionId=connection_id or request_context["connectionId"], Data=data)
+
+
+def is_http_v2(event):
+    return event.get("version") == "2.0" and "http" in event.get("requestContext", {})
+
+
+def from_http_v2(event):
+    """Converts an HTTP API or Function URL event of payload format 2.0 to the REST API format"""
+    http = event["requestContext"]["http"]
+    headers = dict(event.get("headers") or {})
+    if event.get("cookies"):
+        headers["cookie"] = "; ".join(event["cookies"])
+    query = parse_qs(event.get("rawQueryString", ""), keep_blank_values=True)
+    return {
+        "httpMethod": http["method"],
+        "path": event.get("rawPath") or http["path"],
+        "headers": headers,
+        "multiValueHeaders": {name: [value] for name, value in headers.items()},
+        "queryStringParameters": {name: values[-1] for name, values in query.items()} or None,
+        "multiValueQueryStringParameters": query or None,
+        "pathParameters": event.get("pathParameters"),
+        "stageVariables": event.get("stageVariables"),
+        "requestContext": event["requestContext"],
+        "body": event.get("body"),
+        "isBase64Encoded": event.get("isBase64Encoded", False),
+    }
+
+
+def to_http_v2(response):
+    """Converts a REST API response to payload format 2.0, which has no multi value headers"""
+    headers = {}
+    cookies = []
+    multi_value_headers = dict(response.pop("multiValueHeaders", None) or {})
+    for name, value in (response.get("headers") or {}).items():
+        multi_value_headers.setdefault(name, [value])
+    for name, values in multi_value_headers.items():
+        if name.lower() == "set-cookie":
+            cookies.extend(values)
+        else:
+            headers[name] = ",".join(values)
+    response["headers"] = headers
+    if cookies:
+        response["cookies"] = cookies
+    return response
+
+
+def add_cors_headers(event, response):
+    """Allows the origin of the request in the response unless the application already does"""
+    cors = os.environ.get("JERM_CORS")
+    if not cors or not isinstance(response, dict):
+        return response
+
+    cors = json.loads(cors)
+    request_headers = {name.lower(): value for name, value in (event.get("headers") or {}).items()}
+    origin = request_headers.get("origin")
+    if "*" in cors["origins"]:
+        allowed = "*"
+    elif origin in cors["origins"]:
+        allowed = origin
+    else:
+        return response
+
+    names = list(response.get("headers") or {}) + list(response.get("multiValueHeaders") or {})
+    if "access-control-allow-origin" in [name.lower() for name in names]:
+        return response
+    headers = {"Access-Control-Allow-Origin": allowed}
+    if cors.get("credentials"):
+        headers["Access-Control-Allow-Credentials"] = "true"
+    if response.get("multiValueHeaders"):
+        response["multiValueHeaders"].update({name: [value] for name, value in headers.items()})
+    else:
+        response["headers"] = dict(response.get("headers") or {}, **headers)
+    return response
+
+
 def handler(event, context):
     if settings.DEBUG:
         logger.debug("Jerm Event: {}".format(event))
 
+    if event.get("jerm_keep_warm"):
+        return {"warm": True}
+
+    function = route_authorizer(event) or route_event_source(event) or route_websocket(event)
+    if function:
+        return function(event, context)
+
     if event.get("manage"):
         output = io.StringIO()
         management.call_command(*event["manage"].split(" "), stdout=output)
@@ -32,7 +194,10 @@ def handler(event, context):
     try:
         if event.get("httpMethod", None):
             handler = LambdaWSGIHandler(application)
-            return handler(event, context)
+            return add_cors_headers(event, handler(event, context))
+        if is_http_v2(event):
+            handler = LambdaWSGIHandler(application)
+            return to_http_v2(add_cors_headers(event, handler(from_http_v2(event), context)))
     except Exception as e:
         print(e)
         exc_info = sys.exc_info()
@@ -0,0 +1,22 @@
+package handlers
+
+const (
+	AwsLambdaDockerfilePython = `
+FROM public.ecr.aws/lambda/python:%s
+COPY . ${LAMBDA_TASK_ROOT}
+CMD [ "%s" ]
+	`
+
+	AwsLambdaDockerfileNode = `
+FROM public.ecr.aws/lambda/nodejs:%s
+COPY . ${LAMBDA_TASK_ROOT}
+CMD [ "%s" ]
+	`
+
+	AwsLambdaDockerfileGo = `
+FROM public.ecr.aws/lambda/provided:al2
+COPY %[1]s ${LAMBDA_RUNTIME_DIR}/bootstrap
+RUN chmod 755 ${LAMBDA_RUNTIME_DIR}/bootstrap
+CMD [ "%[1]s" ]
+	`
+)
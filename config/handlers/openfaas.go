@@ -0,0 +1,157 @@
+package handlers
+
+const (
+	OpenFaaSDockerfilePython = `
+FROM ghcr.io/openfaas/of-watchdog:0.9.12 AS watchdog
+FROM python:%s-slim
+
+COPY --from=watchdog /fwatchdog /usr/bin/fwatchdog
+WORKDIR /home/app
+COPY . .
+
+ENV fprocess="python jerm_openfaas.py"
+ENV mode="http"
+ENV upstream_url="http://127.0.0.1:5000"
+ENV JERM_HANDLER="%s"
+
+EXPOSE 8080
+CMD ["fwatchdog"]
+	`
+
+	OpenFaaSDockerfileNode = `
+FROM ghcr.io/openfaas/of-watchdog:0.9.12 AS watchdog
+FROM node:%s-alpine
+
+COPY --from=watchdog /fwatchdog /usr/bin/fwatchdog
+WORKDIR /home/app
+COPY . .
+
+ENV fprocess="node jerm_openfaas.js"
+ENV mode="http"
+ENV upstream_url="http://127.0.0.1:5000"
+ENV JERM_HANDLER="%s"
+
+EXPOSE 8080
+CMD ["fwatchdog"]
+	`
+
+	OpenFaaSHandlerPython = `
+import base64
+import importlib
+import json
+import os
+from http.server import BaseHTTPRequestHandler, HTTPServer
+from urllib.parse import parse_qsl, urlsplit
+
+module_name, function_name = os.environ["JERM_HANDLER"].rsplit(".", 1)
+handler = getattr(importlib.import_module(module_name), function_name)
+
+
+class Context:
+    function_name = os.environ.get("HOSTNAME", "")
+    aws_request_id = ""
+
+
+class Handler(BaseHTTPRequestHandler):
+    def __getattr__(self, name):
+        if name.startswith("do_"):
+            return self.handle_event
+        raise AttributeError(name)
+
+    def handle_event(self):
+        length = int(self.headers.get("Content-Length", 0))
+        body = self.rfile.read(length).decode("utf-8") if length else ""
+
+        event = None
+        if body.startswith("{"):
+            try:
+                payload = json.loads(body)
+                if payload.get("manage"):
+                    event = payload
+            except ValueError:
+                pass
+
+        if event is None:
+            url = urlsplit(self.path)
+            event = {
+                "httpMethod": self.command,
+                "path": url.path,
+                "headers": dict(self.headers),
+                "queryStringParameters": dict(parse_qsl(url.query)),
+                "body": body,
+                "isBase64Encoded": False,
+                "requestContext": {},
+            }
+
+        response = handler(event, Context()) or {}
+        if "statusCode" not in response:
+            response = {"statusCode": 200, "body": json.dumps(response)}
+
+        self.send_response(response.get("statusCode", 200))
+        for key, value in (response.get("headers") or {}).items():
+            self.send_header(key, value)
+        self.end_headers()
+
+        content = response.get("body") or ""
+        if response.get("isBase64Encoded"):
+            self.wfile.write(base64.b64decode(content))
+        else:
+            self.wfile.write(content.encode("utf-8"))
+
+
+HTTPServer(("127.0.0.1", 5000), Handler).serve_forever()
+	`
+
+	OpenFaaSHandlerNode = `
+const http = require('http');
+const path = require('path');
+
+const handler = process.env.JERM_HANDLER;
+const index = handler.lastIndexOf('.');
+const fn = require(path.resolve(handler.substring(0, index)))[handler.substring(index + 1)];
+
+const server = http.createServer((req, res) => {
+	const chunks = [];
+	req.on('data', (chunk) => chunks.push(chunk));
+	req.on('end', async () => {
+		const body = Buffer.concat(chunks).toString();
+		const url = new URL(req.url, 'http://localhost');
+
+		let event;
+		try {
+			const payload = JSON.parse(body);
+			if (payload.manage) {
+				event = payload;
+			}
+		} catch (e) {}
+
+		if (!event) {
+			event = {
+				httpMethod: req.method,
+				path: url.pathname,
+				headers: req.headers,
+				queryStringParameters: Object.fromEntries(url.searchParams),
+				body: body,
+				isBase64Encoded: false,
+				requestContext: {},
+			};
+		}
+
+		try {
+			let response = (await fn(event, { functionName: process.env.HOSTNAME })) || {};
+			if (response.statusCode === undefined) {
+				response = { statusCode: 200, body: JSON.stringify(response) };
+			}
+			res.writeHead(response.statusCode, response.headers || {});
+			res.end(response.isBase64Encoded ? Buffer.from(response.body, 'base64') : response.body);
+		} catch (e) {
+			console.error(e);
+			res.writeHead(500);
+			res.end();
+		}
+	});
+});
+
+server.listen(5000, '127.0.0.1');
+	`
+)
@@ -3,9 +3,56 @@ package handlers
 const (
 	AwsLambdaHandlerStaticPage = `
 const fs = require('fs');
+const path = require('path');
 const html = fs.readFileSync('index.html', { encoding:'utf8' });
 
-exports.handler = async (event) => {
+const acceptWebSocket = async () => ({ statusCode: 200 });
+
+const importFunction = (handler) => {
+	const idx = handler.lastIndexOf('.');
+	const module = require(path.resolve(handler.slice(0, idx)));
+	return module[handler.slice(idx + 1)];
+};
+
+const routeWebSocket = (event) => {
+	const eventType = (event.requestContext || {}).eventType;
+	if (!['CONNECT', 'MESSAGE', 'DISCONNECT'].includes(eventType)) {
+		return null;
+	}
+	const handler = process.env.JERM_WEBSOCKET;
+	if (!handler) {
+		return acceptWebSocket;
+	}
+	return importFunction(handler);
+};
+
+// postToConnection sends data to a connection of the WebSocket API of the event.
+// It defaults to the connection of the event.
+const postToConnection = async (event, data, connectionId) => {
+	const { ApiGatewayManagementApiClient, PostToConnectionCommand } = require('@aws-sdk/client-apigatewaymanagementapi');
+	const requestContext = event.requestContext;
+	const client = new ApiGatewayManagementApiClient({
+		endpoint: 'https://' + requestContext.domainName + '/' + requestContext.stage,
+	});
+	if (typeof data !== 'string' && !Buffer.isBuffer(data)) {
+		data = JSON.stringify(data);
+	}
+	await client.send(new PostToConnectionCommand({
+		ConnectionId: connectionId || requestContext.connectionId,
+		Data: data,
+	}));
+};
+
+exports.postToConnection = postToConnection;
+
+exports.handler = async (event, context) => {
+	if (event.jerm_keep_warm) {
+		return { warm: true };
+	}
+	const websocket = routeWebSocket(event);
+	if (websocket) {
+		return websocket(event, context);
+	}
 	const response = {
 		statusCode: 200,
 		headers: {
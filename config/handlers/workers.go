@@ -0,0 +1,11 @@
+package handlers
+
+const (
+	CloudflareWorkerHandlerStaticPage = `
+export default {
+	async fetch(request, env) {
+		return env.ASSETS.fetch(request);
+	},
+};
+	`
+)
@@ -6,19 +6,63 @@ const (
 	DefaultTimeout              = 30
 	DefaultMemory               = 512
 	Lambda         PlatformName = "lambda"
+	CloudFunctions PlatformName = "cloudfunctions"
+	AzureFunctions PlatformName = "azurefunctions"
+	Workers        PlatformName = "workers"
+	OpenFaaS       PlatformName = "openfaas"
+	PackageZip                  = "zip"
+	PackageImage                = "image"
+	HttpRest                    = "rest"
+	HttpApi                     = "http"
+	HttpUrl                     = "url"
+	HttpNone                    = "none"
 )
 
 type PlatformName string
 
 // Platform configuration.
 type Platform struct {
-	Name     PlatformName `json:"name"`
-	Runtime  string       `json:"runtime"`
-	Timeout  int          `json:"timeout"`
-	Role     string       `json:"role"`
-	Memory   int          `json:"memory"`
-	Handler  string       `json:"handler"`
-	KeepWarm bool         `json:"keep_warm"`
+	Name        PlatformName `json:"name"`
+	Runtime     string       `json:"runtime"`
+	Timeout     int          `json:"timeout"`
+	Role        string       `json:"role"`
+	Memory      int          `json:"memory"`
+	Handler     string       `json:"handler"`
+	KeepWarm    bool         `json:"keep_warm"`
+	PackageType string       `json:"package_type,omitempty"`
+	Project     string       `json:"project,omitempty"`
+	Endpoint    string       `json:"endpoint,omitempty"`
+
+	Subscription  string `json:"subscription,omitempty"`
+	ResourceGroup string `json:"resource_group,omitempty"`
+	Plan          string `json:"plan,omitempty"`
+	Account       string `json:"account,omitempty"`
+	Registry      string `json:"registry,omitempty"`
+
+	Vpc  *Vpc  `json:"vpc,omitempty"`
+	Http *Http `json:"http,omitempty"`
+}
+
+// Vpc is the VPC configuration of a function.
+// Subnets tagged with SubnetTags are discovered when SubnetIds is empty.
+type Vpc struct {
+	SubnetIds        []string          `json:"subnet_ids,omitempty"`
+	SecurityGroupIds []string          `json:"security_group_ids,omitempty"`
+	SubnetTags       map[string]string `json:"subnet_tags,omitempty"`
+}
+
+// Http is the HTTP endpoint of a function. Type is one of rest, http, url
+// and none, and defaults to rest.
+type Http struct {
+	Type string `json:"type,omitempty"`
+}
+
+// GetHttpType returns the type of the HTTP endpoint of the function
+func (l *Platform) GetHttpType() string {
+	if l.Http == nil || l.Http.Type == "" {
+		return HttpRest
+	}
+	return l.Http.Type
 }
 
 func (l *Platform) Defaults() error {
@@ -42,6 +86,16 @@ func (l *Platform) Defaults() error {
 			if l.Runtime == RuntimeStatic {
 				l.Runtime = fmt.Sprintf("nodejs%s.x", DefaultNodeVersion[0:2])
 			}
+		case CloudFunctions:
+			l.Runtime, err = runtime.cloudFunctionsRuntime()
+			if err != nil {
+				return err
+			}
+		case AzureFunctions:
+			l.Runtime, err = runtime.azureFunctionsRuntime()
+			if err != nil {
+				return err
+			}
 		}
 	}
 
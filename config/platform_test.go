@@ -41,3 +41,13 @@ func TestPlaformDefaults(t *testing.T) {
 	assert.Contains(p.Runtime, "python")
 	helperCleanup(t, []string{requirementsTxt})
 }
+
+func TestPlatformGetHttpType(t *testing.T) {
+	assert := assert.New(t)
+	p := &Platform{Name: Lambda}
+	assert.Equal(HttpRest, p.GetHttpType())
+	p.Http = &Http{}
+	assert.Equal(HttpRest, p.GetHttpType())
+	p.Http.Type = HttpUrl
+	assert.Equal(HttpUrl, p.GetHttpType())
+}
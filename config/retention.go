@@ -0,0 +1,28 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DefaultRetainedVersions is the number of latest deployments kept when no retention is configured
+const DefaultRetainedVersions = 10
+
+// Retention is how many deployments are kept when old ones are pruned
+type Retention struct {
+	// Versions is the number of latest versions and archives kept.
+	// Versions referenced by aliases are always kept.
+	Versions int `json:"versions,omitempty"`
+}
+
+// GetRetainedVersions returns the number of latest deployments kept
+func (c *Config) GetRetainedVersions() (int, error) {
+	if c.Retention == nil || c.Retention.Versions == 0 {
+		return DefaultRetainedVersions, nil
+	}
+	if c.Retention.Versions < 0 {
+		msg := fmt.Sprintf("invalid retention versions %d. Retention versions must be positive", c.Retention.Versions)
+		return 0, errors.New(msg)
+	}
+	return c.Retention.Versions, nil
+}
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigGetRetainedVersions(t *testing.T) {
+	assert := assert.New(t)
+	cfg := &Config{}
+	versions, err := cfg.GetRetainedVersions()
+	assert.Nil(err)
+	assert.Equal(DefaultRetainedVersions, versions)
+
+	cfg.Retention = &Retention{Versions: 3}
+	versions, err = cfg.GetRetainedVersions()
+	assert.Nil(err)
+	assert.Equal(3, versions)
+
+	cfg.Retention.Versions = -1
+	_, err = cfg.GetRetainedVersions()
+	assert.EqualError(err, "invalid retention versions -1. Retention versions must be positive")
+}
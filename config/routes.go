@@ -0,0 +1,155 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	MaxFunctionNameLength = 64
+	MinMemory             = 128
+	MaxMemory             = 10240
+	MaxTimeout            = 900
+)
+
+// RouteMethods are the HTTP methods of routes. ANY matches every method.
+var RouteMethods = []string{"ANY", "DELETE", "GET", "HEAD", "OPTIONS", "PATCH", "POST", "PUT"}
+
+var (
+	routeNamePattern    = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	routeSegmentPattern = regexp.MustCompile(`^([A-Za-z0-9._~-]+|\{[A-Za-z0-9_]+\+?\})$`)
+)
+
+// Route serves the requests of a path with a function of its own, deployed
+// from the package of the project. Handler is the handler of the function
+// and defaults to the handler of the project.
+type Route struct {
+	Path    string   `json:"path"`
+	Methods []string `json:"methods,omitempty"`
+	Handler string   `json:"handler,omitempty"`
+	Memory  int      `json:"memory,omitempty"`
+	Timeout int      `json:"timeout,omitempty"`
+}
+
+// RouteFunctionName returns the name of the function of a route
+func (c *Config) RouteFunctionName(name string) string {
+	return fmt.Sprintf("%s-%s", c.GetFunctionName(), name)
+}
+
+// RouteNames returns the names of the routes in order
+func (c *Config) RouteNames() []string {
+	var names []string
+	for name := range c.Routes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetRoutes returns the validated routes of the REST API with their defaults
+func (c *Config) GetRoutes() (map[string]Route, error) {
+	routes := map[string]Route{}
+	// methods of each path and the path variable under each path
+	methods := map[string]map[string]string{}
+	variables := map[string]string{}
+	for _, name := range c.RouteNames() {
+		route := c.Routes[name]
+		if !routeNamePattern.MatchString(name) {
+			msg := fmt.Sprintf("invalid route name %q. Route names may only contain letters, numbers, hyphens and underscores", name)
+			return nil, errors.New(msg)
+		}
+		if function := c.RouteFunctionName(name); len(function) > MaxFunctionNameLength {
+			msg := fmt.Sprintf("invalid route name %s. Function name %s is longer than %d characters", name, function, MaxFunctionNameLength)
+			return nil, errors.New(msg)
+		}
+
+		path, err := normalizeRoutePath(route.Path)
+		if err != nil {
+			return nil, fmt.Errorf("route %s: %w", name, err)
+		}
+		route.Path = path
+
+		parent := "/"
+		for _, segment := range RouteSegments(path) {
+			if strings.HasPrefix(segment, "{") {
+				if variable, ok := variables[parent]; ok && variable != segment {
+					msg := fmt.Sprintf("route %s: path variable %s conflicts with %s of another route", name, segment, variable)
+					return nil, errors.New(msg)
+				}
+				variables[parent] = segment
+			}
+			parent = strings.TrimSuffix(parent, "/") + "/" + segment
+		}
+
+		if len(route.Methods) == 0 {
+			route.Methods = []string{"ANY"}
+		}
+		routeMethods := make([]string, len(route.Methods))
+		for i, method := range route.Methods {
+			routeMethods[i] = strings.ToUpper(method)
+			validMethod := false
+			for _, m := range RouteMethods {
+				validMethod = validMethod || m == routeMethods[i]
+			}
+			if !validMethod {
+				msg := fmt.Sprintf("route %s: invalid method %s. Supported methods are %s", name, method, strings.Join(RouteMethods, ", "))
+				return nil, errors.New(msg)
+			}
+			if methods[path] == nil {
+				methods[path] = map[string]string{}
+			}
+			if other, ok := methods[path][routeMethods[i]]; ok {
+				msg := fmt.Sprintf("route %s: %s %s is already served by route %s", name, routeMethods[i], path, other)
+				return nil, errors.New(msg)
+			}
+			methods[path][routeMethods[i]] = name
+		}
+		route.Methods = routeMethods
+
+		if route.Memory != 0 && (route.Memory < MinMemory || route.Memory > MaxMemory) {
+			msg := fmt.Sprintf("route %s: invalid memory %d. Memory must be between %d and %d MB", name, route.Memory, MinMemory, MaxMemory)
+			return nil, errors.New(msg)
+		}
+		if route.Timeout < 0 || route.Timeout > MaxTimeout {
+			msg := fmt.Sprintf("route %s: invalid timeout %d. Timeout must be between 1 and %d seconds", name, route.Timeout, MaxTimeout)
+			return nil, errors.New(msg)
+		}
+		routes[name] = route
+	}
+	return routes, nil
+}
+
+// normalizeRoutePath validates the path of a route and removes its trailing slash
+func normalizeRoutePath(path string) (string, error) {
+	if !strings.HasPrefix(path, "/") {
+		msg := fmt.Sprintf("invalid path %q. Paths must start with /", path)
+		return "", errors.New(msg)
+	}
+	if path != "/" {
+		path = strings.TrimSuffix(path, "/")
+	}
+	segments := RouteSegments(path)
+	for i, segment := range segments {
+		if !routeSegmentPattern.MatchString(segment) {
+			msg := fmt.Sprintf("invalid path %q. Path segments must be names or variables like {id}", path)
+			return "", errors.New(msg)
+		}
+		if strings.HasSuffix(segment, "+}") && i != len(segments)-1 {
+			msg := fmt.Sprintf("invalid path %q. Greedy variables like {proxy+} must be the last segment", path)
+			return "", errors.New(msg)
+		}
+	}
+	return path, nil
+}
+
+// RouteSegments returns the segments of the path of a route
+func RouteSegments(path string) []string {
+	trimmed := strings.Trim(path, "/")
+	if trimmed == "" {
+		return nil
+	}
+	return strings.Split(trimmed, "/")
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigGetRoutes(t *testing.T) {
+	assert := assert.New(t)
+	cfg := &Config{Name: "test", Stage: "dev"}
+	routes, err := cfg.GetRoutes()
+	assert.Nil(err)
+	assert.Empty(routes)
+
+	cfg.Routes = map[string]Route{
+		"reports": {Path: "/reports/{proxy+}", Handler: "reports.handler", Memory: 2048, Timeout: 300},
+		"report":  {Path: "/reports/{proxy+}/", Methods: []string{"get"}},
+		"users":   {Path: "/users/{id}"},
+	}
+	routes, err = cfg.GetRoutes()
+	assert.Nil(err)
+	assert.Equal([]string{"report", "reports", "users"}, cfg.RouteNames())
+	assert.Equal("test-dev-reports", cfg.RouteFunctionName("reports"))
+	assert.Equal("/reports/{proxy+}", routes["report"].Path)
+	assert.Equal([]string{"GET"}, routes["report"].Methods)
+	assert.Equal([]string{"ANY"}, routes["reports"].Methods)
+	assert.Equal([]string{"get"}, cfg.Routes["report"].Methods)
+	assert.Equal([]string{"users", "{id}"}, RouteSegments(routes["users"].Path))
+	assert.Nil(RouteSegments("/"))
+
+	cases := []struct {
+		routes map[string]Route
+		err    string
+	}{
+		{map[string]Route{"a b": {Path: "/a"}}, `invalid route name "a b". Route names may only contain letters, numbers, hyphens and underscores`},
+		{map[string]Route{"reports": {Path: "reports"}}, `route reports: invalid path "reports". Paths must start with /`},
+		{map[string]Route{"reports": {Path: "/reports//all"}}, `route reports: invalid path "/reports//all". Path segments must be names or variables like {id}`},
+		{map[string]Route{"reports": {Path: "/{proxy+}/all"}}, `route reports: invalid path "/{proxy+}/all". Greedy variables like {proxy+} must be the last segment`},
+		{map[string]Route{"a": {Path: "/users/{id}"}, "b": {Path: "/users/{name}/posts"}}, "route b: path variable {name} conflicts with {id} of another route"},
+		{map[string]Route{"a": {Path: "/reports"}, "b": {Path: "/reports", Methods: []string{"any"}}}, "route b: ANY /reports is already served by route a"},
+		{map[string]Route{"reports": {Path: "/reports", Methods: []string{"TRACE"}}}, "route reports: invalid method TRACE. Supported methods are ANY, DELETE, GET, HEAD, OPTIONS, PATCH, POST, PUT"},
+		{map[string]Route{"reports": {Path: "/reports", Memory: 64}}, "route reports: invalid memory 64. Memory must be between 128 and 10240 MB"},
+		{map[string]Route{"reports": {Path: "/reports", Timeout: 901}}, "route reports: invalid timeout 901. Timeout must be between 1 and 900 seconds"},
+		{map[string]Route{"reports-generated-for-the-finance-team-at-the-end-of-every-month": {Path: "/reports"}}, "invalid route name reports-generated-for-the-finance-team-at-the-end-of-every-month. Function name test-dev-reports-generated-for-the-finance-team-at-the-end-of-every-month is longer than 64 characters"},
+	}
+	for _, tt := range cases {
+		cfg.Routes = tt.routes
+		_, err = cfg.GetRoutes()
+		assert.EqualError(err, tt.err)
+	}
+}
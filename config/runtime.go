@@ -53,6 +53,12 @@ type RuntimeInterface interface {
 
 	// lambdaRuntime returns the name of runtime as specified by AWS Lambda
 	lambdaRuntime() (string, error)
+
+	// cloudFunctionsRuntime returns the name of runtime as specified by Google Cloud Functions
+	cloudFunctionsRuntime() (string, error)
+
+	// azureFunctionsRuntime returns the name of runtime as specified by Azure Functions
+	azureFunctionsRuntime() (string, error)
 }
 
 // Base Runtime
@@ -108,6 +114,11 @@ func (r *Runtime) Build(config *Config) (string, string, error) {
 	return tempDir, function, nil
 }
 
+// WithHandlerTemplate sets the template of the generated function handler
+func (r *Runtime) WithHandlerTemplate(template string) {
+	r.handlerTemplate = template
+}
+
 // createFunctionHandler creates a serverless function handler file
 func (r *Runtime) createFunctionHandler(file string, content []byte) (string, error) {
 	log.Debug("creating lambda handler...")
@@ -166,3 +177,41 @@ func (r *Runtime) lambdaRuntime() (string, error) {
 	v := strings.Split(r.Version, ".")
 	return fmt.Sprintf("%s%s", r.Name, v[0]), nil
 }
+
+// cloudFunctionsRuntime is the name of the runtime as specified by Google Cloud Functions
+func (r *Runtime) cloudFunctionsRuntime() (string, error) {
+	if r.Name == RuntimeUnknown {
+		return "", errors.New("cannot detect runtime. please specify runtime in your Jerm.json file")
+	}
+	if r.Name == RuntimeStatic {
+		v := strings.Split(DefaultNodeVersion, ".")
+		return fmt.Sprintf("%s%s", RuntimeNode, v[0]), nil
+	}
+	v := strings.Split(r.Version, ".")
+	if r.Name == RuntimeNode || len(v) < 2 {
+		return fmt.Sprintf("%s%s", r.Name, v[0]), nil
+	}
+	return fmt.Sprintf("%s%s%s", r.Name, v[0], v[1]), nil
+}
+
+// azureFunctionsRuntime is the name of the runtime stack as specified by Azure Functions.
+// Runtimes without a native Azure Functions stack run as custom handlers.
+func (r *Runtime) azureFunctionsRuntime() (string, error) {
+	if r.Name == RuntimeUnknown {
+		return "", errors.New("cannot detect runtime. please specify runtime in your Jerm.json file")
+	}
+	v := strings.Split(r.Version, ".")
+	switch r.Name {
+	case RuntimePython:
+		if len(v) < 2 {
+			return "", fmt.Errorf("invalid python version %s", r.Version)
+		}
+		return fmt.Sprintf("python|%s.%s", v[0], v[1]), nil
+	case RuntimeNode:
+		return fmt.Sprintf("node|%s", v[0]), nil
+	case RuntimeStatic:
+		return fmt.Sprintf("node|%s", strings.Split(DefaultNodeVersion, ".")[0]), nil
+	default:
+		return "custom", nil
+	}
+}
@@ -142,6 +142,46 @@ func TestNewRuntime(t *testing.T) {
 	helperCleanup(t, []string{indexHtml})
 }
 
+func TestRuntimeCloudFunctionsRuntime(t *testing.T) {
+	assert := assert.New(t)
+
+	r := &Runtime{Name: RuntimeUnknown}
+	_, err := r.cloudFunctionsRuntime()
+	assert.Error(err)
+
+	cases := map[string]*Runtime{
+		"python311": {Name: RuntimePython, Version: "3.11.4"},
+		"nodejs20":  {Name: RuntimeNode, Version: "20.5.1"},
+		"go121":     {Name: RuntimeGo, Version: "1.21.0"},
+		"nodejs18":  {Name: RuntimeStatic},
+	}
+	for expected, r := range cases {
+		name, err := r.cloudFunctionsRuntime()
+		assert.Nil(err)
+		assert.Equal(expected, name)
+	}
+}
+
+func TestRuntimeAzureFunctionsRuntime(t *testing.T) {
+	assert := assert.New(t)
+
+	r := &Runtime{Name: RuntimeUnknown}
+	_, err := r.azureFunctionsRuntime()
+	assert.Error(err)
+
+	cases := map[string]*Runtime{
+		"python|3.11": {Name: RuntimePython, Version: "3.11.4"},
+		"node|20":     {Name: RuntimeNode, Version: "20.5.1"},
+		"custom":      {Name: RuntimeGo, Version: "1.21.0"},
+		"node|18":     {Name: RuntimeStatic},
+	}
+	for expected, r := range cases {
+		name, err := r.azureFunctionsRuntime()
+		assert.Nil(err)
+		assert.Equal(expected, name)
+	}
+}
+
 func helperCleanup(t *testing.T, files []string) {
 	for _, file := range files {
 		err := os.RemoveAll(file)
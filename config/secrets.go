@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	SecretsDeploy  = "deploy"
+	SecretsRuntime = "runtime"
+	SSMSecret      = "ssm"
+	SecretsManager = "secretsmanager"
+)
+
+// SecretReference is a reference to a secret in a secret store.
+// It's written as ssm:/myapp/db_password or secretsmanager:prod/db
+// in the environment of the config.
+type SecretReference struct {
+	Store string
+	Name  string
+}
+
+func (s SecretReference) String() string {
+	return fmt.Sprintf("%s:%s", s.Store, s.Name)
+}
+
+// ParseSecretReference parses a secret reference from an environment value.
+// It returns false if the value isn't a secret reference.
+func ParseSecretReference(value string) (SecretReference, bool) {
+	store, name, found := strings.Cut(value, ":")
+	if !found || name == "" {
+		return SecretReference{}, false
+	}
+	switch store {
+	case SSMSecret, SecretsManager:
+		return SecretReference{Store: store, Name: name}, true
+	}
+	return SecretReference{}, false
+}
+
+// GetSecretReferences returns the sorted secret references in the environment
+func (c *Config) GetSecretReferences() ([]SecretReference, error) {
+	env, err := c.GetEnvironment()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[SecretReference]bool{}
+	var references []SecretReference
+	for _, value := range env {
+		reference, ok := ParseSecretReference(value)
+		if !ok || seen[reference] {
+			continue
+		}
+		seen[reference] = true
+		references = append(references, reference)
+	}
+
+	sort.Slice(references, func(i, j int) bool {
+		return references[i].String() < references[j].String()
+	})
+	return references, nil
+}
+
+// GetSecretsPath returns the path of the secrets of the project stage
+func (c *Config) GetSecretsPath() string {
+	return fmt.Sprintf("/jerm/%s/%s", c.Name, c.Stage)
+}
+
+// ResolvesSecretsAtRuntime checks if secrets are read by the function at runtime
+// instead of being resolved at deploy time
+func (c *Config) ResolvesSecretsAtRuntime() bool {
+	return c.SecretsMode == SecretsRuntime
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSecretReference(t *testing.T) {
+	assert := assert.New(t)
+
+	reference, ok := ParseSecretReference("ssm:/myapp/db_password")
+	assert.True(ok)
+	assert.Equal(SecretReference{Store: SSMSecret, Name: "/myapp/db_password"}, reference)
+
+	reference, ok = ParseSecretReference("secretsmanager:prod/db")
+	assert.True(ok)
+	assert.Equal(SecretReference{Store: SecretsManager, Name: "prod/db"}, reference)
+	assert.Equal("secretsmanager:prod/db", reference.String())
+
+	_, ok = ParseSecretReference("postgres://localhost:5432")
+	assert.False(ok)
+	_, ok = ParseSecretReference("ssm:")
+	assert.False(ok)
+}
+
+func TestConfigGetSecretReferences(t *testing.T) {
+	assert := assert.New(t)
+	cfg := &Config{
+		Name:  "test",
+		Stage: "dev",
+		Environment: map[string]string{
+			"DB_PASSWORD": "ssm:/myapp/db_password",
+			"DB":          "secretsmanager:prod/db",
+			"PASSWORD":    "ssm:/myapp/db_password",
+			"DEBUG":       "true",
+		},
+	}
+	references, err := cfg.GetSecretReferences()
+	assert.Nil(err)
+	assert.Equal([]SecretReference{
+		{Store: SecretsManager, Name: "prod/db"},
+		{Store: SSMSecret, Name: "/myapp/db_password"},
+	}, references)
+	assert.Equal("/jerm/test/dev", cfg.GetSecretsPath())
+
+	assert.False(cfg.ResolvesSecretsAtRuntime())
+	cfg.SecretsMode = SecretsRuntime
+	assert.True(cfg.ResolvesSecretsAtRuntime())
+}
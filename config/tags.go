@@ -0,0 +1,33 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Tags jerm adds to the resources it creates
+const (
+	TagProject   = "jerm:project"
+	TagStage     = "jerm:stage"
+	TagVersion   = "jerm:version"
+	TagRoute     = "jerm:route"
+	TagWebSocket = "jerm:websocket"
+)
+
+// GetTags returns the tags of the resources of the project: the user tags
+// with the project and stage tags
+func (c *Config) GetTags() (map[string]string, error) {
+	tags := map[string]string{}
+	for key, value := range c.Tags {
+		prefix := strings.ToLower(key)
+		if strings.HasPrefix(prefix, "aws:") || strings.HasPrefix(prefix, "jerm:") {
+			msg := fmt.Sprintf("invalid tag %s. Tags prefixed with aws: or jerm: are reserved", key)
+			return nil, errors.New(msg)
+		}
+		tags[key] = value
+	}
+	tags[TagProject] = c.Name
+	tags[TagStage] = c.Stage
+	return tags, nil
+}
@@ -0,0 +1,19 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigGetTags(t *testing.T) {
+	assert := assert.New(t)
+	cfg := &Config{Name: "test", Stage: "dev", Tags: map[string]string{"team": "payments"}}
+	tags, err := cfg.GetTags()
+	assert.Nil(err)
+	assert.Equal(map[string]string{"team": "payments", TagProject: "test", TagStage: "dev"}, tags)
+
+	cfg.Tags["jerm:stage"] = "prod"
+	_, err = cfg.GetTags()
+	assert.EqualError(err, "invalid tag jerm:stage. Tags prefixed with aws: or jerm: are reserved")
+}
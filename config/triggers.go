@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+const DefaultS3TriggerEvent = "s3:ObjectCreated:*"
+
+// Triggers are the S3 buckets and SNS topics that invoke the function
+type Triggers struct {
+	S3  []S3Trigger  `json:"s3,omitempty"`
+	SNS []SNSTrigger `json:"sns,omitempty"`
+}
+
+// S3Trigger invokes the function on notifications of a bucket
+type S3Trigger struct {
+	Bucket   string   `json:"bucket"`
+	Events   []string `json:"events,omitempty"`
+	Prefix   string   `json:"prefix,omitempty"`
+	Suffix   string   `json:"suffix,omitempty"`
+	Function string   `json:"function,omitempty"`
+}
+
+// SNSTrigger invokes the function on messages of a topic
+type SNSTrigger struct {
+	Topic    string `json:"topic"`
+	Function string `json:"function,omitempty"`
+}
+
+// BucketArn returns the ARN of the bucket of the trigger
+func (s S3Trigger) BucketArn() string {
+	return fmt.Sprintf("arn:aws:s3:::%s", s.Bucket)
+}
+
+// GetEvents returns the bucket events of the trigger.
+// It defaults to object creation events.
+func (s S3Trigger) GetEvents() []string {
+	if len(s.Events) == 0 {
+		return []string{DefaultS3TriggerEvent}
+	}
+	return s.Events
+}
+
+// GetTriggers returns the validated triggers of the function
+func (c *Config) GetTriggers() (Triggers, error) {
+	if c.Triggers == nil {
+		return Triggers{}, nil
+	}
+
+	functions := map[string]string{}
+	for _, trigger := range c.Triggers.S3 {
+		if trigger.Bucket == "" {
+			return Triggers{}, fmt.Errorf("s3 trigger requires a bucket")
+		}
+		for _, event := range trigger.GetEvents() {
+			if !strings.HasPrefix(event, "s3:") {
+				return Triggers{}, fmt.Errorf("invalid event %s of s3 trigger %s", event, trigger.Bucket)
+			}
+		}
+
+		// records are routed to functions by bucket
+		if function, ok := functions[trigger.Bucket]; ok && function != trigger.Function {
+			return Triggers{}, fmt.Errorf("s3 triggers of bucket %s route to different functions", trigger.Bucket)
+		}
+		functions[trigger.Bucket] = trigger.Function
+	}
+
+	seen := map[string]bool{}
+	for _, trigger := range c.Triggers.SNS {
+		if !strings.HasPrefix(trigger.Topic, "arn:") {
+			return Triggers{}, fmt.Errorf("invalid sns topic arn %q", trigger.Topic)
+		}
+		if seen[trigger.Topic] {
+			return Triggers{}, fmt.Errorf("duplicate sns trigger %s", trigger.Topic)
+		}
+		seen[trigger.Topic] = true
+	}
+	return *c.Triggers, nil
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigGetTriggers(t *testing.T) {
+	assert := assert.New(t)
+	cfg := &Config{}
+	triggers, err := cfg.GetTriggers()
+	assert.Nil(err)
+	assert.Empty(triggers.S3)
+
+	cfg.Triggers = &Triggers{
+		S3: []S3Trigger{
+			{Bucket: "uploads", Prefix: "images/", Function: "app.images.resize"},
+			{Bucket: "uploads", Events: []string{"s3:ObjectRemoved:*"}, Function: "app.images.resize"},
+		},
+		SNS: []SNSTrigger{{Topic: "arn:aws:sns:us-west-2:123456789012:alerts"}},
+	}
+	triggers, err = cfg.GetTriggers()
+	assert.Nil(err)
+	assert.Equal(*cfg.Triggers, triggers)
+	assert.Equal([]string{DefaultS3TriggerEvent}, triggers.S3[0].GetEvents())
+	assert.Equal("arn:aws:s3:::uploads", triggers.S3[0].BucketArn())
+
+	cfg.Triggers.S3[1].Function = "app.images.delete"
+	_, err = cfg.GetTriggers()
+	assert.EqualError(err, "s3 triggers of bucket uploads route to different functions")
+
+	cfg.Triggers.S3 = []S3Trigger{{Bucket: "uploads", Events: []string{"ObjectCreated"}}}
+	_, err = cfg.GetTriggers()
+	assert.EqualError(err, "invalid event ObjectCreated of s3 trigger uploads")
+
+	cfg.Triggers.S3 = nil
+	cfg.Triggers.SNS = append(cfg.Triggers.SNS, SNSTrigger{Topic: "arn:aws:sns:us-west-2:123456789012:alerts"})
+	_, err = cfg.GetTriggers()
+	assert.EqualError(err, "duplicate sns trigger arn:aws:sns:us-west-2:123456789012:alerts")
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	WebSocketConnect    = "$connect"
+	WebSocketDisconnect = "$disconnect"
+	WebSocketDefault    = "$default"
+
+	DefaultRouteSelectionExpression = "$request.body.action"
+	MaxRouteKeyLength               = 128
+)
+
+// WebSocket is the WebSocket API of the function. Every connection, disconnection
+// and message is sent to the function. Messages are routed by the value the
+// route selection expression selects from their body: Routes are the route
+// keys of the API besides $connect, $disconnect and $default, which receives
+// the messages no other route matches.
+type WebSocket struct {
+	Routes                   []string `json:"routes,omitempty"`
+	RouteSelectionExpression string   `json:"route_selection_expression,omitempty"`
+
+	// Handler is a handler in the package of the function the generated handlers
+	// route the WebSocket events to
+	Handler string `json:"handler,omitempty"`
+}
+
+// RouteKeys returns the route keys of the WebSocket API
+func (w WebSocket) RouteKeys() []string {
+	return append([]string{WebSocketConnect, WebSocketDisconnect, WebSocketDefault}, w.Routes...)
+}
+
+// GetWebSocket returns the validated WebSocket API of the function with its defaults
+func (c *Config) GetWebSocket() (WebSocket, error) {
+	if c.WebSocket == nil {
+		return WebSocket{}, errors.New("no websocket configured. Set websocket in your jerm.json file")
+	}
+	websocket := *c.WebSocket
+
+	if websocket.RouteSelectionExpression == "" {
+		websocket.RouteSelectionExpression = DefaultRouteSelectionExpression
+	}
+	if !strings.HasPrefix(websocket.RouteSelectionExpression, "$request.body.") &&
+		!strings.HasPrefix(websocket.RouteSelectionExpression, "${request.body.") {
+		msg := fmt.Sprintf("invalid websocket route_selection_expression %q. Routes must be selected from the message body like %s", websocket.RouteSelectionExpression, DefaultRouteSelectionExpression)
+		return WebSocket{}, errors.New(msg)
+	}
+
+	seen := map[string]bool{}
+	for _, route := range websocket.Routes {
+		if route == "" || len(route) > MaxRouteKeyLength {
+			msg := fmt.Sprintf("invalid websocket route %q. Route keys must be between 1 and %d characters", route, MaxRouteKeyLength)
+			return WebSocket{}, errors.New(msg)
+		}
+		if strings.HasPrefix(route, "$") {
+			msg := fmt.Sprintf("invalid websocket route %s. Route keys starting with $ are reserved", route)
+			return WebSocket{}, errors.New(msg)
+		}
+		if seen[route] {
+			msg := fmt.Sprintf("duplicate websocket route %s", route)
+			return WebSocket{}, errors.New(msg)
+		}
+		seen[route] = true
+	}
+	return websocket, nil
+}
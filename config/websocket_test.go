@@ -0,0 +1,36 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigGetWebSocket(t *testing.T) {
+	assert := assert.New(t)
+	cfg := &Config{}
+	_, err := cfg.GetWebSocket()
+	assert.EqualError(err, "no websocket configured. Set websocket in your jerm.json file")
+
+	cfg.WebSocket = &WebSocket{Routes: []string{"sendMessage"}}
+	websocket, err := cfg.GetWebSocket()
+	assert.Nil(err)
+	assert.Equal(DefaultRouteSelectionExpression, websocket.RouteSelectionExpression)
+	assert.Equal("", cfg.WebSocket.RouteSelectionExpression)
+	assert.Equal([]string{"$connect", "$disconnect", "$default", "sendMessage"}, websocket.RouteKeys())
+
+	cases := []struct {
+		websocket WebSocket
+		err       string
+	}{
+		{WebSocket{RouteSelectionExpression: "$request.header.action"}, `invalid websocket route_selection_expression "$request.header.action". Routes must be selected from the message body like $request.body.action`},
+		{WebSocket{Routes: []string{""}}, `invalid websocket route "". Route keys must be between 1 and 128 characters`},
+		{WebSocket{Routes: []string{"$default"}}, "invalid websocket route $default. Route keys starting with $ are reserved"},
+		{WebSocket{Routes: []string{"join", "join"}}, "duplicate websocket route join"},
+	}
+	for _, tt := range cases {
+		cfg.WebSocket = &tt.websocket
+		_, err = cfg.GetWebSocket()
+		assert.EqualError(err, tt.err)
+	}
+}
@@ -0,0 +1,157 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Error is returned when a REST API responds with a non 2xx status code
+type Error struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *Error) Error() string {
+	body := strings.TrimSpace(e.Body)
+	if body == "" {
+		return e.Status
+	}
+	return fmt.Sprintf("%s: %s", e.Status, body)
+}
+
+// IsNotFound reports whether err is a REST API 404 response
+func IsNotFound(err error) bool {
+	var restErr *Error
+	return errors.As(err, &restErr) && restErr.StatusCode == http.StatusNotFound
+}
+
+// Client is a minimal JSON REST API client
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+
+	// Authorize is called on every request before it is sent
+	Authorize func(*http.Request) error
+}
+
+// NewClient creates a new REST API client for baseURL
+func NewClient(baseURL string, authorize func(*http.Request) error) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+		Authorize:  authorize,
+	}
+}
+
+// URL resolves path against the client base URL.
+// Absolute URLs are returned as is.
+func (c *Client) URL(path string) string {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	return c.BaseURL + "/" + strings.TrimPrefix(path, "/")
+}
+
+// NewRequest creates an authorized request for path
+func (c *Client) NewRequest(method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(context.TODO(), method, c.URL(path), body)
+	if err != nil {
+		return nil, err
+	}
+	if c.Authorize != nil {
+		if err := c.Authorize(req); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+// Send sends req and decodes the JSON response body into out if out is not nil
+func (c *Client) Send(req *http.Request, out interface{}) error {
+	res, err := c.Response(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	return Decode(res, out)
+}
+
+// Stream sends req and returns the response body for the caller to consume
+func (c *Client) Stream(req *http.Request) (io.ReadCloser, error) {
+	res, err := c.Response(req)
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+// Response sends req and returns the response if it has a 2xx status code.
+// The caller is responsible for closing the response body.
+func (c *Client) Response(req *http.Request) (*http.Response, error) {
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		defer res.Body.Close()
+		b, _ := io.ReadAll(res.Body)
+		return nil, &Error{StatusCode: res.StatusCode, Status: res.Status, Body: string(b)}
+	}
+	return res, nil
+}
+
+// Decode decodes the JSON response body into out if out is not nil
+func Decode(res *http.Response, out interface{}) error {
+	if out == nil {
+		return nil
+	}
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(b)) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, out)
+}
+
+// NewJSONRequest creates an authorized request with a JSON encoded body.
+// in can be nil.
+func (c *Client) NewJSONRequest(method, path string, in interface{}) (*http.Request, error) {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := c.NewRequest(method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+// Do sends a JSON encoded in to path and decodes the JSON response into out.
+// in and out can be nil.
+func (c *Client) Do(method, path string, in, out interface{}) error {
+	req, err := c.NewJSONRequest(method, path, in)
+	if err != nil {
+		return err
+	}
+	return c.Send(req, out)
+}
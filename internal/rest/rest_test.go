@@ -0,0 +1,73 @@
+package rest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientURL(t *testing.T) {
+	assert := assert.New(t)
+	c := NewClient("https://example.com/", nil)
+	assert.Equal("https://example.com/v1/items", c.URL("/v1/items"))
+	assert.Equal("https://example.com/v1/items", c.URL("v1/items"))
+	assert.Equal("http://other.com/x", c.URL("http://other.com/x"))
+}
+
+func TestClientDo(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal("Bearer token", r.Header.Get("Authorization"))
+		assert.Equal("application/json", r.Header.Get("Content-Type"))
+		b, _ := io.ReadAll(r.Body)
+		data := map[string]string{}
+		json.Unmarshal(b, &data)
+		w.Write([]byte(`{"name": "` + data["name"] + `"}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, func(r *http.Request) error {
+		r.Header.Set("Authorization", "Bearer token")
+		return nil
+	})
+	out := map[string]string{}
+	err := c.Do(http.MethodPost, "/items", map[string]string{"name": "jerm"}, &out)
+	assert.Nil(err)
+	assert.Equal("jerm", out["name"])
+}
+
+func TestClientDoError(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("not found\n"))
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, nil)
+	err := c.Do(http.MethodGet, "/items", nil, nil)
+	assert.EqualError(err, "404 Not Found: not found")
+	assert.True(IsNotFound(err))
+}
+
+func TestClientStream(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("line1\nline2\n"))
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, nil)
+	req, err := c.NewRequest(http.MethodGet, "/logs", nil)
+	assert.Nil(err)
+	body, err := c.Stream(req)
+	assert.Nil(err)
+	defer body.Close()
+	b, _ := io.ReadAll(body)
+	assert.Equal(2, len(strings.Fields(string(b))))
+}
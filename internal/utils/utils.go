@@ -1,14 +1,17 @@
 package utils
 
 import (
+	"archive/zip"
 	"bufio"
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"github.com/spatocode/jerm/internal/log"
@@ -98,3 +101,54 @@ func GetWorkspaceName() (string, error) {
 	workspaceName := splitPath[len(splitPath)-1]
 	return workspaceName, err
 }
+
+// Unzip extracts a zip archive into dir
+func Unzip(zipPath, dir string) error {
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	for _, file := range reader.File {
+		name := filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(file.Name, "/")))
+		if !strings.HasPrefix(name, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid file path %s in package", file.Name)
+		}
+
+		if file.FileInfo().IsDir() {
+			err = os.MkdirAll(name, os.ModePerm)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		err = os.MkdirAll(filepath.Dir(name), os.ModePerm)
+		if err != nil {
+			return err
+		}
+		err = extractFile(file, name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractFile(file *zip.File, name string) error {
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dest, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, file.Mode()|0600)
+	if err != nil {
+		return err
+	}
+	defer dest.Close()
+
+	_, err = io.Copy(dest, src)
+	return err
+}
@@ -1,8 +1,10 @@
 package utils
 
 import (
+	"archive/zip"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -58,3 +60,30 @@ func helperCreateFile(t *testing.T, file string) {
 		t.Fatal(err)
 	}
 }
+
+func TestUnzip(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "test.zip")
+	f, err := os.Create(archive)
+	assert.Nil(err)
+	writer := zip.NewWriter(f)
+	w, _ := writer.Create("/src/index.js")
+	w.Write([]byte("index"))
+	writer.Close()
+	f.Close()
+
+	err = Unzip(archive, filepath.Join(dir, "out"))
+	assert.Nil(err)
+	b, err := os.ReadFile(filepath.Join(dir, "out", "src", "index.js"))
+	assert.Nil(err)
+	assert.Equal("index", string(b))
+
+	f, _ = os.Create(archive)
+	writer = zip.NewWriter(f)
+	writer.Create("../evil.js")
+	writer.Close()
+	f.Close()
+	err = Unzip(archive, filepath.Join(dir, "out"))
+	assert.EqualError(err, "invalid file path ../evil.js in package")
+}
@@ -53,6 +53,34 @@ func (p *Project) SetPlatform(cloud CloudPlatform) {
 	p.cloud = cloud
 }
 
+// Secrets returns the secret store of the cloud platform
+func (p *Project) Secrets() (CloudSecrets, error) {
+	secrets, ok := p.cloud.(CloudSecrets)
+	if !ok {
+		return nil, fmt.Errorf("secrets are not supported on platform %s", p.config.Platform.Name)
+	}
+	return secrets, nil
+}
+
+// ApiKeys returns the API keys of the cloud platform
+func (p *Project) ApiKeys() (CloudApiKeys, error) {
+	apiKeys, ok := p.cloud.(CloudApiKeys)
+	if !ok {
+		return nil, fmt.Errorf("api keys are not supported on platform %s", p.config.Platform.Name)
+	}
+	return apiKeys, nil
+}
+
+// SetCanary shifts weight percent of the traffic of the next deployment
+// to the new version for the window before promoting it
+func (p *Project) SetCanary(weight int, window time.Duration) error {
+	canary, ok := p.cloud.(CloudCanary)
+	if !ok {
+		return fmt.Errorf("canary deployments are not supported on platform %s", p.config.Platform.Name)
+	}
+	return canary.SetCanary(weight, window)
+}
+
 // Invoke a function
 func (p *Project) Invoke(command string) error {
 	err := p.cloud.Invoke(command)
@@ -121,12 +149,27 @@ func (p *Project) Update(zipPath *string) error {
 	return nil
 }
 
+// UndeployPlan lists the resources deleted by undeploy. It's empty when the
+// cloud platform doesn't list the resources of a deployment.
+func (p *Project) UndeployPlan(opts UndeployOptions) ([]string, error) {
+	teardown, ok := p.cloud.(CloudTeardown)
+	if !ok {
+		return nil, nil
+	}
+	return teardown.UndeployPlan(opts)
+}
+
 // Undeploy terminates a deployment
-func (p *Project) Undeploy() error {
+func (p *Project) Undeploy(opts UndeployOptions) error {
 	log.PrintInfo("Undeploying project...")
 
 	start := time.Now()
-	err := p.cloud.Undeploy()
+	var err error
+	if teardown, ok := p.cloud.(CloudTeardown); ok {
+		err = teardown.Teardown(opts)
+	} else {
+		err = p.cloud.Undeploy()
+	}
 	if err != nil {
 		return err
 	}
@@ -151,6 +194,90 @@ func (p *Project) Rollback(steps int) error {
 	return nil
 }
 
+// RollbackTo rolls back a deployment to a published version
+func (p *Project) RollbackTo(version string) error {
+	versions, ok := p.cloud.(CloudVersions)
+	if !ok {
+		return fmt.Errorf("versions are not supported on platform %s", p.config.Platform.Name)
+	}
+
+	log.PrintfInfo("Rolling back deployment to version %s...\n", version)
+
+	start := time.Now()
+	err := versions.RollbackTo(version)
+	if err != nil {
+		return err
+	}
+
+	duration := time.Since(start)
+	fmt.Printf("%s %s (%s)\n", log.Magenta("rollback:"), log.Green("completed"), log.White(duration.Round(time.Second)))
+	return nil
+}
+
+// Prune deletes old deployments beyond the retention of the project.
+// With dryRun the deployments are listed without being deleted.
+func (p *Project) Prune(dryRun bool) error {
+	pruner, ok := p.cloud.(CloudPruner)
+	if !ok {
+		return fmt.Errorf("pruning is not supported on platform %s", p.config.Platform.Name)
+	}
+
+	log.PrintInfo("Pruning old deployments...")
+	pruned, err := pruner.Prune(dryRun)
+	for _, resource := range pruned {
+		if dryRun {
+			fmt.Printf("%s %s\n", log.Magenta("would delete:"), resource)
+		} else {
+			fmt.Printf("%s %s\n", log.Magenta("deleted:"), resource)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if len(pruned) == 0 {
+		log.PrintInfo("Nothing to prune")
+	}
+	return nil
+}
+
+// Certify serves the deployment at the custom domain of the project
+func (p *Project) Certify() error {
+	domains, ok := p.cloud.(CloudDomains)
+	if !ok {
+		return fmt.Errorf("custom domains are not supported on platform %s", p.config.Platform.Name)
+	}
+
+	log.PrintfInfo("Certifying domain of project %s...\n", p.config.Name)
+
+	start := time.Now()
+	err := domains.Certify()
+	if err != nil {
+		return err
+	}
+
+	duration := time.Since(start)
+	fmt.Printf("%s %s (%s)\n", log.Magenta("certify:"), log.Green("completed"), log.White(duration.Round(time.Second)))
+	return nil
+}
+
+// List lists the deployments of the cloud account
+func (p *Project) List() ([]Deployment, error) {
+	inventory, ok := p.cloud.(CloudInventory)
+	if !ok {
+		return nil, fmt.Errorf("listing deployments is not supported on platform %s", p.config.Platform.Name)
+	}
+	return inventory.List()
+}
+
+// Versions lists the published versions of a deployment, newest first
+func (p *Project) Versions() ([]FunctionVersion, error) {
+	versions, ok := p.cloud.(CloudVersions)
+	if !ok {
+		return nil, fmt.Errorf("versions are not supported on platform %s", p.config.Platform.Name)
+	}
+	return versions.Versions()
+}
+
 // packageProject packages a project for deployment
 func (p *Project) packageProject() (*string, int64, error) {
 	log.Debug("packaging project...")
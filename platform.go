@@ -0,0 +1,65 @@
+package jerm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spatocode/jerm/config"
+)
+
+// PlatformFactory creates a CloudPlatform from a Jerm configuration
+type PlatformFactory func(*config.Config) (CloudPlatform, error)
+
+var (
+	platformsMu sync.RWMutex
+	platforms   = make(map[config.PlatformName]PlatformFactory)
+)
+
+// RegisterPlatform makes a cloud platform available by name.
+// It is typically called from the init function of a cloud backend.
+// It panics if factory is nil or the name is registered twice.
+func RegisterPlatform(name config.PlatformName, factory PlatformFactory) {
+	platformsMu.Lock()
+	defer platformsMu.Unlock()
+
+	if factory == nil {
+		panic("jerm: RegisterPlatform factory is nil")
+	}
+	if _, dup := platforms[name]; dup {
+		panic(fmt.Sprintf("jerm: RegisterPlatform called twice for platform %s", name))
+	}
+	platforms[name] = factory
+}
+
+// Platforms returns a sorted list of the names of the registered platforms
+func Platforms() []string {
+	platformsMu.RLock()
+	defer platformsMu.RUnlock()
+
+	var names []string
+	for name := range platforms {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewPlatform instantiates the cloud platform selected by the
+// platform name in the configuration. It defaults to AWS Lambda.
+func NewPlatform(cfg *config.Config) (CloudPlatform, error) {
+	if cfg.Platform.Name == "" {
+		cfg.Platform.Name = config.Lambda
+	}
+	name := cfg.Platform.Name
+
+	platformsMu.RLock()
+	factory, ok := platforms[name]
+	platformsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown platform %q. Supported platforms are: %s", name, strings.Join(Platforms(), ", "))
+	}
+
+	return factory(cfg)
+}
@@ -0,0 +1,57 @@
+package jerm
+
+import (
+	"testing"
+
+	"github.com/spatocode/jerm/config"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakePlatform struct {
+	config *config.Config
+}
+
+func (f *fakePlatform) Deploy(string) (bool, error) { return false, nil }
+func (f *fakePlatform) Update(string) error         { return nil }
+func (f *fakePlatform) Undeploy() error             { return nil }
+func (f *fakePlatform) Build() (string, error)      { return "", nil }
+func (f *fakePlatform) Rollback(int) error          { return nil }
+func (f *fakePlatform) Logs()                       {}
+func (f *fakePlatform) Invoke(string) error         { return nil }
+
+func TestNewPlatform(t *testing.T) {
+	assert := assert.New(t)
+	name := config.PlatformName("fake")
+	RegisterPlatform(name, func(cfg *config.Config) (CloudPlatform, error) {
+		return &fakePlatform{config: cfg}, nil
+	})
+
+	cfg := &config.Config{Platform: config.Platform{Name: name}}
+	platform, err := NewPlatform(cfg)
+	assert.Nil(err)
+	assert.IsType(&fakePlatform{}, platform)
+	assert.Equal(cfg, platform.(*fakePlatform).config)
+	assert.Contains(Platforms(), "fake")
+
+	assert.Panics(func() {
+		RegisterPlatform(name, func(cfg *config.Config) (CloudPlatform, error) {
+			return nil, nil
+		})
+	})
+}
+
+func TestNewPlatformUnknown(t *testing.T) {
+	assert := assert.New(t)
+	cfg := &config.Config{Platform: config.Platform{Name: "unknown"}}
+	platform, err := NewPlatform(cfg)
+	assert.Nil(platform)
+	assert.ErrorContains(err, `unknown platform "unknown"`)
+}
+
+func TestNewPlatformDefault(t *testing.T) {
+	assert := assert.New(t)
+	cfg := &config.Config{Platform: config.Platform{Memory: 512}}
+	NewPlatform(cfg)
+	assert.Equal(config.Lambda, cfg.Platform.Name)
+	assert.Equal(512, cfg.Platform.Memory)
+}